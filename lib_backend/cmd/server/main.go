@@ -7,6 +7,12 @@ import (
 
 	"lib_backend/internal/config"
 	handler "lib_backend/internal/handlers"
+	"lib_backend/internal/openapi"
+	"lib_backend/internal/outbox"
+	"lib_backend/internal/repository"
+	"lib_backend/internal/scheduler"
+	"lib_backend/internal/storage"
+	"lib_backend/internal/stream"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -29,6 +35,10 @@ func main() {
 
 	r := gin.Default()
 
+	if err := r.SetTrustedProxies(config.LoadTrustedProxies()); err != nil {
+		log.Fatalf("error configuring trusted proxies: %v", err)
+	}
+
 	corsConfig := cors.DefaultConfig()
 
 	corsConfig.AllowOrigins = []string{
@@ -36,12 +46,52 @@ func main() {
 	}
 
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Last-Event-ID", "X-API-Key", "X-User-ID", "Idempotency-Key"}
+	corsConfig.ExposeHeaders = []string{"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Idempotent-Replayed"}
 	corsConfig.MaxAge = 12 * time.Hour
 
 	r.Use(cors.New(corsConfig))
 
-	handler.SetupRoutes(r, db)
+	sched := scheduler.New(db, repository.NewJobRunRepository(db))
+
+	outboxConfig := config.LoadOutboxConfig()
+	dispatcher := outbox.NewDispatcher(repository.NewTransactor(db), repository.NewOutboxRepository(db), outboxConfig)
+
+	if outboxConfig.NATSURL != "" {
+		natsSink, err := outbox.NewNATSSink(outboxConfig.NATSURL, outboxConfig.NATSSubjectPrefix)
+		if err != nil {
+			log.Fatalf("error configuring nats: %v", err)
+		}
+		defer natsSink.Close()
+		dispatcher.AddSink(natsSink)
+	}
+
+	hub := stream.NewHub(repository.NewOutboxRepository(db), config.LoadStreamConfig())
+
+	blobStore, err := storage.New(config.LoadStorageConfig())
+	if err != nil {
+		log.Fatalf("error configuring blob store: %v", err)
+	}
+
+	spec, err := openapi.Load()
+	if err != nil {
+		log.Fatalf("error loading openapi document: %v", err)
+	}
+
+	handler.SetupRoutes(r, db, sched, dispatcher, hub, blobStore, spec)
+
+	dispatcher.Start()
+	defer dispatcher.Stop()
+
+	if err := hub.Start(); err != nil {
+		log.Fatalf("error starting event stream: %v", err)
+	}
+	defer hub.Stop()
+
+	if config.LoadSchedulerConfig().Enabled {
+		sched.Start()
+		defer sched.Stop()
+	}
 
 	port := os.Getenv("APP_PORT")
 	if port == "" {
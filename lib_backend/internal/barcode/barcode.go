@@ -0,0 +1,94 @@
+package barcode
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+
+	"github.com/boombuler/barcode/code128"
+)
+
+// quietZone is the blank margin, in modules, required on each side of a Code128 symbol.
+const quietZone = 10
+
+// WritePNG renders value as a Code128 PNG where each module is moduleWidth
+// pixels wide, with the same quiet zone as WriteSVG on both sides.
+func WritePNG(w io.Writer, value string, moduleWidth, height int) error {
+	modules, err := encodeModules(value)
+	if err != nil {
+		return err
+	}
+
+	width := (len(modules) + 2*quietZone) * moduleWidth
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	for i, dark := range modules {
+		if !dark {
+			continue
+		}
+
+		x := (quietZone + i) * moduleWidth
+		draw.Draw(img, image.Rect(x, 0, x+moduleWidth, height), image.Black, image.Point{}, draw.Src)
+	}
+
+	return png.Encode(w, img)
+}
+
+// WriteSVG renders value as a Code128 SVG with the human readable text below the bars.
+func WriteSVG(w io.Writer, value string, moduleWidth, height int) error {
+	modules, err := encodeModules(value)
+	if err != nil {
+		return err
+	}
+
+	textHeight := height / 4
+	width := (len(modules) + 2*quietZone) * moduleWidth
+	totalHeight := height + textHeight
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, width, totalHeight, width, totalHeight)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#fff"/>`, width, totalHeight)
+
+	for start := 0; start < len(modules); {
+		if !modules[start] {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(modules) && modules[end] {
+			end++
+		}
+
+		fmt.Fprintf(&sb, `<rect x="%d" y="0" width="%d" height="%d" fill="#000"/>`, (quietZone+start)*moduleWidth, (end-start)*moduleWidth, height)
+		start = end
+	}
+
+	fmt.Fprintf(&sb, `<text x="%d" y="%d" font-family="monospace" font-size="%d" text-anchor="middle">%s</text>`, width/2, totalHeight-2, textHeight-2, html.EscapeString(value))
+	sb.WriteString(`</svg>`)
+
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+func encodeModules(value string) ([]bool, error) {
+	code, err := code128.Encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode barcode %s: %w", value, err)
+	}
+
+	bounds := code.Bounds()
+	modules := make([]bool, bounds.Dx())
+
+	for x := bounds.Min.X; x < bounds.Max.X; x++ {
+		r, _, _, _ := code.At(x, bounds.Min.Y).RGBA()
+		modules[x-bounds.Min.X] = r < 0x8000
+	}
+
+	return modules, nil
+}
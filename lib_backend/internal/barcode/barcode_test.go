@@ -0,0 +1,58 @@
+package barcode
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestWritePNGHasQuietZone(t *testing.T) {
+	const moduleWidth, height = 2, 40
+
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, "9788535914849", moduleWidth, height); err != nil {
+		t.Fatalf("WritePNG() error = %v", err)
+	}
+
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode PNG: %v", err)
+	}
+
+	modules, err := encodeModules("9788535914849")
+	if err != nil {
+		t.Fatalf("encodeModules() error = %v", err)
+	}
+
+	bounds := img.Bounds()
+	if want := (len(modules) + 2*quietZone) * moduleWidth; bounds.Dx() != want {
+		t.Fatalf("PNG width = %d, want %d", bounds.Dx(), want)
+	}
+
+	margin := quietZone * moduleWidth
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := 0; x < margin; x++ {
+			for _, px := range []int{x, bounds.Max.X - 1 - x} {
+				if r, _, _, _ := img.At(px, y).RGBA(); r < 0x8000 {
+					t.Fatalf("pixel (%d, %d) inside the quiet zone is dark", px, y)
+				}
+			}
+		}
+	}
+
+	if r, _, _, _ := img.At(margin, 0).RGBA(); r >= 0x8000 {
+		t.Fatalf("first bar does not start right after the quiet zone")
+	}
+}
+
+func TestWriteSVGEscapesValue(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSVG(&buf, "A<B", 1, 40); err != nil {
+		t.Fatalf("WriteSVG() error = %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "A&lt;B") {
+		t.Fatalf("SVG does not escape the human readable text: %s", buf.String())
+	}
+}
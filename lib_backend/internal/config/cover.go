@@ -0,0 +1,41 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// CoverConfig limits uploaded cover images. MaxDimension is checked before
+// decoding so a small file cannot expand into a huge bitmap.
+type CoverConfig struct {
+	MaxBytes     int64
+	MaxDimension int
+}
+
+func LoadCoverConfig() CoverConfig {
+	cfg := CoverConfig{
+		MaxBytes:     5 << 20,
+		MaxDimension: 6000,
+	}
+
+	if maxBytes := os.Getenv("COVER_MAX_BYTES"); maxBytes != "" {
+		n, err := strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid COVER_MAX_BYTES %q, using %d", maxBytes, cfg.MaxBytes)
+		} else {
+			cfg.MaxBytes = n
+		}
+	}
+
+	if maxDimension := os.Getenv("COVER_MAX_DIMENSION"); maxDimension != "" {
+		n, err := strconv.Atoi(maxDimension)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid COVER_MAX_DIMENSION %q, using %d", maxDimension, cfg.MaxDimension)
+		} else {
+			cfg.MaxDimension = n
+		}
+	}
+
+	return cfg
+}
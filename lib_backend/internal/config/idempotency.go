@@ -0,0 +1,37 @@
+package config
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// IdempotencyConfig sets how long responses to keyed POST requests are
+// replayed, and after how long a request that never finished, e.g. because
+// its replica crashed, stops blocking retries with the same key.
+type IdempotencyConfig struct {
+	Retention   time.Duration
+	LockTimeout time.Duration
+}
+
+func LoadIdempotencyConfig() IdempotencyConfig {
+	cfg := IdempotencyConfig{Retention: 24 * time.Hour, LockTimeout: time.Minute}
+
+	for name, target := range map[string]*time.Duration{
+		"IDEMPOTENCY_RETENTION":    &cfg.Retention,
+		"IDEMPOTENCY_LOCK_TIMEOUT": &cfg.LockTimeout,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			log.Printf("WARNING: invalid %s %q, using %s", name, value, *target)
+			continue
+		}
+		*target = d
+	}
+
+	return cfg
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// MetadataConfig configures ISBN lookups. Providers are tried in the order of
+// METADATA_PROVIDERS; their base URLs can point at a local fixture server.
+type MetadataConfig struct {
+	Providers         []string
+	OpenLibraryURL    string
+	GoogleBooksURL    string
+	GoogleBooksAPIKey string
+	Timeout           time.Duration
+	CacheTTL          time.Duration
+	NotFoundTTL       time.Duration
+}
+
+func LoadMetadataConfig() MetadataConfig {
+	cfg := MetadataConfig{
+		Providers:         []string{"openlibrary", "googlebooks"},
+		OpenLibraryURL:    os.Getenv("OPENLIBRARY_URL"),
+		GoogleBooksURL:    os.Getenv("GOOGLE_BOOKS_URL"),
+		GoogleBooksAPIKey: os.Getenv("GOOGLE_BOOKS_API_KEY"),
+		Timeout:           5 * time.Second,
+		CacheTTL:          30 * 24 * time.Hour,
+		NotFoundTTL:       24 * time.Hour,
+	}
+
+	if providers := os.Getenv("METADATA_PROVIDERS"); providers != "" {
+		cfg.Providers = strings.Split(providers, ",")
+	}
+
+	if cfg.OpenLibraryURL == "" {
+		cfg.OpenLibraryURL = "https://openlibrary.org"
+	}
+
+	if cfg.GoogleBooksURL == "" {
+		cfg.GoogleBooksURL = "https://www.googleapis.com"
+	}
+
+	for name, target := range map[string]*time.Duration{
+		"METADATA_TIMEOUT":       &cfg.Timeout,
+		"METADATA_CACHE_TTL":     &cfg.CacheTTL,
+		"METADATA_NOT_FOUND_TTL": &cfg.NotFoundTTL,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			log.Printf("WARNING: invalid %s %q, using %s", name, value, *target)
+			continue
+		}
+		*target = d
+	}
+
+	return cfg
+}
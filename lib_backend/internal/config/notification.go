@@ -0,0 +1,51 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// NotificationConfig configures outgoing notifications. With no SMTP host
+// messages are only written to the server log, which is handy in development;
+// point SMTP_HOST/SMTP_PORT at MailHog (localhost:1025) to inspect real emails.
+type NotificationConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	TemplateDir  string
+	DueSoonDays  int
+}
+
+func LoadNotificationConfig() NotificationConfig {
+	cfg := NotificationConfig{
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		From:         os.Getenv("NOTIFICATION_FROM"),
+		TemplateDir:  os.Getenv("NOTIFICATION_TEMPLATE_DIR"),
+		DueSoonDays:  2,
+	}
+
+	if cfg.SMTPPort == "" {
+		cfg.SMTPPort = "25"
+	}
+
+	if cfg.From == "" {
+		cfg.From = "biblioteca@localhost"
+	}
+
+	if days := os.Getenv("NOTIFICATION_DUE_SOON_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid NOTIFICATION_DUE_SOON_DAYS %q, using %d", days, cfg.DueSoonDays)
+		} else {
+			cfg.DueSoonDays = n
+		}
+	}
+
+	return cfg
+}
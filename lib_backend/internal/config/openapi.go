@@ -0,0 +1,36 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// OpenAPIConfig turns on the validation of documented routes against the
+// OpenAPI document. Invalid requests are rejected, invalid responses logged.
+type OpenAPIConfig struct {
+	ValidateRequests  bool
+	ValidateResponses bool
+}
+
+func LoadOpenAPIConfig() OpenAPIConfig {
+	var cfg OpenAPIConfig
+
+	for name, target := range map[string]*bool{
+		"OPENAPI_VALIDATE_REQUESTS":  &cfg.ValidateRequests,
+		"OPENAPI_VALIDATE_RESPONSES": &cfg.ValidateResponses,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("WARNING: invalid %s %q, validation stays disabled", name, value)
+			continue
+		}
+		*target = enabled
+	}
+
+	return cfg
+}
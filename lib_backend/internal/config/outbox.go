@@ -0,0 +1,51 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// OutboxConfig configures the relay of domain events from the outbox table.
+// Events are also published to NATS when NATS_URL is set; the nats service in
+// docker-compose.yml is enough for local development.
+type OutboxConfig struct {
+	PollInterval      time.Duration
+	BatchSize         int
+	NATSURL           string
+	NATSSubjectPrefix string
+}
+
+func LoadOutboxConfig() OutboxConfig {
+	cfg := OutboxConfig{
+		PollInterval:      time.Second,
+		BatchSize:         100,
+		NATSURL:           os.Getenv("NATS_URL"),
+		NATSSubjectPrefix: os.Getenv("NATS_SUBJECT_PREFIX"),
+	}
+
+	if cfg.NATSSubjectPrefix == "" {
+		cfg.NATSSubjectPrefix = "library"
+	}
+
+	if interval := os.Getenv("OUTBOX_POLL_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			log.Printf("WARNING: invalid OUTBOX_POLL_INTERVAL %q, using %s", interval, cfg.PollInterval)
+		} else {
+			cfg.PollInterval = d
+		}
+	}
+
+	if size := os.Getenv("OUTBOX_BATCH_SIZE"); size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid OUTBOX_BATCH_SIZE %q, using %d", size, cfg.BatchSize)
+		} else {
+			cfg.BatchSize = n
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// PrivacyConfig sets how long returned loans keep the patron who borrowed
+// them. Patrons who opted in to keep their history are never anonymized.
+type PrivacyConfig struct {
+	LoanRetentionDays int
+}
+
+func LoadPrivacyConfig() PrivacyConfig {
+	cfg := PrivacyConfig{LoanRetentionDays: 180}
+
+	if days := os.Getenv("LOAN_HISTORY_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid LOAN_HISTORY_RETENTION_DAYS %q, using %d", days, cfg.LoanRetentionDays)
+		} else {
+			cfg.LoanRetentionDays = n
+		}
+	}
+
+	return cfg
+}
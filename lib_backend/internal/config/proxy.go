@@ -0,0 +1,22 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// LoadTrustedProxies lists the proxies, as IPs or CIDRs separated by commas
+// in TRUSTED_PROXIES, whose X-Forwarded-For header is believed when telling
+// clients apart. None are trusted by default, so a client cannot pick its
+// own IP and the connection address is used.
+func LoadTrustedProxies() []string {
+	proxies := make([]string, 0)
+
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+
+	return proxies
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Requests per Period, refilled continuously, with bursts of
+// up to Requests.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// ParseRateLimit reads limits written as "<requests>/<period>", e.g. "100/1m".
+func ParseRateLimit(value string) (RateLimit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("rate limit %q must be written as <requests>/<period>", value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q needs a positive number of requests", value)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("rate limit %q needs a positive period", value)
+	}
+
+	return RateLimit{Requests: n, Period: d}, nil
+}
+
+// RateLimitConfig configures the per-client request limits. Every route group
+// gets Default unless RATE_LIMIT_<GROUP> overrides it, e.g.
+// RATE_LIMIT_PATRON_CATEGORIES="60/1m". Identities lists, in order, how a
+// client is recognised: a known API key, the X-User-ID header or the IP.
+type RateLimitConfig struct {
+	Enabled    bool
+	Store      string
+	Default    RateLimit
+	Groups     map[string]RateLimit
+	Identities []string
+	APIKeys    []string
+}
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+
+	RateLimitIdentityAPIKey = "api_key"
+	RateLimitIdentityUser   = "user"
+	RateLimitIdentityIP     = "ip"
+)
+
+var rateLimitSettings = map[string]bool{"ENABLED": true, "STORE": true, "DEFAULT": true, "IDENTITIES": true, "API_KEYS": true}
+
+func LoadRateLimitConfig() RateLimitConfig {
+	cfg := RateLimitConfig{
+		Enabled:    true,
+		Store:      RateLimitStoreMemory,
+		Default:    RateLimit{Requests: 300, Period: time.Minute},
+		Groups:     map[string]RateLimit{"REPORTS": {Requests: 30, Period: time.Minute}},
+		Identities: []string{RateLimitIdentityAPIKey, RateLimitIdentityIP},
+	}
+
+	if enabled := os.Getenv("RATE_LIMIT_ENABLED"); enabled != "" {
+		value, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Printf("WARNING: invalid RATE_LIMIT_ENABLED %q, rate limiting stays enabled", enabled)
+		} else {
+			cfg.Enabled = value
+		}
+	}
+
+	if store := os.Getenv("RATE_LIMIT_STORE"); store != "" {
+		if store != RateLimitStoreMemory && store != RateLimitStorePostgres {
+			log.Printf("WARNING: invalid RATE_LIMIT_STORE %q, using %s", store, cfg.Store)
+		} else {
+			cfg.Store = store
+		}
+	}
+
+	if value := os.Getenv("RATE_LIMIT_DEFAULT"); value != "" {
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			log.Printf("WARNING: invalid RATE_LIMIT_DEFAULT: %v, using %s", err, cfg.Default)
+		} else {
+			cfg.Default = limit
+		}
+	}
+
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		group, ok := strings.CutPrefix(name, "RATE_LIMIT_")
+		if !ok || rateLimitSettings[group] {
+			continue
+		}
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			log.Printf("WARNING: invalid %s: %v, ignoring it", name, err)
+			continue
+		}
+		cfg.Groups[group] = limit
+	}
+
+	if value := os.Getenv("RATE_LIMIT_IDENTITIES"); value != "" {
+		identities := make([]string, 0)
+		for _, identity := range strings.Split(value, ",") {
+			identity = strings.TrimSpace(identity)
+			switch identity {
+			case RateLimitIdentityAPIKey, RateLimitIdentityUser, RateLimitIdentityIP:
+				identities = append(identities, identity)
+			default:
+				log.Printf("WARNING: unknown rate limit identity %q, ignoring it", identity)
+			}
+		}
+		if len(identities) > 0 {
+			cfg.Identities = identities
+		}
+	}
+
+	// the IP is always the last resort, so every request has an identity
+	if cfg.Identities[len(cfg.Identities)-1] != RateLimitIdentityIP {
+		cfg.Identities = append(cfg.Identities, RateLimitIdentityIP)
+	}
+
+	for _, key := range strings.Split(os.Getenv("RATE_LIMIT_API_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			cfg.APIKeys = append(cfg.APIKeys, key)
+		}
+	}
+
+	return cfg
+}
+
+// GroupLimit returns the limit of a route group such as "patron-categories".
+func (c RateLimitConfig) GroupLimit(group string) RateLimit {
+	if limit, ok := c.Groups[strings.ToUpper(strings.ReplaceAll(group, "-", "_"))]; ok {
+		return limit
+	}
+	return c.Default
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// RecommendationConfig tunes the co-borrowing recommendations. A pair of
+// books is only related when at least MinSupport different patrons borrowed
+// both, so no recommendation can point back at a single reader's history.
+type RecommendationConfig struct {
+	MinSupport int
+	MaxRelated int
+}
+
+func LoadRecommendationConfig() RecommendationConfig {
+	cfg := RecommendationConfig{
+		MinSupport: 3,
+		MaxRelated: 50,
+	}
+
+	for name, target := range map[string]*int{
+		"RECOMMENDATION_MIN_SUPPORT": &cfg.MinSupport,
+		"RECOMMENDATION_MAX_RELATED": &cfg.MaxRelated,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid %s %q, using %d", name, value, *target)
+			continue
+		}
+		*target = n
+	}
+
+	// a support of one would expose what a single patron borrowed
+	if cfg.MinSupport < 2 {
+		log.Printf("WARNING: RECOMMENDATION_MIN_SUPPORT must be at least 2, using 2")
+		cfg.MinSupport = 2
+	}
+
+	return cfg
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// RegistrationConfig describes how registration numbers are generated when
+// the institution does not supply one: prefix + zero-padded sequence + check digit.
+type RegistrationConfig struct {
+	Prefix         string
+	SequenceDigits int
+}
+
+func LoadRegistrationConfig() RegistrationConfig {
+	return loadSequenceFormat("REGISTRATION", RegistrationConfig{Prefix: "BIB", SequenceDigits: 7})
+}
+
+// LoadItemBarcodeConfig describes the barcodes generated for copies created
+// without one, in the same format as registrations.
+func LoadItemBarcodeConfig() RegistrationConfig {
+	return loadSequenceFormat("ITEM_BARCODE", RegistrationConfig{Prefix: "EX", SequenceDigits: 8})
+}
+
+func loadSequenceFormat(env string, cfg RegistrationConfig) RegistrationConfig {
+	if prefix, ok := os.LookupEnv(env + "_PREFIX"); ok {
+		cfg.Prefix = prefix
+	}
+
+	if digits := os.Getenv(env + "_SEQUENCE_DIGITS"); digits != "" {
+		n, err := strconv.Atoi(digits)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid %s_SEQUENCE_DIGITS %q, using %d", env, digits, cfg.SequenceDigits)
+		} else {
+			cfg.SequenceDigits = n
+		}
+	}
+
+	return cfg
+}
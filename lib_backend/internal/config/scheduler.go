@@ -0,0 +1,57 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SchedulerConfig configures background jobs. Each job schedule can be
+// overridden with JOB_<NAME>_SCHEDULE, e.g. JOB_HOLD_EXPIRY_SCHEDULE="@every 30m".
+type SchedulerConfig struct {
+	Enabled       bool
+	Schedules     map[string]string
+	RetentionDays int
+}
+
+var defaultJobSchedules = map[string]string{
+	"overdue":         "0 8 * * *",
+	"reminders":       "0 9 * * *",
+	"hold_expiry":     "0 * * * *",
+	"purge":           "30 3 * * *",
+	"webhooks":        "@every 1m",
+	"recommendations": "0 4 * * *",
+	"anonymization":   "0 5 * * *",
+}
+
+func LoadSchedulerConfig() SchedulerConfig {
+	cfg := SchedulerConfig{Enabled: true, Schedules: make(map[string]string), RetentionDays: 90}
+
+	if enabled := os.Getenv("SCHEDULER_ENABLED"); enabled != "" {
+		value, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Printf("WARNING: invalid SCHEDULER_ENABLED %q, scheduler stays enabled", enabled)
+		} else {
+			cfg.Enabled = value
+		}
+	}
+
+	for name, schedule := range defaultJobSchedules {
+		if override := os.Getenv("JOB_" + strings.ToUpper(name) + "_SCHEDULE"); override != "" {
+			schedule = override
+		}
+		cfg.Schedules[name] = schedule
+	}
+
+	if days := os.Getenv("JOB_HISTORY_RETENTION_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			log.Printf("WARNING: invalid JOB_HISTORY_RETENTION_DAYS %q, using %d", days, cfg.RetentionDays)
+		} else {
+			cfg.RetentionDays = n
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strconv"
+)
+
+// StorageConfig selects where uploaded files such as book covers are kept:
+// BLOB_STORE=local writes under BLOB_LOCAL_DIR, BLOB_STORE=s3 uses any
+// S3-compatible service (the minio service in docker-compose.yml works locally).
+type StorageConfig struct {
+	Backend     string
+	LocalDir    string
+	S3Endpoint  string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3Region    string
+	S3UseSSL    bool
+}
+
+func LoadStorageConfig() StorageConfig {
+	cfg := StorageConfig{
+		Backend:     os.Getenv("BLOB_STORE"),
+		LocalDir:    os.Getenv("BLOB_LOCAL_DIR"),
+		S3Endpoint:  os.Getenv("S3_ENDPOINT"),
+		S3Bucket:    os.Getenv("S3_BUCKET"),
+		S3AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("S3_SECRET_KEY"),
+		S3Region:    os.Getenv("S3_REGION"),
+	}
+
+	if cfg.Backend == "" {
+		cfg.Backend = "local"
+	}
+
+	if cfg.LocalDir == "" {
+		cfg.LocalDir = "./data/blobs"
+	}
+
+	if cfg.S3Bucket == "" {
+		cfg.S3Bucket = "library"
+	}
+
+	if useSSL := os.Getenv("S3_USE_SSL"); useSSL != "" {
+		parsed, err := strconv.ParseBool(useSSL)
+		if err != nil {
+			log.Printf("WARNING: invalid S3_USE_SSL %q, using %t", useSSL, cfg.S3UseSSL)
+		} else {
+			cfg.S3UseSSL = parsed
+		}
+	}
+
+	return cfg
+}
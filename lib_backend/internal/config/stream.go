@@ -0,0 +1,48 @@
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// StreamConfig configures the real-time event stream. STREAM_ALLOWED_ORIGINS is
+// a comma-separated list of origins allowed to open the WebSocket endpoint.
+type StreamConfig struct {
+	PollInterval      time.Duration
+	HeartbeatInterval time.Duration
+	AllowedOrigins    []string
+}
+
+func LoadStreamConfig() StreamConfig {
+	cfg := StreamConfig{
+		PollInterval:      time.Second,
+		HeartbeatInterval: 15 * time.Second,
+		AllowedOrigins:    []string{"http://localhost:5173"},
+	}
+
+	if interval := os.Getenv("STREAM_POLL_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			log.Printf("WARNING: invalid STREAM_POLL_INTERVAL %q, using %s", interval, cfg.PollInterval)
+		} else {
+			cfg.PollInterval = d
+		}
+	}
+
+	if interval := os.Getenv("STREAM_HEARTBEAT_INTERVAL"); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			log.Printf("WARNING: invalid STREAM_HEARTBEAT_INTERVAL %q, using %s", interval, cfg.HeartbeatInterval)
+		} else {
+			cfg.HeartbeatInterval = d
+		}
+	}
+
+	if origins := os.Getenv("STREAM_ALLOWED_ORIGINS"); origins != "" {
+		cfg.AllowedOrigins = strings.Split(origins, ",")
+	}
+
+	return cfg
+}
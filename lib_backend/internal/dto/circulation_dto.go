@@ -0,0 +1,12 @@
+package dto
+
+type CheckoutRequest struct {
+	PatronBarcode string   `json:"patronBarcode" binding:"required"`
+	ItemBarcodes  []string `json:"itemBarcodes" binding:"required,min=1,dive,required"`
+	BranchCode    string   `json:"branchCode"`
+}
+
+type CheckinRequest struct {
+	ItemBarcode string `json:"itemBarcode" binding:"required"`
+	BranchCode  string `json:"branchCode"`
+}
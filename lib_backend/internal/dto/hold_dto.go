@@ -0,0 +1,8 @@
+package dto
+
+type HoldRequest struct {
+	UserID string `json:"userId" binding:"required,uuid"`
+	BookID string `json:"bookId" binding:"required,uuid"`
+
+	PickupBranchID string `json:"pickupBranchId" binding:"omitempty,uuid"`
+}
@@ -1,6 +1,7 @@
 package dto
 
 type LoanRequest struct {
-	UserID string `json:"userId" binding:"required,uuid"`
-	BookID string `json:"bookId" binding:"required,uuid"`
+	UserID   string `json:"userId" binding:"required,uuid"`
+	BookID   string `json:"bookId" binding:"required,uuid"`
+	BranchID string `json:"branchId" binding:"omitempty,uuid"`
 }
@@ -0,0 +1,6 @@
+package dto
+
+type TransferRequest struct {
+	BookID     string `json:"bookId" binding:"required,uuid"`
+	ToBranchID string `json:"toBranchId" binding:"required,uuid"`
+}
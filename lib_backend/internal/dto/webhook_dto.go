@@ -0,0 +1,13 @@
+package dto
+
+type WebhookSubscriptionRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"eventTypes" binding:"required,min=1"`
+	Secret     string   `json:"secret"`
+}
+
+type WebhookSubscriptionUpdateRequest struct {
+	URL        string   `json:"url" binding:"required"`
+	EventTypes []string `json:"eventTypes" binding:"required,min=1"`
+	Active     *bool    `json:"active" binding:"required"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+type WithdrawalRequest struct {
+	BookIDs []string `json:"bookIds" binding:"required,min=1,dive,required,uuid"`
+	Reason  string   `json:"reason"`
+}
+
+type ReinstateRequest struct {
+	BookIDs []string `json:"bookIds" binding:"required,min=1,dive,required,uuid"`
+}
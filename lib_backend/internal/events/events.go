@@ -0,0 +1,115 @@
+package events
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	LoanCreated  = "loan.created"
+	LoanReturned = "loan.returned"
+	LoanRenewed  = "loan.renewed"
+	LoanOverdue  = "loan.overdue"
+	BookCreated  = "book.created"
+	BookUpdated  = "book.updated"
+	BookDeleted  = "book.deleted"
+	// BookAvailability is emitted whenever circulation changes whether a copy
+	// can be borrowed or where it is.
+	BookAvailability = "book.availability"
+	HoldReady        = "hold.ready"
+	UserCreated      = "user.created"
+	UserUpdated      = "user.updated"
+	UserDeleted      = "user.deleted"
+	// UserErased is emitted when a user's personal data is erased on request;
+	// consumers holding copies of it should drop them.
+	UserErased = "user.erased"
+)
+
+// Types lists every event type the services emit.
+var Types = []string{LoanCreated, LoanReturned, LoanRenewed, LoanOverdue, BookCreated, BookUpdated, BookDeleted, BookAvailability, HoldReady, UserCreated, UserUpdated, UserDeleted, UserErased}
+
+func IsKnownType(eventType string) bool {
+	return slices.Contains(Types, eventType)
+}
+
+// Event is a domain event as relayed from the outbox. Seq increases in commit
+// order and ID is stable across redeliveries, so consumers can deduplicate.
+type Event struct {
+	Seq        int64     `json:"seq"`
+	ID         uuid.UUID `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       any       `json:"data"`
+}
+
+// Sink receives the events relayed from the outbox, in order. Delivery is at
+// least once: after a failure a sink may see the same event again.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+type sinkFunc struct {
+	name string
+	send func(ctx context.Context, event Event) error
+}
+
+func NewSink(name string, send func(ctx context.Context, event Event) error) Sink {
+	return &sinkFunc{name: name, send: send}
+}
+
+func (s *sinkFunc) Name() string {
+	return s.name
+}
+
+func (s *sinkFunc) Send(ctx context.Context, event Event) error {
+	return s.send(ctx, event)
+}
+
+type Handler func(event Event)
+
+// Bus is the in-process sink. It delivers events synchronously to every
+// subscribed handler, in subscription order. A panicking handler is logged
+// and skipped.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers []Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *Bus) Name() string {
+	return "bus"
+}
+
+func (b *Bus) Send(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	handlers := slices.Clone(b.handlers)
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		func() {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					log.Printf("ERROR: handler for event %s %s panicked: %v", event.Type, event.ID.String(), recovered)
+				}
+			}()
+			handler(event)
+		}()
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"lib_backend/internal/model"
 	"lib_backend/internal/services"
@@ -12,11 +14,61 @@ import (
 )
 
 type BookHandler struct {
-	bookService services.BookService
+	bookService     services.BookService
+	metadataService services.MetadataService
 }
 
-func NewBookHandler(s services.BookService) *BookHandler {
-	return &BookHandler{bookService: s}
+func NewBookHandler(s services.BookService, m services.MetadataService) *BookHandler {
+	return &BookHandler{bookService: s, metadataService: m}
+}
+
+func isInvalidISBN(err error) bool {
+	return strings.HasPrefix(err.Error(), "ISBN ") && strings.HasSuffix(err.Error(), " is not valid")
+}
+
+func isMetadataNotFound(err error) bool {
+	return strings.HasPrefix(err.Error(), "no metadata found for ISBN ")
+}
+
+func isInvalidBibliographic(err error) bool {
+	message := err.Error()
+	return (strings.HasPrefix(message, "publication year ") || strings.HasPrefix(message, "page count ")) && strings.HasSuffix(message, " is not valid") ||
+		strings.HasPrefix(message, "language ") && strings.HasSuffix(message, " is not a valid language code") ||
+		strings.HasPrefix(message, "contributor role ") && strings.HasSuffix(message, " is not valid") ||
+		message == "contributor name or ID is required" ||
+		strings.HasPrefix(message, "contributor name ") && strings.HasSuffix(message, " has no letters or digits") ||
+		strings.HasPrefix(message, "contributor with ID ") && strings.HasSuffix(message, " not found") ||
+		strings.HasPrefix(message, "subject heading ") && strings.HasSuffix(message, " is not in the vocabulary") ||
+		strings.HasPrefix(message, "volume number ") && strings.HasSuffix(message, " is not valid") ||
+		message == "volume number requires a series" ||
+		strings.HasPrefix(message, "series with ID ") && strings.HasSuffix(message, " does not exist")
+}
+
+func (h *BookHandler) LookupISBN(c *gin.Context) {
+	isbn := c.Query("isbn")
+
+	if isbn == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "isbn query parameter is required"})
+		return
+	}
+
+	bookMetadata, err := h.metadataService.LookupISBN(isbn)
+
+	if err != nil {
+		log.Printf("ERROR: LookupISBN service failed for ISBN %s: %v", isbn, err)
+
+		switch {
+		case isInvalidISBN(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ISBN"})
+		case isMetadataNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "No metadata found for this ISBN"})
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to look up ISBN", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, bookMetadata)
 }
 
 func (h *BookHandler) CreateBook(c *gin.Context) {
@@ -27,13 +79,39 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 		return
 	}
 
-	createdBook, err := h.bookService.CreateBook(&book)
+	enrich := false
+
+	if enrichStr := c.Query("enrich"); enrichStr != "" {
+		parsed, err := strconv.ParseBool(enrichStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid enrich value, use true or false"})
+			return
+		}
+		enrich = parsed
+	}
+
+	createdBook, err := h.bookService.CreateBook(&book, enrich)
 
 	if err != nil {
 		log.Printf("ERROR: CreateBook service failed: %v", err)
 
-		if err.Error() == "service: book with ISBN "+book.Isbn+" already exists" {
-			c.JSON(http.StatusConflict, gin.H{"error": "Book with this ISBN already exists"})
+		if err.Error() == "livro com código de barras "+book.Barcode+" já existe" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Book with this barcode already exists"})
+			return
+		}
+
+		if strings.HasPrefix(err.Error(), "branch with ID ") && strings.HasSuffix(err.Error(), " does not exist") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown branch"})
+			return
+		}
+
+		if isInvalidBibliographic(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book data", "details": err.Error()})
+			return
+		}
+
+		if isInvalidISBN(err) || isMetadataNotFound(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Could not fill in the book from its ISBN", "details": err.Error()})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create book", "details": err.Error()})
@@ -94,6 +172,31 @@ func (h *BookHandler) GetBookByISBN(c *gin.Context) {
 	c.JSON(http.StatusOK, book)
 }
 
+func (h *BookHandler) GetBookByBarcode(c *gin.Context) {
+	barcode := c.Query("barcode")
+
+	if barcode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Barcode parameter is required"})
+		return
+	}
+
+	book, err := h.bookService.GetBookByBarcode(barcode)
+
+	if err != nil {
+		log.Printf("ERROR: GetBookByBarcode service failed for barcode %s: %v", barcode, err)
+
+		if err.Error() == "book with barcode "+barcode+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve book", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
 func (h *BookHandler) UpdateBook(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -121,6 +224,16 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 			return
 		}
 
+		if strings.HasPrefix(err.Error(), "branch with ID ") && strings.HasSuffix(err.Error(), " does not exist") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown branch"})
+			return
+		}
+
+		if isInvalidBibliographic(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book data", "details": err.Error()})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update book", "details": err.Error()})
 		return
 	}
@@ -154,8 +267,101 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// parseBookFilter reads the search parameters shared by GET /api/books and
+// GET /api/books/search. On invalid input it writes the error response and
+// returns false.
+func parseBookFilter(c *gin.Context) (model.BookFilter, bool) {
+	filter := model.BookFilter{
+		Query:       c.Query("q"),
+		BranchCode:  c.Query("branch"),
+		HomeBranch:  c.Query("home_branch"),
+		ItemType:    c.Query("item_type"),
+		Title:       c.Query("title"),
+		Author:      c.Query("author"),
+		Publisher:   c.Query("publisher"),
+		Edition:     c.Query("edition"),
+		Description: c.Query("description"),
+		Language:    c.Query("language"),
+		Subject:     c.Query("subject"),
+		Tag:         c.Query("tag"),
+		CallNumber:  c.Query("call_number"),
+	}
+
+	for param, target := range map[string]**int{
+		"year_from": &filter.YearFrom,
+		"year_to":   &filter.YearTo,
+		"pages_min": &filter.PagesMin,
+		"pages_max": &filter.PagesMax,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " parameter", "details": err.Error()})
+			return filter, false
+		}
+		*target = &n
+	}
+
+	for param, target := range map[string]**uuid.UUID{
+		"author_id":  &filter.ContributorID,
+		"subject_id": &filter.SubjectID,
+		"series_id":  &filter.SeriesID,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " parameter", "details": err.Error()})
+			return filter, false
+		}
+		*target = &id
+	}
+
+	// year is shorthand for a one-year range
+	if yearStr := c.Query("year"); yearStr != "" {
+		year, err := strconv.Atoi(yearStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year parameter", "details": err.Error()})
+			return filter, false
+		}
+		filter.YearFrom = &year
+		filter.YearTo = &year
+	}
+
+	if availableStr := c.Query("available"); availableStr != "" {
+		available, err := strconv.ParseBool(availableStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid available parameter", "details": err.Error()})
+			return filter, false
+		}
+		filter.Available = &available
+	}
+
+	if withdrawnStr := c.Query("withdrawn"); withdrawnStr != "" {
+		withdrawn, err := strconv.ParseBool(withdrawnStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid withdrawn parameter", "details": err.Error()})
+			return filter, false
+		}
+		filter.Withdrawn = &withdrawn
+	}
+
+	return filter, true
+}
+
 func (h *BookHandler) GetAllBooks(c *gin.Context) {
-	books, err := h.bookService.GetAllBooks()
+	filter, ok := parseBookFilter(c)
+
+	if !ok {
+		return
+	}
+
+	books, err := h.bookService.SearchBooks(filter)
 
 	if err != nil {
 		log.Printf("ERROR: GetAllBooks service failed: %v", err)
@@ -165,3 +371,43 @@ func (h *BookHandler) GetAllBooks(c *gin.Context) {
 
 	c.JSON(http.StatusOK, books)
 }
+
+// SearchCatalog pages through the books matching the same filters as
+// GetAllBooks and adds the facet counts used to refine the search.
+func (h *BookHandler) SearchCatalog(c *gin.Context) {
+	filter, ok := parseBookFilter(c)
+
+	if !ok {
+		return
+	}
+
+	filter.Limit = 50
+	facetLimit := 10
+
+	for param, target := range map[string]*int{
+		"limit":       &filter.Limit,
+		"offset":      &filter.Offset,
+		"facet_limit": &facetLimit,
+	} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n == 0 && param != "offset" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " parameter"})
+			return
+		}
+		*target = n
+	}
+
+	page, err := h.bookService.SearchCatalog(filter, facetLimit)
+
+	if err != nil {
+		log.Printf("ERROR: SearchCatalog service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search catalog", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
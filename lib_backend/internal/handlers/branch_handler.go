@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BranchHandler struct {
+	branchService services.BranchService
+}
+
+func NewBranchHandler(s services.BranchService) *BranchHandler {
+	return &BranchHandler{branchService: s}
+}
+
+func (h *BranchHandler) CreateBranch(c *gin.Context) {
+	var branch model.Branch
+
+	if err := c.ShouldBindJSON(&branch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	createdBranch, err := h.branchService.CreateBranch(&branch)
+
+	if err != nil {
+		log.Printf("ERROR: CreateBranch service failed: %v", err)
+
+		switch err.Error() {
+		case "branch code and name are required":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Branch code and name are required"})
+		case "branch with code " + branch.Code + " already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": "Branch with this code already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create branch", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdBranch)
+}
+
+func (h *BranchHandler) GetBranchByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID format", "details": err.Error()})
+		return
+	}
+
+	branch, err := h.branchService.GetBranchByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetBranchByID service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "branch with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve branch", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, branch)
+}
+
+func (h *BranchHandler) UpdateBranch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID format", "details": err.Error()})
+		return
+	}
+
+	var branch model.Branch
+
+	if err := c.ShouldBindJSON(&branch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	branch.ID = id
+
+	updatedBranch, err := h.branchService.UpdateBranch(&branch)
+
+	if err != nil {
+		log.Printf("ERROR: UpdateBranch service failed for ID %s: %v", id.String(), err)
+
+		switch err.Error() {
+		case "branch with ID " + id.String() + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
+		case "branch with code " + branch.Code + " already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": "Branch with this code already exists"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update branch", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedBranch)
+}
+
+func (h *BranchHandler) DeleteBranch(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID format", "details": err.Error()})
+		return
+	}
+
+	err = h.branchService.DeleteBranch(id)
+
+	if err != nil {
+		log.Printf("ERROR: DeleteBranch service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "branch with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete branch", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BranchHandler) GetAllBranches(c *gin.Context) {
+	branches, err := h.branchService.GetAllBranches()
+
+	if err != nil {
+		log.Printf("ERROR: GetAllBranches service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve branches", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, branches)
+}
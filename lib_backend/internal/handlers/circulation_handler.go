@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/dto"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CirculationHandler struct {
+	circulationService services.CirculationService
+}
+
+func NewCirculationHandler(s services.CirculationService) *CirculationHandler {
+	return &CirculationHandler{circulationService: s}
+}
+
+func (h *CirculationHandler) Checkout(c *gin.Context) {
+	var request dto.CheckoutRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.circulationService.Checkout(request.PatronBarcode, request.ItemBarcodes, request.BranchCode)
+
+	if err != nil {
+		log.Printf("ERROR: Checkout service failed for patron %s: %v", request.PatronBarcode, err)
+
+		switch err.Error() {
+		case "patron with barcode " + request.PatronBarcode + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Patron not found"})
+			return
+		case "patron with barcode " + request.PatronBarcode + " is erased":
+			c.JSON(http.StatusConflict, gin.H{"error": "Patron data was erased"})
+			return
+		case "branch with code " + request.BranchCode + " not found":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown branch"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check out items", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *CirculationHandler) Checkin(c *gin.Context) {
+	var request dto.CheckinRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.circulationService.Checkin(request.ItemBarcode, request.BranchCode)
+
+	if err != nil {
+		log.Printf("ERROR: Checkin service failed for item %s: %v", request.ItemBarcode, err)
+
+		switch err.Error() {
+		case "item with barcode " + request.ItemBarcode + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Item not found"})
+		case "item with barcode " + request.ItemBarcode + " is not on loan":
+			c.JSON(http.StatusConflict, gin.H{"error": "Item is not on loan"})
+		case "item with barcode " + request.ItemBarcode + " is in transit to another branch":
+			c.JSON(http.StatusConflict, gin.H{"error": "Item is in transit to another branch"})
+		case "branch with code " + request.BranchCode + " not found":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown branch"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check in item", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
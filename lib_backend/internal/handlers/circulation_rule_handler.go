@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CirculationRuleHandler struct {
+	ruleService services.CirculationRuleService
+}
+
+func NewCirculationRuleHandler(s services.CirculationRuleService) *CirculationRuleHandler {
+	return &CirculationRuleHandler{ruleService: s}
+}
+
+func (h *CirculationRuleHandler) respondRuleError(c *gin.Context, err error, id uuid.UUID, message string) {
+	switch err.Error() {
+	case "circulation rule with ID " + id.String() + " not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Circulation rule not found"})
+	case "invalid circulation rule values":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid circulation rule values"})
+	case "a circulation rule for this combination already exists":
+		c.JSON(http.StatusConflict, gin.H{"error": "A circulation rule for this combination already exists"})
+	case "circulation rule references an unknown patron category":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown patron category"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message, "details": err.Error()})
+	}
+}
+
+func (h *CirculationRuleHandler) CreateRule(c *gin.Context) {
+	var rule model.CirculationRule
+
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	createdRule, err := h.ruleService.CreateRule(&rule)
+
+	if err != nil {
+		log.Printf("ERROR: CreateRule service failed: %v", err)
+		h.respondRuleError(c, err, rule.ID, "Failed to create circulation rule")
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdRule)
+}
+
+func (h *CirculationRuleHandler) GetRuleByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid circulation rule ID format", "details": err.Error()})
+		return
+	}
+
+	rule, err := h.ruleService.GetRuleByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetRuleByID service failed for ID %s: %v", id.String(), err)
+		h.respondRuleError(c, err, id, "Failed to retrieve circulation rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *CirculationRuleHandler) UpdateRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid circulation rule ID format", "details": err.Error()})
+		return
+	}
+
+	var rule model.CirculationRule
+
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	rule.ID = id
+
+	updatedRule, err := h.ruleService.UpdateRule(&rule)
+
+	if err != nil {
+		log.Printf("ERROR: UpdateRule service failed for ID %s: %v", id.String(), err)
+		h.respondRuleError(c, err, id, "Failed to update circulation rule")
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedRule)
+}
+
+func (h *CirculationRuleHandler) DeleteRule(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid circulation rule ID format", "details": err.Error()})
+		return
+	}
+
+	err = h.ruleService.DeleteRule(id)
+
+	if err != nil {
+		log.Printf("ERROR: DeleteRule service failed for ID %s: %v", id.String(), err)
+		h.respondRuleError(c, err, id, "Failed to delete circulation rule")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CirculationRuleHandler) GetAllRules(c *gin.Context) {
+	rules, err := h.ruleService.GetAllRules()
+
+	if err != nil {
+		log.Printf("ERROR: GetAllRules service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve circulation rules", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ContributorHandler struct {
+	contributorService services.ContributorService
+}
+
+func NewContributorHandler(s services.ContributorService) *ContributorHandler {
+	return &ContributorHandler{contributorService: s}
+}
+
+func (h *ContributorHandler) SearchContributors(c *gin.Context) {
+	limit := 50
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	contributors, err := h.contributorService.SearchContributors(c.Query("q"), limit)
+
+	if err != nil {
+		log.Printf("ERROR: SearchContributors service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve authors", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, contributors)
+}
+
+func (h *ContributorHandler) GetContributorByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid author ID format", "details": err.Error()})
+		return
+	}
+
+	contributor, err := h.contributorService.GetContributorByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetContributorByID service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "contributor with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Author not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve author", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, contributor)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// coverFormOverhead is allowed on top of the image size for the rest of the
+// multipart body.
+const coverFormOverhead = 64 << 10
+
+type CoverHandler struct {
+	coverService services.CoverService
+	maxBytes     int64
+}
+
+func NewCoverHandler(s services.CoverService, cfg config.CoverConfig) *CoverHandler {
+	return &CoverHandler{coverService: s, maxBytes: cfg.MaxBytes}
+}
+
+func isCoverNotFound(err error, id uuid.UUID) bool {
+	return err.Error() == "book with ID "+id.String()+" not found" || err.Error() == "book with ID "+id.String()+" has no cover"
+}
+
+func (h *CoverHandler) UploadCover(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID format", "details": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+coverFormOverhead)
+	file, err := c.FormFile("cover")
+
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Cover image is too large", "details": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A cover image file is required in the cover form field", "details": err.Error()})
+		return
+	}
+
+	upload, err := file.Open()
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read cover image", "details": err.Error()})
+		return
+	}
+	defer upload.Close()
+
+	book, err := h.coverService.UploadCover(id, upload)
+
+	if err != nil {
+		log.Printf("ERROR: UploadCover service failed for book %s: %v", id.String(), err)
+
+		switch {
+		case isCoverNotFound(err, id):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		case strings.HasPrefix(err.Error(), "unsupported cover image type "):
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Cover must be a JPEG, PNG or WebP image", "details": err.Error()})
+		case strings.HasPrefix(err.Error(), "cover image exceeds "):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Cover image is too large", "details": err.Error()})
+		case strings.HasPrefix(err.Error(), "cover image of "), strings.HasPrefix(err.Error(), "invalid cover image: "):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cover image", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload cover", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+func (h *CoverHandler) GetCover(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID format", "details": err.Error()})
+		return
+	}
+
+	size := c.DefaultQuery("size", model.CoverSizeMedium)
+	blob, err := h.coverService.GetCover(id, size)
+
+	if err != nil {
+		switch {
+		case err.Error() == "invalid cover size "+size:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid size, use small, medium or large"})
+		case isCoverNotFound(err, id):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cover not found"})
+		default:
+			log.Printf("ERROR: GetCover service failed for book %s: %v", id.String(), err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve cover", "details": err.Error()})
+		}
+		return
+	}
+	defer blob.Body.Close()
+
+	// versioned URLs from the book response never change content
+	cacheControl := "no-cache"
+	if c.Query("v") != "" {
+		cacheControl = "public, max-age=31536000, immutable"
+	}
+
+	c.DataFromReader(http.StatusOK, blob.Size, blob.ContentType, blob.Body, map[string]string{"Cache-Control": cacheControl})
+}
+
+func (h *CoverHandler) DeleteCover(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID format", "details": err.Error()})
+		return
+	}
+
+	if _, err := h.coverService.DeleteCover(id); err != nil {
+		log.Printf("ERROR: DeleteCover service failed for book %s: %v", id.String(), err)
+
+		if isCoverNotFound(err, id) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Cover not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete cover", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
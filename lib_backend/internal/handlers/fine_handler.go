@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type FineHandler struct {
+	fineService services.FineService
+}
+
+func NewFineHandler(s services.FineService) *FineHandler {
+	return &FineHandler{fineService: s}
+}
+
+func (h *FineHandler) GetFinesByUserID(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format", "details": err.Error()})
+		return
+	}
+
+	fines, err := h.fineService.GetFinesByUserID(userID)
+
+	if err != nil {
+		log.Printf("ERROR: GetFinesByUserID service failed for user ID %s: %v", userID.String(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve fines for user", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, fines)
+}
+
+func (h *FineHandler) PayFine(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid fine ID format", "details": err.Error()})
+		return
+	}
+
+	fine, err := h.fineService.PayFine(id)
+
+	if err != nil {
+		log.Printf("ERROR: PayFine service failed for ID %s: %v", id.String(), err)
+
+		switch err.Error() {
+		case "fine with ID " + id.String() + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Fine not found"})
+		case "fine with ID " + id.String() + " has already been paid":
+			c.JSON(http.StatusConflict, gin.H{"error": "Fine already paid"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to pay fine", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, fine)
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"lib_backend/internal/dto"
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HoldHandler struct {
+	holdService services.HoldService
+}
+
+func NewHoldHandler(s services.HoldService) *HoldHandler {
+	return &HoldHandler{holdService: s}
+}
+
+func (h *HoldHandler) PlaceHold(c *gin.Context) {
+	var request dto.HoldRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	userID := uuid.MustParse(request.UserID)
+	bookID := uuid.MustParse(request.BookID)
+
+	hold := &model.Hold{UserID: userID, BookID: bookID}
+	pickupBranch := ""
+
+	if request.PickupBranchID != "" {
+		pickupBranchID := uuid.MustParse(request.PickupBranchID)
+		hold.PickupBranchID = &pickupBranchID
+		pickupBranch = pickupBranchID.String()
+	}
+
+	createdHold, err := h.holdService.PlaceHold(hold)
+
+	if err != nil {
+		log.Printf("ERROR: PlaceHold service failed: %v", err)
+
+		var limitErr *services.PatronLimitError
+		if errors.As(err, &limitErr) {
+			c.JSON(http.StatusConflict, patronLimitBody(limitErr))
+			return
+		}
+
+		switch err.Error() {
+		case "user with ID " + userID.String() + " not found for hold":
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case "user with ID " + userID.String() + " is erased":
+			c.JSON(http.StatusConflict, gin.H{"error": "User data was erased"})
+		case "book with ID " + bookID.String() + " not found for hold":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		case "holds are not allowed on book with ID " + bookID.String():
+			c.JSON(http.StatusConflict, gin.H{"error": "Holds are not allowed on this book"})
+		case "pickup branch with ID " + pickupBranch + " not found for hold":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown pickup branch"})
+		case "book with ID " + bookID.String() + " is available and cannot be held":
+			c.JSON(http.StatusConflict, gin.H{"error": "Book is available, borrow it instead"})
+		case "user " + userID.String() + " already has a hold on book " + bookID.String():
+			c.JSON(http.StatusConflict, gin.H{"error": "User already has a hold on this book"})
+		case "user " + userID.String() + " already has book " + bookID.String() + " on loan":
+			c.JSON(http.StatusConflict, gin.H{"error": "User already has this book on loan"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place hold", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdHold)
+}
+
+func (h *HoldHandler) GetHoldByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hold ID format", "details": err.Error()})
+		return
+	}
+
+	hold, err := h.holdService.GetHoldByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetHoldByID service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "hold with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve hold", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+func (h *HoldHandler) CancelHold(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid hold ID format", "details": err.Error()})
+		return
+	}
+
+	hold, err := h.holdService.CancelHold(id)
+
+	if err != nil {
+		log.Printf("ERROR: CancelHold service failed for ID %s: %v", id.String(), err)
+
+		switch err.Error() {
+		case "hold with ID " + id.String() + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Hold not found"})
+		case "hold with ID " + id.String() + " is no longer active":
+			c.JSON(http.StatusConflict, gin.H{"error": "Hold is no longer active"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel hold", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, hold)
+}
+
+func (h *HoldHandler) GetHoldsByUserID(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format", "details": err.Error()})
+		return
+	}
+
+	holds, err := h.holdService.GetHoldsByUserID(userID)
+
+	if err != nil {
+		log.Printf("ERROR: GetHoldsByUserID service failed for user ID %s: %v", userID.String(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve holds for user", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
+
+func (h *HoldHandler) GetHoldsByBookID(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("book_id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID format", "details": err.Error()})
+		return
+	}
+
+	holds, err := h.holdService.GetHoldsByBookID(bookID)
+
+	if err != nil {
+		log.Printf("ERROR: GetHoldsByBookID service failed for book ID %s: %v", bookID.String(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve holds for book", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, holds)
+}
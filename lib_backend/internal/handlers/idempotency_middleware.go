@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxIdempotencyKeyLength matches the key column.
+const maxIdempotencyKeyLength = 255
+
+// responseRecorder keeps a copy of the body written to the client.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// hashRequest identifies a request by method, path with query and body, and
+// restores the body for the handler.
+func hashRequest(c *gin.Context) (string, error) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return "", err
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Idempotency makes POST requests sent with an Idempotency-Key header safe to
+// retry: the first response is stored and replayed to later requests with the
+// same key. Keys are scoped by the client identity, so a client can neither
+// replay nor block the responses of another one. Server errors and rate
+// limited requests are not stored, so their retries run again.
+func Idempotency(s services.IdempotencyService, identity func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must have at most 255 characters"})
+			return
+		}
+
+		requestHash, err := hashRequest(c)
+
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body", "details": err.Error()})
+			return
+		}
+
+		scope := identity(c)
+		record, err := s.Begin(scope, key, requestHash)
+
+		if err != nil {
+			switch {
+			case strings.HasSuffix(err.Error(), " was used for a different request"):
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			case strings.HasSuffix(err.Error(), " is still in progress"):
+				c.Header("Retry-After", "1")
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still in progress"})
+			default:
+				log.Printf("ERROR: idempotency check failed for key %s: %v", key, err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Idempotency-Key", "details": err.Error()})
+			}
+			return
+		}
+
+		if record != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(*record.StatusCode, record.ContentType, record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		handled := false
+		defer func() {
+			// a panicking handler leaves no response to store
+			if !handled {
+				release(s, scope, key)
+			}
+		}()
+
+		c.Next()
+		handled = true
+
+		status := recorder.Status()
+
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			release(s, scope, key)
+			return
+		}
+
+		// the request took effect, so the key must not be freed for a retry to
+		// run it again; the reservation is taken over after the lock timeout
+		if err := s.Complete(scope, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Printf("ERROR: failed to store response of idempotency key %s: %v", key, err)
+		}
+	}
+}
+
+// release frees a key whose request did not take effect, so a retry runs it.
+func release(s services.IdempotencyService, scope string, key string) {
+	if err := s.Release(scope, key); err != nil {
+		log.Printf("ERROR: failed to release idempotency key %s: %v", key, err)
+	}
+}
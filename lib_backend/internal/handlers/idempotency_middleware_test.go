@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeIdempotencyService keeps the records in memory with the semantics of
+// the Postgres-backed service.
+type fakeIdempotencyService struct {
+	services.IdempotencyService
+	mu      sync.Mutex
+	records map[string]*model.IdempotencyRecord
+	begins  int
+	// completeErr makes Complete fail, as when the database is unreachable
+	completeErr error
+}
+
+func newFakeIdempotencyService() *fakeIdempotencyService {
+	return &fakeIdempotencyService{records: make(map[string]*model.IdempotencyRecord)}
+}
+
+func (s *fakeIdempotencyService) Begin(scope string, key string, requestHash string) (*model.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.begins++
+
+	record, ok := s.records[scope+"\n"+key]
+	if !ok {
+		s.records[scope+"\n"+key] = &model.IdempotencyRecord{Scope: scope, Key: key, RequestHash: requestHash, CreatedAt: time.Now()}
+		return nil, nil
+	}
+	if record.RequestHash != requestHash {
+		return nil, fmt.Errorf("idempotency key %s was used for a different request", key)
+	}
+	if record.StatusCode == nil {
+		return nil, fmt.Errorf("idempotency key %s is still in progress", key)
+	}
+	copied := *record
+	return &copied, nil
+}
+
+func (s *fakeIdempotencyService) Complete(scope string, key string, statusCode int, contentType string, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+	record := s.records[scope+"\n"+key]
+	record.StatusCode = &statusCode
+	record.ContentType = contentType
+	record.ResponseBody = append([]byte(nil), body...)
+	return nil
+}
+
+func (s *fakeIdempotencyService) Release(scope string, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, scope+"\n"+key)
+	return nil
+}
+
+// newIdempotentRouter serves POST /api/loans behind the idempotency
+// middleware, scoped by the X-User-ID header, and counts handler runs.
+func newIdempotentRouter(runs *atomic.Int32, handler gin.HandlerFunc) *gin.Engine {
+	return newIdempotentRouterWith(newFakeIdempotencyService(), runs, handler)
+}
+
+func newIdempotentRouterWith(s services.IdempotencyService, runs *atomic.Int32, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	identity := func(c *gin.Context) string { return "user:" + c.GetHeader("X-User-ID") }
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.POST("/api/loans", Idempotency(s, identity), func(c *gin.Context) {
+		runs.Add(1)
+		handler(c)
+	})
+	return r
+}
+
+func postIdempotent(r http.Handler, userID string, key string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/loans", strings.NewReader(body))
+	req.Header.Set("X-User-ID", userID)
+	req.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplaysResponseToSameClient(t *testing.T) {
+	var runs atomic.Int32
+	r := newIdempotentRouter(&runs, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"run": runs.Load()})
+	})
+
+	first := postIdempotent(r, "alice", "k1", `{"bookId":"1"}`)
+	retry := postIdempotent(r, "alice", "k1", `{"bookId":"1"}`)
+
+	if runs.Load() != 1 {
+		t.Fatalf("handler ran %d times, want once", runs.Load())
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry = %d %q, want replay of %d %q", retry.Code, retry.Body.String(), first.Code, first.Body.String())
+	}
+
+	if w := postIdempotent(r, "alice", "k1", `{"bookId":"2"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reuse with another body status = %d, want 422", w.Code)
+	}
+}
+
+func TestIdempotencyScopesKeysByClient(t *testing.T) {
+	var runs atomic.Int32
+	r := newIdempotentRouter(&runs, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"user": c.GetHeader("X-User-ID")})
+	})
+
+	postIdempotent(r, "alice", "shared", `{"bookId":"1"}`)
+	w := postIdempotent(r, "bob", "shared", `{"bookId":"1"}`)
+
+	if runs.Load() != 2 {
+		t.Fatalf("handler ran %d times, want once per client", runs.Load())
+	}
+	if w.Header().Get("Idempotent-Replayed") != "" || !strings.Contains(w.Body.String(), "bob") {
+		t.Fatalf("second client got %q, want its own response", w.Body.String())
+	}
+
+	if w := postIdempotent(r, "bob", "shared", `{"bookId":"2"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("reuse within the second client status = %d, want 422", w.Code)
+	}
+}
+
+func TestIdempotencyRunsConcurrentRetriesOnce(t *testing.T) {
+	var runs atomic.Int32
+	release := make(chan struct{})
+	r := newIdempotentRouter(&runs, func(c *gin.Context) {
+		<-release
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	const retries = 10
+	codes := make([]int, retries)
+	var wg sync.WaitGroup
+
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = postIdempotent(r, "alice", "k1", `{"bookId":"1"}`).Code
+		}(i)
+	}
+
+	// the retries that lost the race return at once; the winner waits
+	for deadline := time.Now().Add(5 * time.Second); runs.Load() == 0 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	created, inProgress := 0, 0
+	for _, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			inProgress++
+		default:
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+
+	if runs.Load() != 1 || created+inProgress != retries {
+		t.Fatalf("handler ran %d times with %d created and %d in progress, want one run", runs.Load(), created, inProgress)
+	}
+
+	if w := postIdempotent(r, "alice", "k1", `{"bookId":"1"}`); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry after completion = %d, want replayed 201", w.Code)
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterServerError(t *testing.T) {
+	var runs atomic.Int32
+	r := newIdempotentRouter(&runs, func(c *gin.Context) {
+		if runs.Load() == 1 {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "boom"})
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	if w := postIdempotent(r, "alice", "k1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d, want 500", w.Code)
+	}
+	if w := postIdempotent(r, "alice", "k1", `{}`); w.Code != http.StatusCreated || runs.Load() != 2 {
+		t.Fatalf("retry status = %d after %d runs, want 201 from a second run", w.Code, runs.Load())
+	}
+}
+
+func TestIdempotencyReleasesKeyAfterPanic(t *testing.T) {
+	var runs atomic.Int32
+	r := newIdempotentRouter(&runs, func(c *gin.Context) {
+		if runs.Load() == 1 {
+			panic("boom")
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	if w := postIdempotent(r, "alice", "k1", `{}`); w.Code != http.StatusInternalServerError {
+		t.Fatalf("first status = %d, want 500 from the recovered panic", w.Code)
+	}
+	if w := postIdempotent(r, "alice", "k1", `{}`); w.Code != http.StatusCreated || runs.Load() != 2 {
+		t.Fatalf("retry status = %d after %d runs, want 201 from a second run", w.Code, runs.Load())
+	}
+}
+
+func TestIdempotencyKeepsKeyWhenStoringResponseFails(t *testing.T) {
+	var runs atomic.Int32
+	idempotencyService := newFakeIdempotencyService()
+	idempotencyService.completeErr = fmt.Errorf("connection refused")
+	r := newIdempotentRouterWith(idempotencyService, &runs, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	if w := postIdempotent(r, "alice", "k1", `{}`); w.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want 201", w.Code)
+	}
+	if w := postIdempotent(r, "alice", "k1", `{}`); w.Code != http.StatusConflict || runs.Load() != 1 {
+		t.Fatalf("retry status = %d after %d runs, want 409 without running the handler again", w.Code, runs.Load())
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"lib_backend/internal/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewJobHandler(s *scheduler.Scheduler) *JobHandler {
+	return &JobHandler{scheduler: s}
+}
+
+func (h *JobHandler) GetJobs(c *gin.Context) {
+	jobs, err := h.scheduler.Jobs()
+
+	if err != nil {
+		log.Printf("ERROR: GetJobs failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve jobs", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leader": h.scheduler.IsLeader(), "jobs": jobs})
+}
+
+func (h *JobHandler) GetJobRuns(c *gin.Context) {
+	name := c.Param("name")
+	limit := 50
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	runs, err := h.scheduler.Runs(name, limit)
+
+	if err != nil {
+		log.Printf("ERROR: GetJobRuns failed for job %s: %v", name, err)
+
+		if err.Error() == "job "+name+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve job runs", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+func (h *JobHandler) TriggerJob(c *gin.Context) {
+	name := c.Param("name")
+
+	run, err := h.scheduler.Trigger(name)
+
+	if err != nil {
+		log.Printf("ERROR: TriggerJob failed for job %s: %v", name, err)
+
+		switch err.Error() {
+		case "job " + name + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case "job " + name + " is already running":
+			c.JSON(http.StatusConflict, gin.H{"error": "Job is already running"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to trigger job", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, run)
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"time"
 
 	"lib_backend/internal/dto"
 	"lib_backend/internal/model"
@@ -20,6 +22,16 @@ func NewLoanHandler(s services.LoanService) *LoanHandler {
 	return &LoanHandler{loanService: s}
 }
 
+func patronLimitBody(limitErr *services.PatronLimitError) gin.H {
+	return gin.H{
+		"error":    limitErr.Error(),
+		"category": limitErr.Category,
+		"limit":    limitErr.Limit,
+		"max":      limitErr.Max,
+		"current":  limitErr.Current,
+	}
+}
+
 func (h *LoanHandler) CreateLoan(c *gin.Context) {
 	var request dto.LoanRequest
 
@@ -35,17 +47,32 @@ func (h *LoanHandler) CreateLoan(c *gin.Context) {
 		UserID:   parsedUserID,
 		BookID:   parsedBookID,
 		Returned: false,
-		LoanedAt: model.DefaultLoanedAt(),
+		LoanedAt: time.Now(),
+	}
+
+	if request.BranchID != "" {
+		branchID := uuid.MustParse(request.BranchID)
+		loanToCreate.CheckoutBranchID = &branchID
 	}
 
 	createdLoan, err := h.loanService.CreateLoan(loanToCreate)
 
 	if err != nil {
+		var limitErr *services.PatronLimitError
+		if errors.As(err, &limitErr) {
+			c.JSON(http.StatusConflict, patronLimitBody(limitErr))
+			return
+		}
+
 		switch err.Error() {
 		case "user with ID " + parsedUserID.String() + " not found for loan":
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case "user with ID " + parsedUserID.String() + " is erased":
+			c.JSON(http.StatusConflict, gin.H{"error": "User data was erased"})
 		case "book with ID " + parsedBookID.String() + " is not available for loan":
 			c.JSON(http.StatusConflict, gin.H{"error": "Book is not available for loan"})
+		case "book with ID " + parsedBookID.String() + " is non-circulating":
+			c.JSON(http.StatusConflict, gin.H{"error": "Book is non-circulating"})
 		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan", "details": err.Error()})
 		}
@@ -129,7 +156,18 @@ func (h *LoanHandler) ReturnBook(c *gin.Context) {
 		return
 	}
 
-	returnedLoan, err := h.loanService.ReturnBook(id)
+	var returnBranchID *uuid.UUID
+
+	if branchIDStr := c.Query("branch_id"); branchIDStr != "" {
+		branchID, err := uuid.Parse(branchIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID format", "details": err.Error()})
+			return
+		}
+		returnBranchID = &branchID
+	}
+
+	returnedLoan, err := h.loanService.ReturnBook(id, returnBranchID)
 
 	if err != nil {
 		log.Printf("ERROR: ReturnBook service failed for ID %s: %v", id.String(), err)
@@ -148,6 +186,72 @@ func (h *LoanHandler) ReturnBook(c *gin.Context) {
 	c.JSON(http.StatusOK, returnedLoan)
 }
 
+func (h *LoanHandler) RenewLoan(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID format", "details": err.Error()})
+		return
+	}
+
+	renewedLoan, err := h.loanService.RenewLoan(id)
+
+	if err != nil {
+		log.Printf("ERROR: RenewLoan service failed for ID %s: %v", id.String(), err)
+
+		var limitErr *services.PatronLimitError
+		if errors.As(err, &limitErr) {
+			c.JSON(http.StatusConflict, patronLimitBody(limitErr))
+			return
+		}
+
+		switch err.Error() {
+		case "loan with ID " + id.String() + " not found for renewal":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		case "loan with ID " + id.String() + " has already been returned":
+			c.JSON(http.StatusConflict, gin.H{"error": "Loan already returned"})
+		case "loan with ID " + id.String() + " cannot be renewed because the book has holds":
+			c.JSON(http.StatusConflict, gin.H{"error": "Book has holds and cannot be renewed"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to renew loan", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, renewedLoan)
+}
+
+func (h *LoanHandler) ExplainLoanPolicy(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID format", "details": err.Error()})
+		return
+	}
+
+	explanation, err := h.loanService.ExplainLoanPolicy(id)
+
+	if err != nil {
+		log.Printf("ERROR: ExplainLoanPolicy service failed for ID %s: %v", id.String(), err)
+
+		switch err.Error() {
+		case "loan with ID " + id.String() + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+			return
+		case "loan with ID " + id.String() + " is anonymized":
+			c.JSON(http.StatusConflict, gin.H{"error": "Loan no longer records its patron"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to explain loan policy", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, explanation)
+}
+
 func (h *LoanHandler) DeleteLoan(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
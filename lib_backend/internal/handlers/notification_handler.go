@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+func NewNotificationHandler(s services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: s}
+}
+
+func (h *NotificationHandler) GetNotificationsByUserID(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format", "details": err.Error()})
+		return
+	}
+
+	notifications, err := h.notificationService.GetNotificationsByUserID(userID)
+
+	if err != nil {
+		log.Printf("ERROR: GetNotificationsByUserID service failed for user %s: %v", userID.String(), err)
+
+		if err.Error() == "user with ID "+userID.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve notifications", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) SendDueSoonReminders(c *gin.Context) {
+	sent, err := h.notificationService.SendDueSoonReminders()
+
+	if err != nil {
+		log.Printf("ERROR: SendDueSoonReminders service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send due date reminders", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sent": sent})
+}
+
+func (h *NotificationHandler) SendOverdueNotices(c *gin.Context) {
+	sent, err := h.notificationService.SendOverdueNotices()
+
+	if err != nil {
+		log.Printf("ERROR: SendOverdueNotices service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send overdue notices", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sent": sent})
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"net/http"
+
+	"lib_backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetOpenAPIDocument(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", openapi.Document())
+}
+
+// docsPolicy keeps the docs page to its inline script and style and to
+// fetching the document from this server.
+const docsPolicy = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'"
+
+func GetDocsPage(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", openapi.DocsPage())
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OpenAPIValidator checks the routes described in the OpenAPI document.
+// Requests that do not match are rejected with 400; responses that do not
+// match were already sent, so they are only logged.
+func OpenAPIValidator(spec *openapi.Spec, cfg config.OpenAPIConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !cfg.ValidateRequests && !cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		operation := spec.Operation(c.Request.Method, c.FullPath())
+
+		if operation == nil {
+			c.Next()
+			return
+		}
+
+		if cfg.ValidateRequests {
+			contentType := c.GetHeader("Content-Type")
+			var body []byte
+
+			// other bodies, such as cover uploads, are left to the handler
+			if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "" || mediaType == "application/json" {
+				var err error
+				body, err = io.ReadAll(c.Request.Body)
+				if err != nil {
+					c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body", "details": err.Error()})
+					return
+				}
+				c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			pathParams := make(map[string]string, len(c.Params))
+			for _, param := range c.Params {
+				pathParams[param.Key] = param.Value
+			}
+
+			if err := operation.ValidateRequest(pathParams, c.Request.URL.Query(), contentType, body); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Request does not match the API specification", "details": err.Error()})
+				return
+			}
+		}
+
+		if !cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		c.Next()
+
+		if err := operation.ValidateResponse(recorder.Status(), recorder.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			log.Printf("WARNING: response %d of %s %s does not match the API specification: %v", recorder.Status(), c.Request.Method, c.FullPath(), err)
+		}
+	}
+}
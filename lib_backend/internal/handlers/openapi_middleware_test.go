@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newValidatedRouter(t *testing.T, handled *int) *gin.Engine {
+	t.Helper()
+
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("openapi.Load() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(OpenAPIValidator(spec, config.OpenAPIConfig{ValidateRequests: true}))
+	handler := func(c *gin.Context) {
+		*handled++
+		c.Status(http.StatusNoContent)
+	}
+	r.POST("/api/loans", handler)
+	r.GET("/api/loans/:id", handler)
+	return r
+}
+
+func TestOpenAPIValidatorRejectsInvalidRequests(t *testing.T) {
+	const userID, bookID = "0b8e4b4e-7d39-4a4a-9d36-7d1a54c3f0f1", "5f0c6a52-2b7e-4c1e-9a51-3c1f0e6d2a10"
+
+	cases := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		details string
+	}{
+		{"missing required field", http.MethodPost, "/api/loans", `{"userId":"` + userID + `"}`, "body.bookId: is required"},
+		{"invalid body format", http.MethodPost, "/api/loans", `{"userId":"` + userID + `","bookId":"7"}`, `body.bookId: "7" is not a valid UUID`},
+		{"invalid path parameter", http.MethodGet, "/api/loans/7", "", `path parameter id: "7" is not a valid UUID`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handled := 0
+			r := newValidatedRouter(t, &handled)
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			var response struct {
+				Error   string `json:"error"`
+				Details string `json:"details"`
+			}
+			json.Unmarshal(w.Body.Bytes(), &response)
+
+			if w.Code != http.StatusBadRequest || response.Error != "Request does not match the API specification" || response.Details != tc.details {
+				t.Fatalf("status = %d, body = %s, want 400 with %q", w.Code, w.Body.String(), tc.details)
+			}
+			if handled != 0 {
+				t.Fatal("handler ran for a request that does not match the specification")
+			}
+		})
+	}
+
+	handled := 0
+	r := newValidatedRouter(t, &handled)
+	req := httptest.NewRequest(http.MethodPost, "/api/loans", strings.NewReader(`{"userId":"`+userID+`","bookId":"`+bookID+`"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent || handled != 1 {
+		t.Fatalf("valid request status = %d, handled = %d, want it passed to the handler", w.Code, handled)
+	}
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PatronCategoryHandler struct {
+	categoryService services.PatronCategoryService
+}
+
+func NewPatronCategoryHandler(s services.PatronCategoryService) *PatronCategoryHandler {
+	return &PatronCategoryHandler{categoryService: s}
+}
+
+func (h *PatronCategoryHandler) GetCategoryByCode(c *gin.Context) {
+	code := c.Param("code")
+
+	category, err := h.categoryService.GetCategoryByCode(code)
+
+	if err != nil {
+		log.Printf("ERROR: GetCategoryByCode service failed for code %s: %v", code, err)
+
+		if err.Error() == "patron category "+code+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Patron category not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patron category", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+func (h *PatronCategoryHandler) UpdateCategory(c *gin.Context) {
+	code := c.Param("code")
+
+	var category model.PatronCategory
+
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	category.Code = code
+
+	updatedCategory, err := h.categoryService.UpdateCategory(&category)
+
+	if err != nil {
+		log.Printf("ERROR: UpdateCategory service failed for code %s: %v", code, err)
+
+		switch err.Error() {
+		case "patron category " + code + " not found for update":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Patron category not found"})
+		case "invalid limits for patron category " + code:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patron category limits"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update patron category", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedCategory)
+}
+
+func (h *PatronCategoryHandler) GetAllCategories(c *gin.Context) {
+	categories, err := h.categoryService.GetAllCategories()
+
+	if err != nil {
+		log.Printf("ERROR: GetAllCategories service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve patron categories", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, categories)
+}
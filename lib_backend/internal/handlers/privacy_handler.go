@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PrivacyHandler struct {
+	privacyService services.PrivacyService
+}
+
+func NewPrivacyHandler(s services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{privacyService: s}
+}
+
+// writeExportZip packs each part of the export as its own JSON file.
+func writeExportZip(export *model.UserDataExport) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"loans.json", export.Loans},
+		{"holds.json", export.Holds},
+		{"fines.json", export.Fines},
+		{"notifications.json", export.Notifications},
+		{"events.json", export.Events},
+	}
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", file.name, err)
+		}
+		w, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s to export: %w", file.name, err)
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to write %s to export: %w", file.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish export archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ExportUserData returns everything stored about the user as a ZIP of JSON
+// files, or as a single JSON document with format=json.
+func (h *PrivacyHandler) ExportUserData(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format", "details": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "zip")
+
+	if format != "zip" && format != "json" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format, use zip or json"})
+		return
+	}
+
+	export, err := h.privacyService.ExportUserData(id)
+
+	if err != nil {
+		log.Printf("ERROR: ExportUserData service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "user with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data", "details": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("user-%s-%s", id.String(), export.ExportedAt.Format("20060102"))
+
+	if format == "json" {
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.JSON(http.StatusOK, export)
+		return
+	}
+
+	archive, err := writeExportZip(export)
+
+	if err != nil {
+		log.Printf("ERROR: failed to build data export archive for user %s: %v", id.String(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, filename))
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+func (h *PrivacyHandler) EraseUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format", "details": err.Error()})
+		return
+	}
+
+	result, err := h.privacyService.EraseUser(id)
+
+	if err != nil {
+		log.Printf("ERROR: EraseUser service failed for ID %s: %v", id.String(), err)
+
+		message := err.Error()
+		prefix := "user with ID " + id.String()
+
+		switch {
+		case message == prefix+" not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case message == prefix+" is already erased":
+			c.JSON(http.StatusConflict, gin.H{"error": "User data was already erased"})
+		case strings.HasPrefix(message, prefix+" has "):
+			c.JSON(http.StatusConflict, gin.H{"error": "User must settle active loans, holds and unpaid fines before erasure", "details": message})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to erase user", "details": message})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/ratelimit"
+	"lib_backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RateLimiter struct {
+	store ratelimit.Store
+	cfg   config.RateLimitConfig
+}
+
+func NewRateLimiter(store ratelimit.Store, cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{store: store, cfg: cfg}
+}
+
+// identity names the client a request counts against. API keys are only
+// trusted when configured, so a client cannot get a fresh bucket by sending a
+// made-up key; the X-User-ID header is trusted as is and should only be
+// enabled behind a gateway that sets it.
+func (l *RateLimiter) identity(c *gin.Context) string {
+	for _, kind := range l.cfg.Identities {
+		switch kind {
+		case config.RateLimitIdentityAPIKey:
+			if key := c.GetHeader("X-API-Key"); key != "" && slices.Contains(l.cfg.APIKeys, key) {
+				sum := sha256.Sum256([]byte(key))
+				return "key:" + hex.EncodeToString(sum[:8])
+			}
+		case config.RateLimitIdentityUser:
+			if userID := c.GetHeader("X-User-ID"); userID != "" {
+				return "user:" + userID
+			}
+		case config.RateLimitIdentityIP:
+			return "ip:" + c.ClientIP()
+		}
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// For limits the requests of each client to a route group. When the store
+// fails the request goes through: the limiter protects the API, it must not
+// take it down.
+func (l *RateLimiter) For(group string) gin.HandlerFunc {
+	limit := l.cfg.GroupLimit(group)
+	policy := fmt.Sprintf("%d;w=%s", limit.Requests, ceilSeconds(limit.Period))
+
+	return func(c *gin.Context) {
+		if !l.cfg.Enabled || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		result, err := l.store.Take(group+":"+l.identity(c), limit)
+
+		if err != nil {
+			log.Printf("ERROR: rate limiter failed for %s, letting the request through: %v", group, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy)
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+
+		if !result.Allowed {
+			retryAfter := ceilSeconds(result.RetryAfter)
+			c.Header("Retry-After", retryAfter)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests", "details": "retry after " + retryAfter + " seconds"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// newRateLimitStore builds the configured store. A bucket idle for the
+// longest period of any limit is full again, so it can be dropped.
+func newRateLimitStore(db *sql.DB, cfg config.RateLimitConfig) ratelimit.Store {
+	idleTTL := cfg.Default.Period
+	for _, limit := range cfg.Groups {
+		idleTTL = max(idleTTL, limit.Period)
+	}
+
+	if cfg.Store == config.RateLimitStorePostgres {
+		return ratelimit.NewPostgresStore(repository.NewRateLimitRepository(db), idleTTL)
+	}
+
+	return ratelimit.NewMemoryStore(idleTTL)
+}
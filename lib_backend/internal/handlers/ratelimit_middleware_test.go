@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/ratelimit"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newLimitedRouter(limit config.RateLimit, idempotencyService services.IdempotencyService, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := config.RateLimitConfig{Enabled: true, Default: limit, Identities: []string{config.RateLimitIdentityIP}}
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore(time.Hour), cfg)
+
+	r := gin.New()
+	if err := r.SetTrustedProxies(config.LoadTrustedProxies()); err != nil {
+		panic(err)
+	}
+	r.POST("/api/loans", limiter.For("loans"), Idempotency(idempotencyService, limiter.identity), handler)
+	return r
+}
+
+func postLoan(r http.Handler, remoteAddr string, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/loans", strings.NewReader(`{"userId":"1"}`))
+	req.RemoteAddr = remoteAddr
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimiterRefusesBeforeIdempotencyLookup(t *testing.T) {
+	idempotencyService := newFakeIdempotencyService()
+	r := newLimitedRouter(config.RateLimit{Requests: 1, Period: time.Minute}, idempotencyService, func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": "1"})
+	})
+
+	if w := postLoan(r, "10.0.0.1:1234", "first"); w.Code != http.StatusCreated {
+		t.Fatalf("first request status = %d, want 201", w.Code)
+	}
+
+	for _, key := range []string{"first", "second"} {
+		w := postLoan(r, "10.0.0.1:1234", key)
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+			t.Fatalf("limited request with key %s status = %d, want 429 with Retry-After", key, w.Code)
+		}
+	}
+
+	if idempotencyService.begins != 1 {
+		t.Fatalf("idempotency store consulted %d times, want only for the allowed request", idempotencyService.begins)
+	}
+}
+
+func TestRateLimiterAllowsLimitUnderConcurrency(t *testing.T) {
+	r := newLimitedRouter(config.RateLimit{Requests: 3, Period: time.Minute}, newFakeIdempotencyService(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	var wg sync.WaitGroup
+	codes := make([]int, 20)
+
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = postLoan(r, "10.0.0.1:1234", "").Code
+		}(i)
+	}
+	wg.Wait()
+
+	allowed := 0
+	for _, code := range codes {
+		if code == http.StatusNoContent {
+			allowed++
+		}
+	}
+
+	if allowed != 3 {
+		t.Fatalf("%d requests allowed, want 3", allowed)
+	}
+
+	if w := postLoan(r, "10.0.0.2:1234", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("request from another client status = %d, want 204", w.Code)
+	}
+}
+
+func postLoanForwardedFor(r http.Handler, remoteAddr string, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/loans", strings.NewReader(`{"userId":"1"}`))
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimiterIgnoresSpoofedForwardedFor(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "")
+	r := newLimitedRouter(config.RateLimit{Requests: 1, Period: time.Minute}, newFakeIdempotencyService(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	if code := postLoanForwardedFor(r, "10.0.0.1:1234", "203.0.113.1"); code != http.StatusNoContent {
+		t.Fatalf("first request status = %d, want 204", code)
+	}
+
+	if code := postLoanForwardedFor(r, "10.0.0.1:1234", "203.0.113.2"); code != http.StatusTooManyRequests {
+		t.Fatalf("request with another X-Forwarded-For status = %d, want 429 from the same bucket", code)
+	}
+}
+
+func TestRateLimiterTrustsForwardedForFromConfiguredProxy(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8")
+	r := newLimitedRouter(config.RateLimit{Requests: 1, Period: time.Minute}, newFakeIdempotencyService(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	for _, client := range []string{"203.0.113.1", "203.0.113.2"} {
+		if code := postLoanForwardedFor(r, "10.0.0.1:1234", client); code != http.StatusNoContent {
+			t.Fatalf("request for client %s behind the proxy status = %d, want 204", client, code)
+		}
+	}
+
+	if code := postLoanForwardedFor(r, "10.0.0.1:1234", "203.0.113.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("second request for the same client status = %d, want 429", code)
+	}
+}
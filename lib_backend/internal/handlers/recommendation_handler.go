@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RecommendationHandler struct {
+	recommendationService services.RecommendationService
+}
+
+func NewRecommendationHandler(s services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{recommendationService: s}
+}
+
+func parseRecommendationLimit(c *gin.Context) (int, bool) {
+	limit := 10
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, use a value from 1 to 100"})
+			return 0, false
+		}
+		limit = parsed
+	}
+
+	return limit, true
+}
+
+func (h *RecommendationHandler) GetRelatedBooks(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID format", "details": err.Error()})
+		return
+	}
+
+	limit, ok := parseRecommendationLimit(c)
+
+	if !ok {
+		return
+	}
+
+	recommendations, err := h.recommendationService.GetRelatedBooks(id, limit)
+
+	if err != nil {
+		log.Printf("ERROR: GetRelatedBooks service failed for book %s: %v", id.String(), err)
+
+		if err.Error() == "book with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve related books", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
+
+func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format", "details": err.Error()})
+		return
+	}
+
+	limit, ok := parseRecommendationLimit(c)
+
+	if !ok {
+		return
+	}
+
+	recommendations, err := h.recommendationService.GetRecommendations(id, limit)
+
+	if err != nil {
+		log.Printf("ERROR: GetRecommendations service failed for user %s: %v", id.String(), err)
+
+		if err.Error() == "user with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve recommendations", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendations)
+}
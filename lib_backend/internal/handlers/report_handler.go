@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReportHandler struct {
+	reportService services.ReportService
+}
+
+func NewReportHandler(s services.ReportService) *ReportHandler {
+	return &ReportHandler{reportService: s}
+}
+
+// parseReportDate accepts a date, covering the whole day, or an RFC 3339
+// timestamp. end is true for the upper bound, which is exclusive, so a date
+// given as "to" still includes that day.
+func parseReportDate(value string, end bool) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// parseReportFilter reads the parameters shared by every report. On invalid
+// input it writes the error response and returns false.
+func parseReportFilter(c *gin.Context) (model.ReportFilter, bool) {
+	filter := model.ReportFilter{Category: c.Query("category"), Interval: c.Query("interval"), Limit: 10}
+
+	for param, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		t, err := parseReportDate(value, param == "to")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " parameter, use YYYY-MM-DD or RFC 3339", "details": err.Error()})
+			return filter, false
+		}
+		*target = t
+	}
+
+	if branchIDStr := c.Query("branch_id"); branchIDStr != "" {
+		branchID, err := uuid.Parse(branchIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch_id parameter", "details": err.Error()})
+			return filter, false
+		}
+		filter.BranchID = &branchID
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, use a value from 1 to 1000"})
+			return filter, false
+		}
+		filter.Limit = limit
+	}
+
+	return filter, true
+}
+
+func wantsCSV(c *gin.Context) bool {
+	if format := c.Query("format"); format != "" {
+		return format == "csv"
+	}
+	return strings.Contains(c.GetHeader("Accept"), "text/csv")
+}
+
+// respondReport writes data as JSON, or as a CSV download of header and
+// rows when the client asks for format=csv or Accept: text/csv.
+func respondReport(c *gin.Context, name string, data any, header []string, rows [][]string) {
+	if !wantsCSV(c) {
+		c.JSON(http.StatusOK, data)
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, name))
+	c.Status(http.StatusOK)
+
+	writer := csv.NewWriter(c.Writer)
+
+	if err := writer.WriteAll(append([][]string{header}, rows...)); err != nil {
+		log.Printf("ERROR: failed to write %s report as CSV: %v", name, err)
+	}
+}
+
+func respondReportError(c *gin.Context, name string, err error) {
+	log.Printf("ERROR: %s report failed: %v", name, err)
+
+	message := err.Error()
+
+	switch {
+	case message == "report start must be before its end":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The from date must be before the to date"})
+	case strings.HasPrefix(message, "report interval "):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid interval, use day, week or month"})
+	case strings.HasPrefix(message, "weeding period of "):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid years, use a positive number"})
+	case strings.HasPrefix(message, "report period is too long"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Period too long for this interval, use a shorter range or a larger interval"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build report", "details": message})
+	}
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', 4, 64)
+}
+
+func (h *ReportHandler) GetLoanVolume(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+
+	if !ok {
+		return
+	}
+
+	volume, err := h.reportService.GetLoanVolume(filter)
+
+	if err != nil {
+		respondReportError(c, "loans", err)
+		return
+	}
+
+	rows := make([][]string, 0, len(volume))
+	for _, entry := range volume {
+		rows = append(rows, []string{entry.Period.Format(time.RFC3339), strconv.Itoa(entry.Loans), strconv.Itoa(entry.Patrons)})
+	}
+
+	respondReport(c, "loans", volume, []string{"period", "loans", "patrons"}, rows)
+}
+
+func (h *ReportHandler) GetTopBooks(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+
+	if !ok {
+		return
+	}
+
+	books, err := h.reportService.GetTopBooks(filter)
+
+	if err != nil {
+		respondReportError(c, "top-books", err)
+		return
+	}
+
+	rows := make([][]string, 0, len(books))
+	for _, book := range books {
+		rows = append(rows, []string{book.BookID.String(), book.Title, book.Author, book.Isbn, strconv.Itoa(book.Loans)})
+	}
+
+	respondReport(c, "top-books", books, []string{"book_id", "title", "author", "isbn", "loans"}, rows)
+}
+
+func (h *ReportHandler) GetActivePatrons(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+
+	if !ok {
+		return
+	}
+
+	patrons, err := h.reportService.GetActivePatrons(filter)
+
+	if err != nil {
+		respondReportError(c, "active-patrons", err)
+		return
+	}
+
+	rows := make([][]string, 0, len(patrons))
+	for _, entry := range patrons {
+		rows = append(rows, []string{entry.Category, strconv.Itoa(entry.Patrons), strconv.Itoa(entry.Loans)})
+	}
+
+	respondReport(c, "active-patrons", patrons, []string{"category", "patrons", "loans"}, rows)
+}
+
+func (h *ReportHandler) GetLoanDuration(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+
+	if !ok {
+		return
+	}
+
+	duration, err := h.reportService.GetLoanDuration(filter)
+
+	if err != nil {
+		respondReportError(c, "loan-duration", err)
+		return
+	}
+
+	respondReport(c, "loan-duration", duration,
+		[]string{"returned_loans", "average_days", "min_days", "max_days"},
+		[][]string{{strconv.Itoa(duration.ReturnedLoans), formatFloat(duration.AverageDays), formatFloat(duration.MinDays), formatFloat(duration.MaxDays)}})
+}
+
+func (h *ReportHandler) GetOverdueRate(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+
+	if !ok {
+		return
+	}
+
+	rate, err := h.reportService.GetOverdueRate(filter)
+
+	if err != nil {
+		respondReportError(c, "overdue-rate", err)
+		return
+	}
+
+	respondReport(c, "overdue-rate", rate,
+		[]string{"loans", "overdue", "rate"},
+		[][]string{{strconv.Itoa(rate.Loans), strconv.Itoa(rate.Overdue), formatFloat(rate.Rate)}})
+}
+
+func (h *ReportHandler) GetCollectionTurnover(c *gin.Context) {
+	filter, ok := parseReportFilter(c)
+
+	if !ok {
+		return
+	}
+
+	turnover, err := h.reportService.GetCollectionTurnover(filter)
+
+	if err != nil {
+		respondReportError(c, "turnover", err)
+		return
+	}
+
+	respondReport(c, "turnover", turnover,
+		[]string{"loans", "items", "turnover"},
+		[][]string{{strconv.Itoa(turnover.Loans), strconv.Itoa(turnover.Items), formatFloat(turnover.Turnover)}})
+}
+
+// GetWeedingCandidates lists the books not borrowed in the last years years,
+// or never borrowed when years is omitted.
+func (h *ReportHandler) GetWeedingCandidates(c *gin.Context) {
+	filter := model.WeedingFilter{Subject: c.Query("subject"), Limit: 100}
+
+	if yearsStr := c.Query("years"); yearsStr != "" {
+		years, err := strconv.Atoi(yearsStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid years parameter", "details": err.Error()})
+			return
+		}
+		filter.Years = &years
+	}
+
+	for param, target := range map[string]**uuid.UUID{"branch_id": &filter.BranchID, "subject_id": &filter.SubjectID} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " parameter", "details": err.Error()})
+			return
+		}
+		*target = &id
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, use a value from 1 to 1000"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offset parameter"})
+			return
+		}
+		filter.Offset = offset
+	}
+
+	candidates, err := h.reportService.GetWeedingCandidates(filter)
+
+	if err != nil {
+		respondReportError(c, "weeding", err)
+		return
+	}
+
+	rows := make([][]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		addedAt, ageDays, lastLoanAt := "", "", ""
+		if candidate.AddedAt != nil {
+			addedAt = candidate.AddedAt.Format(time.DateOnly)
+		}
+		if candidate.AgeDays != nil {
+			ageDays = strconv.Itoa(*candidate.AgeDays)
+		}
+		if candidate.LastLoanAt != nil {
+			lastLoanAt = candidate.LastLoanAt.Format(time.DateOnly)
+		}
+		rows = append(rows, []string{candidate.BookID.String(), candidate.Title, candidate.Author, candidate.Isbn, candidate.CallNumber,
+			candidate.BranchCode, addedAt, ageDays, lastLoanAt, strconv.Itoa(candidate.LifetimeLoans)})
+	}
+
+	respondReport(c, "weeding", candidates,
+		[]string{"book_id", "title", "author", "isbn", "call_number", "branch_code", "added_at", "age_days", "last_loan_at", "lifetime_loans"}, rows)
+}
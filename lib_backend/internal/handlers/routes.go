@@ -2,59 +2,314 @@ package handler
 
 import (
 	"database/sql"
+	"log"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
+	"lib_backend/internal/jobs"
+	"lib_backend/internal/metadata"
+	"lib_backend/internal/notifications"
+	"lib_backend/internal/openapi"
+	"lib_backend/internal/outbox"
 	"lib_backend/internal/repository"
+	"lib_backend/internal/scheduler"
 	"lib_backend/internal/services"
+	"lib_backend/internal/storage"
+	"lib_backend/internal/stream"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(r *gin.Engine, db *sql.DB) {
+// documentedPrefixes are the route groups covered by the OpenAPI document,
+// which must list exactly their routes.
+var documentedPrefixes = []string{"/api/users", "/api/books", "/api/loans"}
+
+func SetupRoutes(r *gin.Engine, db *sql.DB, sched *scheduler.Scheduler, dispatcher *outbox.Dispatcher, hub *stream.Hub, blobStore storage.BlobStore, spec *openapi.Spec) {
 
 	userRepo := repository.NewUserRepository(db)
 	bookRepo := repository.NewBookRepository(db)
 	loanRepo := repository.NewLoanRepository(db)
+	categoryRepo := repository.NewPatronCategoryRepository(db)
+	ruleRepo := repository.NewCirculationRuleRepository(db)
+	holdRepo := repository.NewHoldRepository(db)
+	fineRepo := repository.NewFineRepository(db)
+	branchRepo := repository.NewBranchRepository(db)
+	transferRepo := repository.NewTransferRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	transactor := repository.NewTransactor(db)
+	metadataCacheRepo := repository.NewMetadataCacheRepository(db)
+	contributorRepo := repository.NewContributorRepository(db)
+	subjectRepo := repository.NewSubjectRepository(db)
+	seriesRepo := repository.NewSeriesRepository(db)
+	recommendationRepo := repository.NewRecommendationRepository(db)
+	reportRepo := repository.NewReportRepository(db)
+
+	notificationConfig := config.LoadNotificationConfig()
+	var channel notifications.Channel = notifications.LogChannel{}
+	if notificationConfig.SMTPHost != "" {
+		channel = notifications.NewSMTPChannel(notificationConfig)
+	}
+	notifier := notifications.NewNotifier(notifications.NewTemplates(notificationConfig.TemplateDir), userRepo, notificationRepo, channel)
+
+	webhookService := services.NewWebhookService(webhookRepo)
+	dispatcher.AddSink(events.NewBus())
+	dispatcher.AddSink(events.NewSink("webhooks", webhookService.Enqueue))
 
-	userService := services.NewUserService(userRepo)
-	bookService := services.NewBookService(bookRepo)
-	loanService := services.NewLoanService(loanRepo, userRepo, bookRepo)
+	metadataConfig := config.LoadMetadataConfig()
+	metadataService := services.NewMetadataService(metadataCacheRepo, metadata.Chain(metadata.NewProviders(metadataConfig)), metadataConfig)
+
+	coverConfig := config.LoadCoverConfig()
+	coverService := services.NewCoverService(bookRepo, blobStore, transactor, outboxRepo, coverConfig)
+
+	userService := services.NewUserService(userRepo, categoryRepo, config.LoadRegistrationConfig(), notifier, transactor, outboxRepo)
+	bookService := services.NewBookService(bookRepo, branchRepo, contributorRepo, subjectRepo, seriesRepo, transactor, outboxRepo, metadataService, coverService, config.LoadItemBarcodeConfig())
+	loanService := services.NewLoanService(loanRepo, userRepo, bookRepo, categoryRepo, ruleRepo, holdRepo, fineRepo, branchRepo, transferRepo, notifier, transactor, outboxRepo)
+	contributorService := services.NewContributorService(contributorRepo)
+	subjectService := services.NewSubjectService(subjectRepo)
+	seriesService := services.NewSeriesService(seriesRepo, bookRepo, contributorRepo, transactor, outboxRepo)
+	recommendationService := services.NewRecommendationService(recommendationRepo, bookRepo, userRepo, contributorRepo, transactor, config.LoadRecommendationConfig())
+	reportService := services.NewReportService(reportRepo)
+	privacyService := services.NewPrivacyService(userRepo, loanRepo, holdRepo, fineRepo, notificationRepo, webhookRepo, transactor, outboxRepo, config.LoadPrivacyConfig())
+	idempotencyService := services.NewIdempotencyService(repository.NewIdempotencyRepository(db), config.LoadIdempotencyConfig())
+	withdrawalService := services.NewWithdrawalService(bookRepo, loanRepo, holdRepo, transferRepo, branchRepo, notifier, transactor, outboxRepo)
+	categoryService := services.NewPatronCategoryService(categoryRepo)
+	ruleService := services.NewCirculationRuleService(ruleRepo)
+	holdService := services.NewHoldService(holdRepo, userRepo, bookRepo, loanRepo, categoryRepo, ruleRepo, branchRepo, transferRepo, notifier, transactor, outboxRepo)
+	fineService := services.NewFineService(fineRepo)
+	branchService := services.NewBranchService(branchRepo)
+	transferService := services.NewTransferService(transferRepo, bookRepo, holdRepo, branchRepo, notifier, transactor, outboxRepo)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, loanRepo, bookRepo, notifier, notificationConfig.DueSoonDays)
+	circulationService := services.NewCirculationService(loanService, transferService, loanRepo, userRepo, bookRepo, holdRepo, branchRepo, transferRepo)
+
+	jobs.Register(sched, dispatcher, config.LoadSchedulerConfig(), loanService, notificationService, holdService, webhookService, recommendationService, privacyService, idempotencyService)
 
 	userHandler := NewUserHandler(userService)
-	bookHandler := NewBookHandler(bookService)
+	bookHandler := NewBookHandler(bookService, metadataService)
+	coverHandler := NewCoverHandler(coverService, coverConfig)
+	contributorHandler := NewContributorHandler(contributorService)
+	subjectHandler := NewSubjectHandler(subjectService)
+	seriesHandler := NewSeriesHandler(seriesService)
+	recommendationHandler := NewRecommendationHandler(recommendationService)
+	reportHandler := NewReportHandler(reportService)
+	withdrawalHandler := NewWithdrawalHandler(withdrawalService)
+	privacyHandler := NewPrivacyHandler(privacyService)
 	loanHandler := NewLoanHandler(loanService)
+	categoryHandler := NewPatronCategoryHandler(categoryService)
+	ruleHandler := NewCirculationRuleHandler(ruleService)
+	holdHandler := NewHoldHandler(holdService)
+	fineHandler := NewFineHandler(fineService)
+	circulationHandler := NewCirculationHandler(circulationService)
+	branchHandler := NewBranchHandler(branchService)
+	transferHandler := NewTransferHandler(transferService)
+	notificationHandler := NewNotificationHandler(notificationService)
+	jobHandler := NewJobHandler(sched)
+	webhookHandler := NewWebhookHandler(webhookService)
+	streamHandler := NewStreamHandler(hub, config.LoadStreamConfig())
+
+	rateLimitConfig := config.LoadRateLimitConfig()
+	rateLimiter := NewRateLimiter(newRateLimitStore(db, rateLimitConfig), rateLimitConfig)
 
-	api := r.Group("/api")
+	// idempotency runs after the limiter, so a limited client cannot reach
+	// the idempotency store at all, and shares its client identity
+	idempotency := Idempotency(idempotencyService, rateLimiter.identity)
+
+	api := r.Group("/api", OpenAPIValidator(spec, config.LoadOpenAPIConfig()))
 	{
-		users := api.Group("/users")
+		api.GET("openapi.json", GetOpenAPIDocument) // GET /api/openapi.json
+		api.GET("docs", GetDocsPage)                // GET /api/docs (documentação navegável)
+
+		users := api.Group("/users", rateLimiter.For("users"), idempotency)
 		{
-			users.POST("", userHandler.CreateUser)            // POST /api/users
-			users.GET("by-email", userHandler.GetUserByEmail) // GET /api/users/by-email?email=
-			users.GET("", userHandler.GetAllUsers)            // GET /api/users
-			users.GET(":id", userHandler.GetUserByID)         // GET /api/users/:id
-			users.PUT(":id", userHandler.UpdateUser)          // PUT /api/users/:id
-			users.DELETE(":id", userHandler.DeleteUser)       // DELETE /api/users/:id
+			users.POST("", userHandler.CreateUser)                                       // POST /api/users
+			users.GET("by-email", userHandler.GetUserByEmail)                            // GET /api/users/by-email?email=
+			users.GET("by-registration", userHandler.GetUserByRegistration)              // GET /api/users/by-registration?registration=
+			users.GET("", userHandler.GetAllUsers)                                       // GET /api/users
+			users.GET(":id", userHandler.GetUserByID)                                    // GET /api/users/:id
+			users.GET(":id/barcode", userHandler.GetUserBarcode)                         // GET /api/users/:id/barcode?format=png|svg
+			users.GET(":id/notifications", notificationHandler.GetNotificationsByUserID) // GET /api/users/:id/notifications
+			users.GET(":id/recommendations", recommendationHandler.GetRecommendations)   // GET /api/users/:id/recommendations?limit=
+			users.PUT(":id", userHandler.UpdateUser)                                     // PUT /api/users/:id
+			users.DELETE(":id", userHandler.DeleteUser)                                  // DELETE /api/users/:id
+
+			users.GET(":id/data-export", privacyHandler.ExportUserData) // GET /api/users/:id/data-export?format=zip|json
+			users.POST(":id/erase", privacyHandler.EraseUser)           // POST /api/users/:id/erase
 		}
 
-		books := api.Group("/books")
+		books := api.Group("/books", rateLimiter.For("books"), idempotency)
 		{
-			books.POST("", bookHandler.CreateBook)          // POST /api/books
-			books.GET("by-isbn", bookHandler.GetBookByISBN) // GET /api/books/by-isbn?isbn=
-			books.GET("", bookHandler.GetAllBooks)          // GET /api/books (deve vir após as rotas mais específicas)
-			books.GET(":id", bookHandler.GetBookByID)       // GET /api/books/:id
-			books.PUT(":id", bookHandler.UpdateBook)        // PUT /api/books/:id
-			books.DELETE(":id", bookHandler.DeleteBook)     // DELETE /api/books/:id
+			books.POST("", bookHandler.CreateBook)                // POST /api/books?enrich=true
+			books.GET("lookup", bookHandler.LookupISBN)           // GET /api/books/lookup?isbn=
+			books.GET("by-isbn", bookHandler.GetBookByISBN)       // GET /api/books/by-isbn?isbn=
+			books.GET("by-barcode", bookHandler.GetBookByBarcode) // GET /api/books/by-barcode?barcode=
+			books.GET("search", bookHandler.SearchCatalog)        // GET /api/books/search?<filtros de /api/books>&limit=&offset=&facet_limit= (com facetas)
+			books.GET("", bookHandler.GetAllBooks)                // GET /api/books?q=&branch=&home_branch=&available=&item_type=&author=&author_id=&publisher=&year_from=&year_to=&language=&subject=&subject_id=&tag=&series_id=&call_number=... (deve vir após as rotas mais específicas)
+			books.GET(":id", bookHandler.GetBookByID)             // GET /api/books/:id
+			books.PUT(":id", bookHandler.UpdateBook)              // PUT /api/books/:id
+			books.DELETE(":id", bookHandler.DeleteBook)           // DELETE /api/books/:id
+
+			books.POST(":id/cover", coverHandler.UploadCover)   // POST /api/books/:id/cover (multipart, campo cover)
+			books.GET(":id/cover", coverHandler.GetCover)       // GET /api/books/:id/cover?size=small|medium|large
+			books.DELETE(":id/cover", coverHandler.DeleteCover) // DELETE /api/books/:id/cover
+
+			books.GET(":id/related", recommendationHandler.GetRelatedBooks) // GET /api/books/:id/related?limit=
+
+			books.POST("withdraw", withdrawalHandler.WithdrawBooks)   // POST /api/books/withdraw {bookIds, reason}
+			books.POST("reinstate", withdrawalHandler.ReinstateBooks) // POST /api/books/reinstate {bookIds}
 		}
 
-		loans := api.Group("/loans")
+		authors := api.Group("/authors", rateLimiter.For("authors"), idempotency)
 		{
-			loans.POST("", loanHandler.CreateLoan)          // POST /api/loans
-			loans.GET(":id", loanHandler.GetLoanByID)       // GET /api/loans/:id
-			loans.PUT(":id/return", loanHandler.ReturnBook) // PUT /api/loans/:id/return
-			loans.GET("", loanHandler.GetAllLoans)          // GET /api/loans
+			authors.GET("", contributorHandler.SearchContributors)    // GET /api/authors?q=&limit=
+			authors.GET(":id", contributorHandler.GetContributorByID) // GET /api/authors/:id
+		}
+
+		subjects := api.Group("/subjects", rateLimiter.For("subjects"), idempotency)
+		{
+			subjects.POST("", subjectHandler.CreateSubject)      // POST /api/subjects
+			subjects.GET("", subjectHandler.SearchSubjects)      // GET /api/subjects?q=&parent_id=&limit=
+			subjects.GET(":id", subjectHandler.GetSubjectByID)   // GET /api/subjects/:id
+			subjects.PUT(":id", subjectHandler.UpdateSubject)    // PUT /api/subjects/:id
+			subjects.DELETE(":id", subjectHandler.DeleteSubject) // DELETE /api/subjects/:id
+		}
+
+		// tags come from the subject handler and share its limit
+		tags := api.Group("/tags", rateLimiter.For("subjects"), idempotency)
+		{
+			tags.GET("", subjectHandler.GetTags) // GET /api/tags?q=&limit=
+		}
+
+		seriesRoutes := api.Group("/series", rateLimiter.For("series"), idempotency)
+		{
+			seriesRoutes.POST("", seriesHandler.CreateSeries)                       // POST /api/series
+			seriesRoutes.GET("", seriesHandler.SearchSeries)                        // GET /api/series?q=&limit=
+			seriesRoutes.GET(":id", seriesHandler.GetSeriesByID)                    // GET /api/series/:id (volumes com disponibilidade)
+			seriesRoutes.PUT(":id", seriesHandler.UpdateSeries)                     // PUT /api/series/:id
+			seriesRoutes.DELETE(":id", seriesHandler.DeleteSeries)                  // DELETE /api/series/:id
+			seriesRoutes.PUT(":id/volumes/:book_id", seriesHandler.SetVolume)       // PUT /api/series/:id/volumes/:book_id
+			seriesRoutes.DELETE(":id/volumes/:book_id", seriesHandler.RemoveVolume) // DELETE /api/series/:id/volumes/:book_id
+		}
+
+		loans := api.Group("/loans", rateLimiter.For("loans"), idempotency)
+		{
+			loans.POST("", loanHandler.CreateLoan)                 // POST /api/loans
+			loans.GET(":id", loanHandler.GetLoanByID)              // GET /api/loans/:id
+			loans.PUT(":id/return", loanHandler.ReturnBook)        // PUT /api/loans/:id/return
+			loans.PUT(":id/renew", loanHandler.RenewLoan)          // PUT /api/loans/:id/renew
+			loans.GET(":id/policy", loanHandler.ExplainLoanPolicy) // GET /api/loans/:id/policy
+			loans.GET("", loanHandler.GetAllLoans)                 // GET /api/loans
 
 			loans.GET("by-user/:user_id", loanHandler.GetLoansByUserID) // GET /api/loans/by-user/:user_id
 			loans.GET("by-book/:book_id", loanHandler.GetLoansByBookID) // GET /api/loans/by-book/:book_id
 
 			loans.DELETE(":id", loanHandler.DeleteLoan)
 		}
+
+		categories := api.Group("/patron-categories", rateLimiter.For("patron-categories"), idempotency)
+		{
+			categories.GET("", categoryHandler.GetAllCategories)       // GET /api/patron-categories
+			categories.GET(":code", categoryHandler.GetCategoryByCode) // GET /api/patron-categories/:code
+			categories.PUT(":code", categoryHandler.UpdateCategory)    // PUT /api/patron-categories/:code
+		}
+
+		rules := api.Group("/circulation-rules", rateLimiter.For("circulation-rules"), idempotency)
+		{
+			rules.POST("", ruleHandler.CreateRule)      // POST /api/circulation-rules
+			rules.GET("", ruleHandler.GetAllRules)      // GET /api/circulation-rules
+			rules.GET(":id", ruleHandler.GetRuleByID)   // GET /api/circulation-rules/:id
+			rules.PUT(":id", ruleHandler.UpdateRule)    // PUT /api/circulation-rules/:id
+			rules.DELETE(":id", ruleHandler.DeleteRule) // DELETE /api/circulation-rules/:id
+		}
+
+		holds := api.Group("/holds", rateLimiter.For("holds"), idempotency)
+		{
+			holds.POST("", holdHandler.PlaceHold)                       // POST /api/holds
+			holds.GET(":id", holdHandler.GetHoldByID)                   // GET /api/holds/:id
+			holds.PUT(":id/cancel", holdHandler.CancelHold)             // PUT /api/holds/:id/cancel
+			holds.GET("by-user/:user_id", holdHandler.GetHoldsByUserID) // GET /api/holds/by-user/:user_id
+			holds.GET("by-book/:book_id", holdHandler.GetHoldsByBookID) // GET /api/holds/by-book/:book_id
+		}
+
+		fines := api.Group("/fines", rateLimiter.For("fines"), idempotency)
+		{
+			fines.GET("by-user/:user_id", fineHandler.GetFinesByUserID) // GET /api/fines/by-user/:user_id
+			fines.PUT(":id/pay", fineHandler.PayFine)                   // PUT /api/fines/:id/pay
+		}
+
+		notificationRoutes := api.Group("/notifications", rateLimiter.For("notifications"), idempotency)
+		{
+			notificationRoutes.POST("due-soon", notificationHandler.SendDueSoonReminders) // POST /api/notifications/due-soon
+			notificationRoutes.POST("overdue", notificationHandler.SendOverdueNotices)    // POST /api/notifications/overdue
+		}
+
+		circulation := api.Group("/circulation", rateLimiter.For("circulation"), idempotency)
+		{
+			circulation.POST("checkout", circulationHandler.Checkout) // POST /api/circulation/checkout
+			circulation.POST("checkin", circulationHandler.Checkin)   // POST /api/circulation/checkin
+		}
+
+		branches := api.Group("/branches", rateLimiter.For("branches"), idempotency)
+		{
+			branches.POST("", branchHandler.CreateBranch)      // POST /api/branches
+			branches.GET("", branchHandler.GetAllBranches)     // GET /api/branches
+			branches.GET(":id", branchHandler.GetBranchByID)   // GET /api/branches/:id
+			branches.PUT(":id", branchHandler.UpdateBranch)    // PUT /api/branches/:id
+			branches.DELETE(":id", branchHandler.DeleteBranch) // DELETE /api/branches/:id
+
+			branches.GET(":id/pull-list", transferHandler.GetPullList) // GET /api/branches/:id/pull-list
+		}
+
+		transfers := api.Group("/transfers", rateLimiter.For("transfers"), idempotency)
+		{
+			transfers.POST("", transferHandler.RequestTransfer)           // POST /api/transfers
+			transfers.GET("", transferHandler.SearchTransfers)            // GET /api/transfers?from_branch=&to_branch=&status=
+			transfers.GET(":id", transferHandler.GetTransferByID)         // GET /api/transfers/:id
+			transfers.PUT(":id/ship", transferHandler.ShipTransfer)       // PUT /api/transfers/:id/ship
+			transfers.PUT(":id/receive", transferHandler.ReceiveTransfer) // PUT /api/transfers/:id/receive
+		}
+
+		webhooks := api.Group("/webhooks", rateLimiter.For("webhooks"), idempotency)
+		{
+			webhooks.POST("", webhookHandler.CreateSubscription)         // POST /api/webhooks
+			webhooks.GET("", webhookHandler.GetAllSubscriptions)         // GET /api/webhooks
+			webhooks.GET(":id", webhookHandler.GetSubscriptionByID)      // GET /api/webhooks/:id
+			webhooks.PUT(":id", webhookHandler.UpdateSubscription)       // PUT /api/webhooks/:id
+			webhooks.DELETE(":id", webhookHandler.DeleteSubscription)    // DELETE /api/webhooks/:id
+			webhooks.GET(":id/deliveries", webhookHandler.GetDeliveries) // GET /api/webhooks/:id/deliveries?limit=
+		}
+
+		deliveries := api.Group("/webhook-deliveries", rateLimiter.For("webhooks"), idempotency)
+		{
+			deliveries.POST(":id/replay", webhookHandler.ReplayDelivery) // POST /api/webhook-deliveries/:id/replay
+		}
+
+		eventRoutes := api.Group("/events", rateLimiter.For("events"), idempotency)
+		{
+			eventRoutes.GET("stream", streamHandler.Stream) // GET /api/events/stream?types=&book=&user= (SSE)
+			eventRoutes.GET("ws", streamHandler.WebSocket)  // GET /api/events/ws?types=&book=&user=&last_event_id=
+		}
+
+		reports := api.Group("/reports", rateLimiter.For("reports"), idempotency)
+		{
+			reports.GET("loans", reportHandler.GetLoanVolume)             // GET /api/reports/loans?interval=day|week|month&from=&to=&branch_id=&category=&format=csv
+			reports.GET("top-books", reportHandler.GetTopBooks)           // GET /api/reports/top-books?limit=&from=&to=&branch_id=&category=
+			reports.GET("active-patrons", reportHandler.GetActivePatrons) // GET /api/reports/active-patrons
+			reports.GET("loan-duration", reportHandler.GetLoanDuration)   // GET /api/reports/loan-duration
+			reports.GET("overdue-rate", reportHandler.GetOverdueRate)     // GET /api/reports/overdue-rate
+			reports.GET("turnover", reportHandler.GetCollectionTurnover)  // GET /api/reports/turnover
+			reports.GET("weeding", reportHandler.GetWeedingCandidates)    // GET /api/reports/weeding?years=&subject_id=&subject=&branch_id=&limit=&offset=
+		}
+
+		admin := api.Group("/admin", rateLimiter.For("admin"), idempotency)
+		{
+			admin.GET("jobs", jobHandler.GetJobs)               // GET /api/admin/jobs
+			admin.GET("jobs/:name/runs", jobHandler.GetJobRuns) // GET /api/admin/jobs/:name/runs?limit=
+			admin.POST("jobs/:name/run", jobHandler.TriggerJob) // POST /api/admin/jobs/:name/run
+		}
+	}
+
+	for _, problem := range spec.CheckRoutes(r.Routes(), documentedPrefixes...) {
+		log.Printf("WARNING: openapi: %s", problem)
 	}
 }
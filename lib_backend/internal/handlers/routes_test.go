@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/openapi"
+	"lib_backend/internal/outbox"
+	"lib_backend/internal/repository"
+	"lib_backend/internal/scheduler"
+	"lib_backend/internal/storage"
+	"lib_backend/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
+)
+
+// newTestRouter builds the real router on a database handle that is never
+// connected.
+func newTestRouter(t *testing.T) (*gin.Engine, *openapi.Spec) {
+	t.Helper()
+
+	// sql.Open does not connect, and building the routes runs no query
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable")
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	blobStore, err := storage.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocalStore() error = %v", err)
+	}
+
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("openapi.Load() error = %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	sched := scheduler.New(db, repository.NewJobRunRepository(db))
+	dispatcher := outbox.NewDispatcher(repository.NewTransactor(db), repository.NewOutboxRepository(db), config.LoadOutboxConfig())
+	hub := stream.NewHub(repository.NewOutboxRepository(db), config.LoadStreamConfig())
+
+	SetupRoutes(r, db, sched, dispatcher, hub, blobStore, spec)
+	return r, spec
+}
+
+func TestRoutesMatchOpenAPIDocument(t *testing.T) {
+	r, spec := newTestRouter(t)
+
+	for _, problem := range spec.CheckRoutes(r.Routes(), documentedPrefixes...) {
+		t.Error(problem)
+	}
+}
+
+func TestDocsPageLoadsNoThirdPartyAssets(t *testing.T) {
+	r, _ := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Security-Policy") != docsPolicy {
+		t.Fatalf("GET /api/docs = %d with policy %q, want 200 with %q", w.Code, w.Header().Get("Content-Security-Policy"), docsPolicy)
+	}
+
+	if body := w.Body.String(); strings.Contains(body, "https://") || strings.Contains(body, "http://") || !strings.Contains(body, "/api/openapi.json") {
+		t.Fatal("docs page must only load /api/openapi.json")
+	}
+}
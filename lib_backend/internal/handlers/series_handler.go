@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SeriesHandler struct {
+	seriesService services.SeriesService
+}
+
+func NewSeriesHandler(s services.SeriesService) *SeriesHandler {
+	return &SeriesHandler{seriesService: s}
+}
+
+type setVolumeRequest struct {
+	VolumeNumber *int `json:"volume_number"`
+}
+
+func (h *SeriesHandler) CreateSeries(c *gin.Context) {
+	var series model.Series
+
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	createdSeries, err := h.seriesService.CreateSeries(&series)
+
+	if err != nil {
+		log.Printf("ERROR: CreateSeries service failed: %v", err)
+
+		if err.Error() == "series title is required" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Series title is required"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create series", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdSeries)
+}
+
+func (h *SeriesHandler) SearchSeries(c *gin.Context) {
+	limit := 50
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	seriesList, err := h.seriesService.SearchSeries(c.Query("q"), limit)
+
+	if err != nil {
+		log.Printf("ERROR: SearchSeries service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve series", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, seriesList)
+}
+
+func (h *SeriesHandler) GetSeriesByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID format", "details": err.Error()})
+		return
+	}
+
+	series, err := h.seriesService.GetSeriesByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetSeriesByID service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "series with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve series", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *SeriesHandler) UpdateSeries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID format", "details": err.Error()})
+		return
+	}
+
+	var series model.Series
+
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	series.ID = id
+
+	updatedSeries, err := h.seriesService.UpdateSeries(&series)
+
+	if err != nil {
+		log.Printf("ERROR: UpdateSeries service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "series with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update series", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedSeries)
+}
+
+func (h *SeriesHandler) DeleteSeries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID format", "details": err.Error()})
+		return
+	}
+
+	err = h.seriesService.DeleteSeries(id)
+
+	if err != nil {
+		log.Printf("ERROR: DeleteSeries service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "series with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete series", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SeriesHandler) SetVolume(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID format", "details": err.Error()})
+		return
+	}
+
+	bookID, err := uuid.Parse(c.Param("book_id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID format", "details": err.Error()})
+		return
+	}
+
+	var req setVolumeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	series, err := h.seriesService.SetVolume(id, bookID, req.VolumeNumber)
+
+	if err != nil {
+		log.Printf("ERROR: SetVolume service failed for series %s and book %s: %v", id.String(), bookID.String(), err)
+
+		switch {
+		case err.Error() == "series with ID "+id.String()+" not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		case err.Error() == "book with ID "+bookID.String()+" not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		case strings.HasPrefix(err.Error(), "volume number ") && strings.HasSuffix(err.Error(), " is not valid"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid volume number"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set volume", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+func (h *SeriesHandler) RemoveVolume(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID format", "details": err.Error()})
+		return
+	}
+
+	bookID, err := uuid.Parse(c.Param("book_id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid book ID format", "details": err.Error()})
+		return
+	}
+
+	err = h.seriesService.RemoveVolume(id, bookID)
+
+	if err != nil {
+		log.Printf("ERROR: RemoveVolume service failed for series %s and book %s: %v", id.String(), bookID.String(), err)
+
+		if err.Error() == "book with ID "+bookID.String()+" is not in series "+id.String() {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book is not in this series"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove volume", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
+	"lib_backend/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// streamWriteTimeout bounds each write to a WebSocket client.
+const streamWriteTimeout = 10 * time.Second
+
+type StreamHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+func NewStreamHandler(hub *stream.Hub, cfg config.StreamConfig) *StreamHandler {
+	return &StreamHandler{
+		hub:       hub,
+		heartbeat: cfg.HeartbeatInterval,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || slices.Contains(cfg.AllowedOrigins, origin)
+			},
+		},
+	}
+}
+
+// parseStreamRequest reads the filter and resume point shared by both
+// endpoints. Browsers cannot set headers on WebSocket requests, so the resume
+// point is also accepted as the last_event_id query parameter.
+func parseStreamRequest(c *gin.Context) (stream.Filter, int64, error) {
+	filter := stream.Filter{Types: stream.DefaultTypes}
+
+	if types := c.Query("types"); types != "" {
+		filter.Types = strings.Split(types, ",")
+		for _, eventType := range filter.Types {
+			if !events.IsKnownType(eventType) {
+				return filter, 0, fmt.Errorf("event type %s is not supported", eventType)
+			}
+		}
+	}
+
+	if book := c.Query("book"); book != "" {
+		bookID, err := uuid.Parse(book)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid book ID: %w", err)
+		}
+		filter.BookID = &bookID
+	}
+
+	if user := c.Query("user"); user != "" {
+		userID, err := uuid.Parse(user)
+		if err != nil {
+			return filter, 0, fmt.Errorf("invalid user ID: %w", err)
+		}
+		filter.UserID = &userID
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+
+	if lastEventID == "" {
+		return filter, -1, nil
+	}
+
+	seq, err := strconv.ParseInt(lastEventID, 10, 64)
+	if err != nil || seq < 0 {
+		return filter, 0, fmt.Errorf("invalid last event ID %s", lastEventID)
+	}
+
+	return filter, seq, nil
+}
+
+func (h *StreamHandler) Stream(c *gin.Context) {
+	filter, lastSeq, err := parseStreamRequest(c)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stream request", "details": err.Error()})
+		return
+	}
+
+	client := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(client)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	send := func(event events.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data); err != nil {
+			return err
+		}
+		w.Flush()
+		return nil
+	}
+
+	if _, err := fmt.Fprint(w, "retry: 3000\n\n"); err != nil {
+		return
+	}
+	w.Flush()
+
+	if lastSeq >= 0 {
+		if err := h.hub.Replay(client, lastSeq, send); err != nil {
+			log.Printf("ERROR: failed to replay events after %d: %v", lastSeq, err)
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		case event, ok := <-client.Events:
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *StreamHandler) WebSocket(c *gin.Context) {
+	filter, lastSeq, err := parseStreamRequest(c)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stream request", "details": err.Error()})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+
+	if err != nil {
+		// the upgrader has already written the error response
+		log.Printf("ERROR: failed to upgrade event stream to WebSocket: %v", err)
+		return
+	}
+	defer func() {
+		if closeErr := conn.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close event stream WebSocket: %v", closeErr)
+		}
+	}()
+
+	client := h.hub.Subscribe(filter)
+	defer h.hub.Unsubscribe(client)
+
+	// the client only ever sends control frames; reading processes them and
+	// notices when the connection goes away
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(event events.Event) error {
+		if err := conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout)); err != nil {
+			return err
+		}
+		return conn.WriteJSON(event)
+	}
+
+	if lastSeq >= 0 {
+		if err := h.hub.Replay(client, lastSeq, send); err != nil {
+			log.Printf("ERROR: failed to replay events after %d: %v", lastSeq, err)
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(streamWriteTimeout)); err != nil {
+				return
+			}
+		case event, ok := <-client.Events:
+			if !ok {
+				return
+			}
+			if err := send(event); err != nil {
+				return
+			}
+		}
+	}
+}
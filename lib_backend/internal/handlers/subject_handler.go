@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SubjectHandler struct {
+	subjectService services.SubjectService
+}
+
+func NewSubjectHandler(s services.SubjectService) *SubjectHandler {
+	return &SubjectHandler{subjectService: s}
+}
+
+func isInvalidSubjectParent(err error) bool {
+	return strings.HasPrefix(err.Error(), "parent subject with ID ") && strings.HasSuffix(err.Error(), " not found") ||
+		err.Error() == "subject cannot be placed under itself or a narrower subject"
+}
+
+func (h *SubjectHandler) CreateSubject(c *gin.Context) {
+	var subject model.Subject
+
+	if err := c.ShouldBindJSON(&subject); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	createdSubject, err := h.subjectService.CreateSubject(&subject)
+
+	if err != nil {
+		log.Printf("ERROR: CreateSubject service failed: %v", err)
+
+		switch {
+		case err.Error() == "subject heading is required":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Subject heading is required"})
+		case err.Error() == "subject "+subject.Heading+" already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": "Subject with this heading already exists"})
+		case isInvalidSubjectParent(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent subject", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create subject", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdSubject)
+}
+
+func (h *SubjectHandler) SearchSubjects(c *gin.Context) {
+	filter := model.SubjectFilter{Query: c.Query("q"), Limit: 100}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	if parentIDStr := c.Query("parent_id"); parentIDStr != "" {
+		parentID, err := uuid.Parse(parentIDStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent_id parameter", "details": err.Error()})
+			return
+		}
+		filter.ParentID = &parentID
+	}
+
+	subjects, err := h.subjectService.SearchSubjects(filter)
+
+	if err != nil {
+		log.Printf("ERROR: SearchSubjects service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve subjects", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subjects)
+}
+
+func (h *SubjectHandler) GetSubjectByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject ID format", "details": err.Error()})
+		return
+	}
+
+	subject, err := h.subjectService.GetSubjectByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetSubjectByID service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "subject with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subject not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve subject", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subject)
+}
+
+func (h *SubjectHandler) UpdateSubject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject ID format", "details": err.Error()})
+		return
+	}
+
+	var subject model.Subject
+
+	if err := c.ShouldBindJSON(&subject); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	subject.ID = id
+
+	updatedSubject, err := h.subjectService.UpdateSubject(&subject)
+
+	if err != nil {
+		log.Printf("ERROR: UpdateSubject service failed for ID %s: %v", id.String(), err)
+
+		switch {
+		case err.Error() == "subject with ID "+id.String()+" not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subject not found"})
+		case err.Error() == "subject "+subject.Heading+" already exists":
+			c.JSON(http.StatusConflict, gin.H{"error": "Subject with this heading already exists"})
+		case isInvalidSubjectParent(err):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent subject", "details": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update subject", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedSubject)
+}
+
+func (h *SubjectHandler) DeleteSubject(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject ID format", "details": err.Error()})
+		return
+	}
+
+	err = h.subjectService.DeleteSubject(id)
+
+	if err != nil {
+		log.Printf("ERROR: DeleteSubject service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "subject with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Subject not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete subject", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *SubjectHandler) GetTags(c *gin.Context) {
+	limit := 100
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	tags, err := h.subjectService.GetTags(c.Query("q"), limit)
+
+	if err != nil {
+		log.Printf("ERROR: GetTags service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve tags", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
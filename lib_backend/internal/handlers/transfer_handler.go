@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/dto"
+	"lib_backend/internal/model"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TransferHandler struct {
+	transferService services.TransferService
+}
+
+func NewTransferHandler(s services.TransferService) *TransferHandler {
+	return &TransferHandler{transferService: s}
+}
+
+func (h *TransferHandler) RequestTransfer(c *gin.Context) {
+	var request dto.TransferRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	bookID := uuid.MustParse(request.BookID)
+	toBranchID := uuid.MustParse(request.ToBranchID)
+
+	transfer, err := h.transferService.RequestTransfer(bookID, toBranchID)
+
+	if err != nil {
+		log.Printf("ERROR: RequestTransfer service failed for book %s: %v", bookID.String(), err)
+
+		switch err.Error() {
+		case "book with ID " + bookID.String() + " not found for transfer":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Book not found"})
+		case "branch with ID " + toBranchID.String() + " not found for transfer":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown branch"})
+		case "book with ID " + bookID.String() + " is not available for transfer":
+			c.JSON(http.StatusConflict, gin.H{"error": "Book is not available for transfer"})
+		case "book with ID " + bookID.String() + " has no current branch":
+			c.JSON(http.StatusConflict, gin.H{"error": "Book has no current branch"})
+		case "book with ID " + bookID.String() + " is already at branch " + toBranchID.String():
+			c.JSON(http.StatusConflict, gin.H{"error": "Book is already at this branch"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to request transfer", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+func (h *TransferHandler) GetTransferByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID format", "details": err.Error()})
+		return
+	}
+
+	transfer, err := h.transferService.GetTransferByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetTransferByID service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "transfer with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transfer", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) ShipTransfer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID format", "details": err.Error()})
+		return
+	}
+
+	transfer, err := h.transferService.ShipTransfer(id)
+
+	if err != nil {
+		log.Printf("ERROR: ShipTransfer service failed for ID %s: %v", id.String(), err)
+
+		switch err.Error() {
+		case "transfer with ID " + id.String() + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		case "transfer with ID " + id.String() + " cannot be shipped from status " + model.TransferStatusInTransit,
+			"transfer with ID " + id.String() + " cannot be shipped from status " + model.TransferStatusReceived:
+			c.JSON(http.StatusConflict, gin.H{"error": "Transfer has already been shipped"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to ship transfer", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) ReceiveTransfer(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID format", "details": err.Error()})
+		return
+	}
+
+	transfer, err := h.transferService.ReceiveTransfer(id)
+
+	if err != nil {
+		log.Printf("ERROR: ReceiveTransfer service failed for ID %s: %v", id.String(), err)
+
+		switch err.Error() {
+		case "transfer with ID " + id.String() + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transfer not found"})
+		case "transfer with ID " + id.String() + " has already been received":
+			c.JSON(http.StatusConflict, gin.H{"error": "Transfer has already been received"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to receive transfer", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+func (h *TransferHandler) SearchTransfers(c *gin.Context) {
+	filter := model.TransferFilter{Status: c.Query("status")}
+
+	if fromBranch := c.Query("from_branch"); fromBranch != "" {
+		fromBranchID, err := uuid.Parse(fromBranch)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from_branch ID format", "details": err.Error()})
+			return
+		}
+		filter.FromBranchID = &fromBranchID
+	}
+
+	if toBranch := c.Query("to_branch"); toBranch != "" {
+		toBranchID, err := uuid.Parse(toBranch)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to_branch ID format", "details": err.Error()})
+			return
+		}
+		filter.ToBranchID = &toBranchID
+	}
+
+	transfers, err := h.transferService.SearchTransfers(filter)
+
+	if err != nil {
+		log.Printf("ERROR: SearchTransfers service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transfers", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+func (h *TransferHandler) GetPullList(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID format", "details": err.Error()})
+		return
+	}
+
+	transfers, err := h.transferService.GetPullList(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetPullList service failed for branch %s: %v", id.String(), err)
+
+		if err.Error() == "branch with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve pull list", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"bytes"
 	"log"
 	"net/http"
 
+	"lib_backend/internal/barcode"
 	"lib_backend/internal/model"
 	"lib_backend/internal/services"
 
@@ -37,6 +39,21 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 			return
 		}
 
+		if err.Error() == "patron category "+user.Category+" does not exist" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown patron category"})
+			return
+		}
+
+		if err.Error() == "language "+user.Language+" is not supported" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language"})
+			return
+		}
+
+		if err.Error() == "user with registration "+user.Registration+" already exists" {
+			c.JSON(http.StatusConflict, gin.H{"error": "User with this registration already exists"})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user", "details": err.Error()})
 		return
 	}
@@ -95,6 +112,81 @@ func (h *UserHandler) GetUserByEmail(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+func (h *UserHandler) GetUserByRegistration(c *gin.Context) {
+	registration := c.Query("registration")
+
+	if registration == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Registration parameter is required"})
+		return
+	}
+
+	user, err := h.userService.GetUserByRegistration(registration)
+
+	if err != nil {
+		log.Printf("ERROR: GetUserByRegistration service failed for registration %s: %v", registration, err)
+
+		if err.Error() == "user with registration "+registration+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// GetUserBarcode renders the library card barcode (Code128 of the registration) as PNG or SVG.
+func (h *UserHandler) GetUserBarcode(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format", "details": err.Error()})
+		return
+	}
+
+	format := c.DefaultQuery("format", "png")
+
+	if format != "png" && format != "svg" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be png or svg"})
+		return
+	}
+
+	user, err := h.userService.GetUserByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetUserBarcode service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "user with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user", "details": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	contentType := "image/png"
+
+	if format == "svg" {
+		contentType = "image/svg+xml"
+		err = barcode.WriteSVG(&buf, user.Registration, 2, 80)
+	} else {
+		err = barcode.WritePNG(&buf, user.Registration, 2, 80)
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to render barcode for user %s: %v", id.String(), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render barcode", "details": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -122,6 +214,21 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 			return
 		}
 
+		if err.Error() == "patron category "+user.Category+" does not exist" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown patron category"})
+			return
+		}
+
+		if err.Error() == "language "+user.Language+" is not supported" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported language"})
+			return
+		}
+
+		if err.Error() == "user with ID "+id.String()+" is erased" {
+			c.JSON(http.StatusConflict, gin.H{"error": "User data was erased"})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user", "details": err.Error()})
 		return
 	}
@@ -148,6 +255,11 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 			return
 		}
 
+		if err.Error() == "user with ID "+id.String()+" has loan history" {
+			c.JSON(http.StatusConflict, gin.H{"error": "User with loan history must be erased instead of deleted", "details": err.Error()})
+			return
+		}
+
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user", "details": err.Error()})
 		return
 	}
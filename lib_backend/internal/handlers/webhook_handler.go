@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"lib_backend/internal/dto"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WebhookHandler struct {
+	webhookService services.WebhookService
+}
+
+func NewWebhookHandler(s services.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: s}
+}
+
+func isWebhookValidationError(err error) bool {
+	message := err.Error()
+	return (strings.HasPrefix(message, "webhook URL ") && strings.HasSuffix(message, " is not a valid http or https URL")) ||
+		(strings.HasPrefix(message, "event type ") && strings.HasSuffix(message, " is not supported"))
+}
+
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var request dto.WebhookSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(request.URL, request.EventTypes, request.Secret)
+
+	if err != nil {
+		log.Printf("ERROR: CreateSubscription service failed for %s: %v", request.URL, err)
+
+		if isWebhookValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook subscription", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook subscription", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) GetAllSubscriptions(c *gin.Context) {
+	subscriptions, err := h.webhookService.GetAllSubscriptions()
+
+	if err != nil {
+		log.Printf("ERROR: GetAllSubscriptions service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook subscriptions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) GetSubscriptionByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook subscription ID format", "details": err.Error()})
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscriptionByID(id)
+
+	if err != nil {
+		log.Printf("ERROR: GetSubscriptionByID service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "webhook subscription with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook subscription", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook subscription ID format", "details": err.Error()})
+		return
+	}
+
+	var request dto.WebhookSubscriptionUpdateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	subscription, err := h.webhookService.UpdateSubscription(id, request.URL, request.EventTypes, *request.Active)
+
+	if err != nil {
+		log.Printf("ERROR: UpdateSubscription service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "webhook subscription with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+			return
+		}
+
+		if isWebhookValidationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook subscription", "details": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook subscription", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook subscription ID format", "details": err.Error()})
+		return
+	}
+
+	err = h.webhookService.DeleteSubscription(id)
+
+	if err != nil {
+		log.Printf("ERROR: DeleteSubscription service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "webhook subscription with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook subscription", "details": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook subscription ID format", "details": err.Error()})
+		return
+	}
+
+	limit := 50
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(id, limit)
+
+	if err != nil {
+		log.Printf("ERROR: GetDeliveries service failed for subscription %s: %v", id.String(), err)
+
+		if err.Error() == "webhook subscription with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook subscription not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve webhook deliveries", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook delivery ID format", "details": err.Error()})
+		return
+	}
+
+	delivery, err := h.webhookService.ReplayDelivery(id)
+
+	if err != nil {
+		log.Printf("ERROR: ReplayDelivery service failed for ID %s: %v", id.String(), err)
+
+		if err.Error() == "webhook delivery with ID "+id.String()+" not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook delivery not found"})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay webhook delivery", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"log"
+	"net/http"
+
+	"lib_backend/internal/dto"
+	"lib_backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type WithdrawalHandler struct {
+	withdrawalService services.WithdrawalService
+}
+
+func NewWithdrawalHandler(s services.WithdrawalService) *WithdrawalHandler {
+	return &WithdrawalHandler{withdrawalService: s}
+}
+
+// parseBookIDs converts ids already validated by the request binding.
+func parseBookIDs(values []string) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		ids = append(ids, uuid.MustParse(value))
+	}
+	return ids
+}
+
+func (h *WithdrawalHandler) WithdrawBooks(c *gin.Context) {
+	var request dto.WithdrawalRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.withdrawalService.WithdrawBooks(parseBookIDs(request.BookIDs), request.Reason)
+
+	if err != nil {
+		log.Printf("ERROR: WithdrawBooks service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to withdraw books", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WithdrawalHandler) ReinstateBooks(c *gin.Context) {
+	var request dto.ReinstateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	result, err := h.withdrawalService.ReinstateBooks(parseBookIDs(request.BookIDs))
+
+	if err != nil {
+		log.Printf("ERROR: ReinstateBooks service failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reinstate books", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
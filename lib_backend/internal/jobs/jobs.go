@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/outbox"
+	"lib_backend/internal/scheduler"
+	"lib_backend/internal/services"
+)
+
+// Register wires the time-based work of the services into the scheduler.
+func Register(sched *scheduler.Scheduler, dispatcher *outbox.Dispatcher, cfg config.SchedulerConfig, loanService services.LoanService, notificationService services.NotificationService, holdService services.HoldService, webhookService services.WebhookService, recommendationService services.RecommendationService, privacyService services.PrivacyService, idempotencyService services.IdempotencyService) {
+	jobs := []scheduler.Job{
+		{
+			Name:       "overdue",
+			Schedule:   cfg.Schedules["overdue"],
+			MaxRetries: 3,
+			Backoff:    time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				flagged, err := loanService.FlagOverdueLoans()
+				if err != nil {
+					return "", err
+				}
+
+				sent, err := notificationService.SendOverdueNotices()
+				return fmt.Sprintf("flagged %d overdue loans, sent %d overdue notices", flagged, sent), err
+			},
+		},
+		{
+			Name:       "reminders",
+			Schedule:   cfg.Schedules["reminders"],
+			MaxRetries: 3,
+			Backoff:    time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				sent, err := notificationService.SendDueSoonReminders()
+				return fmt.Sprintf("sent %d due date reminders", sent), err
+			},
+		},
+		{
+			Name:       "hold_expiry",
+			Schedule:   cfg.Schedules["hold_expiry"],
+			MaxRetries: 2,
+			Backoff:    30 * time.Second,
+			Run: func(ctx context.Context) (string, error) {
+				expired, err := holdService.ExpireHolds()
+				return fmt.Sprintf("expired %d holds", expired), err
+			},
+		},
+		{
+			Name:     "webhooks",
+			Schedule: cfg.Schedules["webhooks"],
+			// failed deliveries are retried by their own backoff on later runs
+			Run: func(ctx context.Context) (string, error) {
+				succeeded, failed, err := webhookService.DeliverDue(ctx)
+				return fmt.Sprintf("delivered %d webhooks, %d gave up", succeeded, failed), err
+			},
+		},
+		{
+			Name:       "recommendations",
+			Schedule:   cfg.Schedules["recommendations"],
+			MaxRetries: 1,
+			Backoff:    10 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				pairs, err := recommendationService.RefreshSimilarities()
+				return fmt.Sprintf("stored %d related book pairs", pairs), err
+			},
+		},
+		{
+			Name:       "anonymization",
+			Schedule:   cfg.Schedules["anonymization"],
+			MaxRetries: 2,
+			Backoff:    5 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				result, err := privacyService.AnonymizeReturnedLoans()
+				if err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("anonymized %d returned loans, detached %d fines, redacted %d events and %d webhook deliveries",
+					result.AnonymizedLoans, result.DetachedFines, result.RedactedEvents, result.RedactedDeliveries), nil
+			},
+		},
+		{
+			Name:       "purge",
+			Schedule:   cfg.Schedules["purge"],
+			MaxRetries: 1,
+			Backoff:    5 * time.Minute,
+			Run: func(ctx context.Context) (string, error) {
+				before := time.Now().AddDate(0, 0, -cfg.RetentionDays)
+
+				notifications, err := notificationService.PurgeNotifications(before)
+				if err != nil {
+					return "", err
+				}
+
+				runs, err := sched.PurgeRuns(before)
+				if err != nil {
+					return "", err
+				}
+
+				outboxEvents, err := dispatcher.PurgePublished(before)
+				if err != nil {
+					return "", err
+				}
+
+				deliveries, err := webhookService.PurgeDeliveries(before)
+				if err != nil {
+					return "", err
+				}
+
+				idempotencyKeys, err := idempotencyService.PurgeExpired()
+				if err != nil {
+					return "", err
+				}
+
+				return fmt.Sprintf("purged %d notifications, %d job runs, %d outbox events, %d webhook deliveries and %d idempotency keys", notifications, runs, outboxEvents, deliveries, idempotencyKeys), nil
+			},
+		},
+	}
+
+	for _, job := range jobs {
+		if err := sched.Register(job); err != nil {
+			log.Printf("ERROR: failed to register job %s: %v", job.Name, err)
+		}
+	}
+}
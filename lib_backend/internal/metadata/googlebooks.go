@@ -0,0 +1,85 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"lib_backend/internal/model"
+)
+
+// GoogleBooks uses the Google Books volumes search (GET /books/v1/volumes?q=isbn:<isbn>).
+type GoogleBooks struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func NewGoogleBooks(client *http.Client, baseURL string, apiKey string) *GoogleBooks {
+	return &GoogleBooks{client: client, baseURL: baseURL, apiKey: apiKey}
+}
+
+type googleBooksResponse struct {
+	Items []struct {
+		VolumeInfo struct {
+			Title         string   `json:"title"`
+			Subtitle      string   `json:"subtitle"`
+			Authors       []string `json:"authors"`
+			Publisher     string   `json:"publisher"`
+			PublishedDate string   `json:"publishedDate"`
+			PageCount     *int     `json:"pageCount"`
+			Categories    []string `json:"categories"`
+			ImageLinks    struct {
+				Thumbnail string `json:"thumbnail"`
+			} `json:"imageLinks"`
+		} `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+func (p *GoogleBooks) Name() string {
+	return "googlebooks"
+}
+
+func (p *GoogleBooks) LookupISBN(ctx context.Context, isbn string) (*model.BookMetadata, error) {
+	query := url.Values{"q": {"isbn:" + isbn}}
+	if p.apiKey != "" {
+		query.Set("key", p.apiKey)
+	}
+
+	var result googleBooksResponse
+	found, err := getJSON(ctx, p.client, p.baseURL+"/books/v1/volumes?"+query.Encode(), &result)
+
+	if err != nil || !found || len(result.Items) == 0 {
+		return nil, err
+	}
+
+	volume := result.Items[0].VolumeInfo
+
+	if volume.Title == "" {
+		return nil, nil
+	}
+
+	metadata := &model.BookMetadata{
+		ISBN:      isbn,
+		Title:     volume.Title,
+		Subtitle:  volume.Subtitle,
+		Authors:   volume.Authors,
+		Publisher: volume.Publisher,
+		Year:      parseYear(volume.PublishedDate),
+		PageCount: volume.PageCount,
+		Subjects:  volume.Categories,
+		// Google serves thumbnails over plain http by default
+		CoverURL: strings.Replace(volume.ImageLinks.Thumbnail, "http://", "https://", 1),
+	}
+
+	if metadata.Authors == nil {
+		metadata.Authors = []string{}
+	}
+
+	if metadata.Subjects == nil {
+		metadata.Subjects = []string{}
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,89 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"lib_backend/internal/model"
+)
+
+// OpenLibrary uses the Open Library Books API
+// (GET /api/books?bibkeys=ISBN:<isbn>&format=json&jscmd=data).
+type OpenLibrary struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewOpenLibrary(client *http.Client, baseURL string) *OpenLibrary {
+	return &OpenLibrary{client: client, baseURL: baseURL}
+}
+
+type openLibraryName struct {
+	Name string `json:"name"`
+}
+
+type openLibraryBook struct {
+	Title         string            `json:"title"`
+	Subtitle      string            `json:"subtitle"`
+	Authors       []openLibraryName `json:"authors"`
+	Publishers    []openLibraryName `json:"publishers"`
+	PublishDate   string            `json:"publish_date"`
+	NumberOfPages *int              `json:"number_of_pages"`
+	Subjects      []openLibraryName `json:"subjects"`
+	Cover         struct {
+		Small  string `json:"small"`
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+func (p *OpenLibrary) Name() string {
+	return "openlibrary"
+}
+
+func (p *OpenLibrary) LookupISBN(ctx context.Context, isbn string) (*model.BookMetadata, error) {
+	key := "ISBN:" + isbn
+	query := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"data"}}
+	result := map[string]openLibraryBook{}
+
+	found, err := getJSON(ctx, p.client, p.baseURL+"/api/books?"+query.Encode(), &result)
+
+	if err != nil || !found {
+		return nil, err
+	}
+
+	book, ok := result[key]
+	if !ok || book.Title == "" {
+		return nil, nil
+	}
+
+	metadata := &model.BookMetadata{
+		ISBN:      isbn,
+		Title:     book.Title,
+		Subtitle:  book.Subtitle,
+		Authors:   names(book.Authors),
+		Year:      parseYear(book.PublishDate),
+		PageCount: book.NumberOfPages,
+		Subjects:  names(book.Subjects),
+		CoverURL:  book.Cover.Large,
+	}
+
+	if len(book.Publishers) > 0 {
+		metadata.Publisher = book.Publishers[0].Name
+	}
+
+	if metadata.CoverURL == "" {
+		metadata.CoverURL = book.Cover.Medium
+	}
+
+	return metadata, nil
+}
+
+func names(entries []openLibraryName) []string {
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.Name)
+	}
+	return result
+}
@@ -0,0 +1,125 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/model"
+)
+
+// Provider looks a book up in an external catalog. LookupISBN returns nil
+// and no error when the catalog does not know the ISBN.
+type Provider interface {
+	Name() string
+	LookupISBN(ctx context.Context, isbn string) (*model.BookMetadata, error)
+}
+
+// NewProviders builds the configured providers in lookup order.
+func NewProviders(cfg config.MetadataConfig) []Provider {
+	client := &http.Client{Timeout: cfg.Timeout}
+	providers := make([]Provider, 0, len(cfg.Providers))
+
+	for _, name := range cfg.Providers {
+		switch name {
+		case "openlibrary":
+			providers = append(providers, NewOpenLibrary(client, cfg.OpenLibraryURL))
+		case "googlebooks":
+			providers = append(providers, NewGoogleBooks(client, cfg.GoogleBooksURL, cfg.GoogleBooksAPIKey))
+		default:
+			log.Printf("WARNING: unknown metadata provider %q ignored", name)
+		}
+	}
+
+	return providers
+}
+
+// Chain asks each provider in turn and returns the first match. Provider
+// errors are only returned when no other provider found the ISBN.
+type Chain []Provider
+
+func (c Chain) Name() string {
+	return "chain"
+}
+
+func (c Chain) LookupISBN(ctx context.Context, isbn string) (*model.BookMetadata, error) {
+	var errs []error
+
+	for _, provider := range c {
+		metadata, err := provider.LookupISBN(ctx, isbn)
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		if metadata != nil {
+			metadata.Source = provider.Name()
+			return metadata, nil
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// getJSON decodes the JSON body of a GET request, reporting 404 as found == false.
+func getJSON(ctx context.Context, client *http.Client, url string, target any) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	request.Header.Set("Accept", "application/json")
+	request.Header.Set("User-Agent", "lib_backend")
+
+	response, err := client.Do(request)
+
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		if closeErr := response.Body.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close metadata response body: %v", closeErr)
+		}
+	}()
+
+	if response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d", response.StatusCode)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(response.Body, 2<<20)).Decode(target); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return true, nil
+}
+
+var yearPattern = regexp.MustCompile(`\b(\d{4})\b`)
+
+// parseYear finds the year in free-form dates such as "2004", "March 2004" or "2004-03-12".
+func parseYear(date string) *int {
+	match := yearPattern.FindStringSubmatch(date)
+
+	if match == nil {
+		return nil
+	}
+
+	year, err := strconv.Atoi(match[1])
+	if err != nil {
+		return nil
+	}
+
+	return &year
+}
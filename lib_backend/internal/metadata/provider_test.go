@@ -0,0 +1,214 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lib_backend/internal/config"
+)
+
+const testISBN = "9788535914849"
+
+const openLibraryHit = `{"ISBN:9788535914849": {
+	"title": "Dom Casmurro",
+	"authors": [{"name": "Machado de Assis"}],
+	"publishers": [{"name": "Penguin"}],
+	"publish_date": "March 2016",
+	"number_of_pages": 256,
+	"subjects": [{"name": "Ficção brasileira"}],
+	"cover": {"medium": "https://covers.example/m.jpg"}
+}}`
+
+const googleBooksHit = `{"items": [{"volumeInfo": {
+	"title": "Dom Casmurro",
+	"authors": ["Machado de Assis"],
+	"publisher": "Companhia",
+	"publishedDate": "2016-03-01",
+	"imageLinks": {"thumbnail": "http://books.example/t.jpg"}
+}}]}`
+
+// catalogServer answers every request with the given status and body and
+// counts the requests it served.
+func catalogServer(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &calls
+}
+
+func TestOpenLibraryHit(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(openLibraryHit))
+	}))
+	defer server.Close()
+
+	metadata, err := NewOpenLibrary(server.Client(), server.URL).LookupISBN(context.Background(), testISBN)
+	if err != nil || metadata == nil {
+		t.Fatalf("LookupISBN() = %v, %v, want a match", metadata, err)
+	}
+
+	if !strings.Contains(query, "bibkeys=ISBN%3A"+testISBN) {
+		t.Errorf("query = %q, want the ISBN bibkey", query)
+	}
+
+	if metadata.Title != "Dom Casmurro" || metadata.Publisher != "Penguin" || *metadata.Year != 2016 || *metadata.PageCount != 256 {
+		t.Errorf("metadata = %+v, want the Open Library fields", metadata)
+	}
+
+	if len(metadata.Authors) != 1 || metadata.Authors[0] != "Machado de Assis" || metadata.CoverURL != "https://covers.example/m.jpg" {
+		t.Errorf("metadata = %+v, want authors and the medium cover", metadata)
+	}
+}
+
+func TestOpenLibraryMiss(t *testing.T) {
+	for name, server := range map[string]*httptest.Server{
+		"empty result": func() *httptest.Server { s, _ := catalogServer(t, http.StatusOK, `{}`); return s }(),
+		"not found":    func() *httptest.Server { s, _ := catalogServer(t, http.StatusNotFound, ``); return s }(),
+	} {
+		metadata, err := NewOpenLibrary(server.Client(), server.URL).LookupISBN(context.Background(), testISBN)
+		if metadata != nil || err != nil {
+			t.Errorf("%s: LookupISBN() = %v, %v, want nil and no error", name, metadata, err)
+		}
+	}
+}
+
+func TestGoogleBooksHitAndMiss(t *testing.T) {
+	hit, _ := catalogServer(t, http.StatusOK, googleBooksHit)
+
+	metadata, err := NewGoogleBooks(hit.Client(), hit.URL, "").LookupISBN(context.Background(), testISBN)
+	if err != nil || metadata == nil {
+		t.Fatalf("LookupISBN() = %v, %v, want a match", metadata, err)
+	}
+
+	if metadata.CoverURL != "https://books.example/t.jpg" || metadata.Subjects == nil || *metadata.Year != 2016 {
+		t.Errorf("metadata = %+v, want https cover, empty subjects and the year", metadata)
+	}
+
+	miss, _ := catalogServer(t, http.StatusOK, `{"totalItems": 0}`)
+
+	metadata, err = NewGoogleBooks(miss.Client(), miss.URL, "").LookupISBN(context.Background(), testISBN)
+	if metadata != nil || err != nil {
+		t.Errorf("LookupISBN() = %v, %v, want nil and no error", metadata, err)
+	}
+}
+
+func TestProvidersReportMalformedPayloadAndErrors(t *testing.T) {
+	malformed, _ := catalogServer(t, http.StatusOK, `{"ISBN:`)
+	failing, _ := catalogServer(t, http.StatusBadGateway, `oops`)
+
+	cases := map[string]Provider{
+		"openlibrary malformed": NewOpenLibrary(malformed.Client(), malformed.URL),
+		"googlebooks malformed": NewGoogleBooks(malformed.Client(), malformed.URL, ""),
+		"openlibrary 502":       NewOpenLibrary(failing.Client(), failing.URL),
+	}
+
+	for name, provider := range cases {
+		metadata, err := provider.LookupISBN(context.Background(), testISBN)
+		if metadata != nil || err == nil {
+			t.Errorf("%s: LookupISBN() = %v, %v, want an error", name, metadata, err)
+		}
+	}
+}
+
+func TestProviderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	providers := NewProviders(config.MetadataConfig{Providers: []string{"openlibrary"}, OpenLibraryURL: server.URL, Timeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	metadata, err := providers[0].LookupISBN(context.Background(), testISBN)
+
+	if metadata != nil || err == nil {
+		t.Fatalf("LookupISBN() = %v, %v, want a timeout error", metadata, err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("lookup took %s, want it cut off by the client timeout", elapsed)
+	}
+}
+
+func TestChainFallbackOrder(t *testing.T) {
+	olHit, _ := catalogServer(t, http.StatusOK, openLibraryHit)
+	olMiss, _ := catalogServer(t, http.StatusOK, `{}`)
+	olDown, _ := catalogServer(t, http.StatusInternalServerError, ``)
+	gbHit, gbCalls := catalogServer(t, http.StatusOK, googleBooksHit)
+	gbMiss, _ := catalogServer(t, http.StatusOK, `{}`)
+
+	t.Run("first match wins", func(t *testing.T) {
+		before := gbCalls.Load()
+		chain := Chain{NewOpenLibrary(olHit.Client(), olHit.URL), NewGoogleBooks(gbHit.Client(), gbHit.URL, "")}
+
+		metadata, err := chain.LookupISBN(context.Background(), testISBN)
+		if err != nil || metadata == nil || metadata.Source != "openlibrary" {
+			t.Fatalf("LookupISBN() = %+v, %v, want the Open Library match", metadata, err)
+		}
+		if gbCalls.Load() != before {
+			t.Error("Google Books was asked although Open Library matched")
+		}
+	})
+
+	t.Run("miss falls back", func(t *testing.T) {
+		chain := Chain{NewOpenLibrary(olMiss.Client(), olMiss.URL), NewGoogleBooks(gbHit.Client(), gbHit.URL, "")}
+
+		metadata, err := chain.LookupISBN(context.Background(), testISBN)
+		if err != nil || metadata == nil || metadata.Source != "googlebooks" {
+			t.Fatalf("LookupISBN() = %+v, %v, want the Google Books match", metadata, err)
+		}
+	})
+
+	t.Run("error falls back", func(t *testing.T) {
+		chain := Chain{NewOpenLibrary(olDown.Client(), olDown.URL), NewGoogleBooks(gbHit.Client(), gbHit.URL, "")}
+
+		metadata, err := chain.LookupISBN(context.Background(), testISBN)
+		if err != nil || metadata == nil || metadata.Source != "googlebooks" {
+			t.Fatalf("LookupISBN() = %+v, %v, want the Google Books match despite the error", metadata, err)
+		}
+	})
+
+	t.Run("all miss", func(t *testing.T) {
+		chain := Chain{NewOpenLibrary(olMiss.Client(), olMiss.URL), NewGoogleBooks(gbMiss.Client(), gbMiss.URL, "")}
+
+		metadata, err := chain.LookupISBN(context.Background(), testISBN)
+		if metadata != nil || err != nil {
+			t.Fatalf("LookupISBN() = %+v, %v, want nil and no error", metadata, err)
+		}
+	})
+
+	t.Run("error without match", func(t *testing.T) {
+		chain := Chain{NewOpenLibrary(olDown.Client(), olDown.URL), NewGoogleBooks(gbMiss.Client(), gbMiss.URL, "")}
+
+		metadata, err := chain.LookupISBN(context.Background(), testISBN)
+		if metadata != nil || err == nil || !strings.HasPrefix(err.Error(), "openlibrary: ") {
+			t.Fatalf("LookupISBN() = %+v, %v, want the Open Library error", metadata, err)
+		}
+	})
+}
+
+func TestNewProvidersKeepsConfiguredOrder(t *testing.T) {
+	providers := NewProviders(config.MetadataConfig{Providers: []string{"googlebooks", "unknown", "openlibrary"}, Timeout: time.Second})
+
+	if len(providers) != 2 || providers[0].Name() != "googlebooks" || providers[1].Name() != "openlibrary" {
+		t.Fatalf("NewProviders() = %v, want googlebooks then openlibrary", providers)
+	}
+}
@@ -1,11 +1,97 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Book struct {
-	ID        uuid.UUID `json:"id"`
-	Title     string    `json:"title"`
-	Author    string    `json:"author"`
-	Isbn      string    `json:"isbn"`
-	Available bool      `json:"available"`
+	ID               uuid.UUID            `json:"id"`
+	Title            string               `json:"title"`
+	Subtitle         string               `json:"subtitle,omitempty"`
+	Author           string               `json:"author"`
+	Contributors     []BookContributor    `json:"contributors,omitempty"`
+	Isbn             string               `json:"isbn"`
+	Publisher        string               `json:"publisher,omitempty"`
+	PublicationYear  *int                 `json:"publication_year,omitempty"`
+	Edition          string               `json:"edition,omitempty"`
+	Language         string               `json:"language,omitempty"`
+	PageCount        *int                 `json:"page_count,omitempty"`
+	Description      string               `json:"description,omitempty"`
+	CallNumber       string               `json:"call_number,omitempty"`
+	Subjects         []string             `json:"subjects,omitempty"`
+	Tags             []string             `json:"tags,omitempty"`
+	SeriesID         *uuid.UUID           `json:"series_id,omitempty"`
+	VolumeNumber     *int                 `json:"volume_number,omitempty"`
+	Series           *BookSeries          `json:"series,omitempty"`
+	Available        bool                 `json:"available"`
+	ItemType         string               `json:"item_type"`
+	Barcode          string               `json:"barcode"`
+	HomeBranchID     *uuid.UUID           `json:"home_branch_id,omitempty"`
+	CurrentBranchID  *uuid.UUID           `json:"current_branch_id,omitempty"`
+	Availability     []BranchAvailability `json:"availability,omitempty"`
+	AddedAt          *time.Time           `json:"added_at,omitempty"`
+	WithdrawnAt      *time.Time           `json:"withdrawn_at,omitempty"`
+	WithdrawalReason string               `json:"withdrawal_reason,omitempty"`
+	Cover            *BookCover           `json:"cover,omitempty"`
+	CoverUpdatedAt   *time.Time           `json:"-"`
+}
+
+const (
+	CoverSizeSmall  = "small"
+	CoverSizeMedium = "medium"
+	CoverSizeLarge  = "large"
+)
+
+// BookCover links to the cover thumbnails served by the API. The v parameter
+// changes with every upload, so clients can cache the images for good.
+type BookCover struct {
+	Small     string    `json:"small"`
+	Medium    string    `json:"medium"`
+	Large     string    `json:"large"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func NewBookCover(bookID uuid.UUID, updatedAt time.Time) *BookCover {
+	url := func(size string) string {
+		return fmt.Sprintf("/api/books/%s/cover?size=%s&v=%d", bookID.String(), size, updatedAt.Unix())
+	}
+
+	return &BookCover{Small: url(CoverSizeSmall), Medium: url(CoverSizeMedium), Large: url(CoverSizeLarge), UpdatedAt: updatedAt}
+}
+
+const DefaultItemType = "book"
+
+// BookFilter narrows GET /api/books; zero values mean no filtering. Query
+// matches any text field, the other text filters only their own field.
+// ContributorID matches books crediting the contributor in any role, and a
+// Limit of zero returns every match. Withdrawn books are left out unless
+// Withdrawn is set.
+type BookFilter struct {
+	Query         string
+	BranchCode    string
+	HomeBranch    string
+	Available     *bool
+	ItemType      string
+	Title         string
+	Author        string
+	ContributorID *uuid.UUID
+	Publisher     string
+	Edition       string
+	Description   string
+	Language      string
+	Subject       string
+	SubjectID     *uuid.UUID
+	Tag           string
+	SeriesID      *uuid.UUID
+	Withdrawn     *bool
+	CallNumber    string
+	YearFrom      *int
+	YearTo        *int
+	PagesMin      *int
+	PagesMax      *int
+	Limit         int
+	Offset        int
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// BookMetadata is what an external catalog knows about an ISBN, used to
+// pre-fill new books.
+type BookMetadata struct {
+	ISBN      string   `json:"isbn"`
+	Title     string   `json:"title"`
+	Subtitle  string   `json:"subtitle,omitempty"`
+	Authors   []string `json:"authors"`
+	Publisher string   `json:"publisher,omitempty"`
+	Year      *int     `json:"year,omitempty"`
+	PageCount *int     `json:"page_count,omitempty"`
+	Subjects  []string `json:"subjects"`
+	CoverURL  string   `json:"cover_url,omitempty"`
+	Source    string   `json:"source"`
+}
+
+// MetadataCacheEntry records a lookup, including ISBNs no catalog knew, so
+// repeated lookups do not hit the external catalogs again.
+type MetadataCacheEntry struct {
+	ISBN      string
+	Found     bool
+	Metadata  *BookMetadata
+	FetchedAt time.Time
+}
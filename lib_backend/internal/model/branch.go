@@ -0,0 +1,19 @@
+package model
+
+import "github.com/google/uuid"
+
+type Branch struct {
+	ID      uuid.UUID `json:"id"`
+	Code    string    `json:"code"`
+	Name    string    `json:"name"`
+	Address string    `json:"address"`
+}
+
+type BranchAvailability struct {
+	BranchID   *uuid.UUID `json:"branch_id"`
+	BranchCode string     `json:"branch_code"`
+	BranchName string     `json:"branch_name"`
+	Total      int        `json:"total"`
+	Available  int        `json:"available"`
+	InTransit  int        `json:"in_transit"`
+}
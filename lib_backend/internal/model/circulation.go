@@ -0,0 +1,29 @@
+package model
+
+const (
+	CheckinActionReshelve  = "reshelve"
+	CheckinActionHoldShelf = "hold_shelf"
+	CheckinActionTransit   = "transit"
+)
+
+type CheckoutItemResult struct {
+	ItemBarcode string `json:"item_barcode"`
+	Loan        *Loan  `json:"loan,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+type CheckoutResult struct {
+	Patron     *User                `json:"patron"`
+	CheckedOut int                  `json:"checked_out"`
+	Items      []CheckoutItemResult `json:"items"`
+}
+
+// CheckinResult tells the desk operator what to do with the returned item.
+type CheckinResult struct {
+	Book     *Book     `json:"book"`
+	Loan     *Loan     `json:"loan,omitempty"`
+	Action   string    `json:"action"`
+	Hold     *Hold     `json:"hold,omitempty"`
+	Patron   *User     `json:"hold_patron,omitempty"`
+	Transfer *Transfer `json:"transfer,omitempty"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import "github.com/google/uuid"
+
+// CirculationRule is one row of the circulation matrix. A nil PatronCategory,
+// ItemType or BranchCode is a wildcard that matches any value.
+type CirculationRule struct {
+	ID              uuid.UUID `json:"id"`
+	PatronCategory  *string   `json:"patron_category"`
+	ItemType        *string   `json:"item_type"`
+	BranchCode      *string   `json:"branch_code"`
+	LoanPeriodDays  int       `json:"loan_period_days"`
+	MaxRenewals     int       `json:"max_renewals"`
+	FinePerDayCents int       `json:"fine_per_day_cents"`
+	HoldsAllowed    bool      `json:"holds_allowed"`
+	NonCirculating  bool      `json:"non_circulating"`
+}
+
+// Specificity ranks a rule: patron category outweighs item type, which
+// outweighs branch, so the most specific matching rule always wins.
+func (r *CirculationRule) Specificity() int {
+	score := 0
+	if r.PatronCategory != nil {
+		score += 4
+	}
+	if r.ItemType != nil {
+		score += 2
+	}
+	if r.BranchCode != nil {
+		score++
+	}
+	return score
+}
+
+// CirculationPolicy is the effective policy for a patron/item/branch
+// combination, taken from the most specific rule or, when no rule matches,
+// from the patron category defaults.
+type CirculationPolicy struct {
+	Source          string           `json:"source"`
+	Rule            *CirculationRule `json:"rule,omitempty"`
+	LoanPeriodDays  int              `json:"loan_period_days"`
+	MaxRenewals     int              `json:"max_renewals"`
+	FinePerDayCents int              `json:"fine_per_day_cents"`
+	HoldsAllowed    bool             `json:"holds_allowed"`
+	NonCirculating  bool             `json:"non_circulating"`
+}
+
+const (
+	PolicySourceRule           = "rule"
+	PolicySourcePatronCategory = "patron_category"
+)
+
+type PolicyExplanation struct {
+	LoanID         uuid.UUID          `json:"loan_id"`
+	PatronCategory string             `json:"patron_category"`
+	ItemType       string             `json:"item_type"`
+	BranchCode     string             `json:"branch_code"`
+	Applied        *CirculationPolicy `json:"applied"`
+	Current        *CirculationPolicy `json:"current"`
+	Candidates     []CirculationRule  `json:"candidates"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ContributorRoleAuthor      = "author"
+	ContributorRoleEditor      = "editor"
+	ContributorRoleTranslator  = "translator"
+	ContributorRoleIllustrator = "illustrator"
+)
+
+// Contributor is a person credited on books. Name is in reading order
+// ("Machado de Assis") and SortName inverted for alphabetical lists
+// ("Assis, Machado de").
+type Contributor struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name"`
+	SortName  string            `json:"sort_name"`
+	BookCount int               `json:"book_count"`
+	CreatedAt time.Time         `json:"created_at"`
+	Works     []ContributorWork `json:"works,omitempty"`
+}
+
+// BookContributor credits a contributor on a book. When writing a book,
+// either ID or Name identifies the contributor; an unknown name creates one.
+type BookContributor struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	SortName string    `json:"sort_name,omitempty"`
+	Role     string    `json:"role"`
+}
+
+// ContributorWork is a book in a contributor's bibliography.
+type ContributorWork struct {
+	Role string `json:"role"`
+	Book Book   `json:"book"`
+}
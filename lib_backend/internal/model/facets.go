@@ -0,0 +1,42 @@
+package model
+
+import "github.com/google/uuid"
+
+// FacetValue counts the matching books with one value of a facet. ID is set
+// for facets backed by an entity, such as subjects and authors.
+type FacetValue struct {
+	ID    *uuid.UUID `json:"id,omitempty"`
+	Value string     `json:"value"`
+	Count int        `json:"count"`
+}
+
+// YearRange counts the matching books published in a decade.
+type YearRange struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Count int `json:"count"`
+}
+
+type AvailabilityFacet struct {
+	Available   int `json:"available"`
+	Unavailable int `json:"unavailable"`
+}
+
+type BookFacets struct {
+	Subjects     []FacetValue      `json:"subjects"`
+	Tags         []FacetValue      `json:"tags"`
+	Authors      []FacetValue      `json:"authors"`
+	Languages    []FacetValue      `json:"languages"`
+	Years        []YearRange       `json:"years"`
+	Availability AvailabilityFacet `json:"availability"`
+}
+
+// CatalogPage is one page of a faceted search. Total and the facets cover
+// every match, not only the books on the page.
+type CatalogPage struct {
+	Total  int        `json:"total"`
+	Limit  int        `json:"limit"`
+	Offset int        `json:"offset"`
+	Books  []Book     `json:"books"`
+	Facets BookFacets `json:"facets"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Fine struct {
+	ID          uuid.UUID  `json:"id"`
+	LoanID      *uuid.UUID `json:"loan_id,omitempty"`
+	UserID      uuid.UUID  `json:"user_id"`
+	DaysLate    int        `json:"days_late"`
+	AmountCents int        `json:"amount_cents"`
+	Paid        bool       `json:"paid"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PaidAt      *time.Time `json:"paid_at,omitempty"`
+}
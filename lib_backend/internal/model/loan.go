@@ -11,10 +11,19 @@ type Loan struct {
 	UserID     uuid.UUID  `json:"user_id"`
 	BookID     uuid.UUID  `json:"book_id"`
 	LoanedAt   time.Time  `json:"loaned_at"`
+	DueAt      time.Time  `json:"due_at"`
 	Returned   bool       `json:"returned"`
 	ReturnedAt *time.Time `json:"returned_at,omitempty"`
-}
+	Renewals   int        `json:"renewals"`
+	RuleID     *uuid.UUID `json:"circulation_rule_id,omitempty"`
+
+	CheckoutBranchID *uuid.UUID `json:"checkout_branch_id,omitempty"`
+	ReturnBranchID   *uuid.UUID `json:"return_branch_id,omitempty"`
+
+	// OverdueAt is when the loan was flagged overdue for its current due date.
+	OverdueAt *time.Time `json:"overdue_at,omitempty"`
 
-func DefaultLoanedAt() time.Time {
-	return time.Now()
+	// AnonymizedAt is set once the loan no longer records its patron, and
+	// UserID is then empty.
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
 }
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NotificationDueSoon       = "due_soon"
+	NotificationOverdue       = "overdue"
+	NotificationHoldReady     = "hold_ready"
+	NotificationAccountChange = "account_change"
+)
+
+const (
+	NotificationStatusSent   = "sent"
+	NotificationStatusFailed = "failed"
+)
+
+// Notification is the log entry of a message sent (or attempted) to a user.
+// ReferenceID points at the loan, hold or user the message is about.
+type Notification struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Kind        string     `json:"kind"`
+	Channel     string     `json:"channel"`
+	Recipient   string     `json:"recipient"`
+	Subject     string     `json:"subject"`
+	Body        string     `json:"body"`
+	Status      string     `json:"status"`
+	Error       *string    `json:"error,omitempty"`
+	ReferenceID *uuid.UUID `json:"reference_id,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxEvent is a domain event recorded in the same transaction as the change
+// it describes. Seq gives the commit order; ID lets consumers drop duplicates.
+type OutboxEvent struct {
+	Seq         int64           `json:"seq"`
+	ID          uuid.UUID       `json:"id"`
+	Type        string          `json:"type"`
+	Data        json.RawMessage `json:"data"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	PublishedAt *time.Time      `json:"published_at,omitempty"`
+}
@@ -0,0 +1,12 @@
+package model
+
+type PatronCategory struct {
+	Code           string `json:"code"`
+	Name           string `json:"name"`
+	MaxLoans       int    `json:"max_loans"`
+	LoanPeriodDays int    `json:"loan_period_days"`
+	MaxRenewals    int    `json:"max_renewals"`
+	MaxHolds       int    `json:"max_holds"`
+}
+
+const DefaultPatronCategory = "student"
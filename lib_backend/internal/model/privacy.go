@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserDataExport gathers everything stored about a patron. Events are the
+// domain events that mention the patron, which serve as its audit trail.
+type UserDataExport struct {
+	ExportedAt    time.Time      `json:"exported_at"`
+	Profile       *User          `json:"profile"`
+	Loans         []Loan         `json:"loans"`
+	Holds         []Hold         `json:"holds"`
+	Fines         []Fine         `json:"fines"`
+	Notifications []Notification `json:"notifications"`
+	Events        []OutboxEvent  `json:"events"`
+}
+
+type ErasureResult struct {
+	UserID               uuid.UUID `json:"user_id"`
+	ErasedAt             time.Time `json:"erased_at"`
+	AnonymizedLoans      int64     `json:"anonymized_loans"`
+	DeletedHolds         int64     `json:"deleted_holds"`
+	DeletedNotifications int64     `json:"deleted_notifications"`
+	RedactedEvents       int64     `json:"redacted_events"`
+}
+
+// AnonymizationResult counts what the anonymization job stripped of patrons.
+type AnonymizationResult struct {
+	AnonymizedLoans    int64 `json:"anonymized_loans"`
+	DetachedFines      int64 `json:"detached_fines"`
+	RedactedEvents     int64 `json:"redacted_events"`
+	RedactedDeliveries int64 `json:"redacted_deliveries"`
+}
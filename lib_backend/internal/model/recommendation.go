@@ -0,0 +1,9 @@
+package model
+
+// Recommendation is a book suggested from the loan history. Score grows with
+// the share of patrons who borrowed it together with the books it was
+// recommended for.
+type Recommendation struct {
+	Book  Book    `json:"book"`
+	Score float64 `json:"score"`
+}
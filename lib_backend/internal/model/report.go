@@ -0,0 +1,96 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ReportIntervalDay   = "day"
+	ReportIntervalWeek  = "week"
+	ReportIntervalMonth = "month"
+)
+
+// ReportFilter selects the loans a report covers: those checked out from
+// From (inclusive) to To (exclusive), optionally only at one branch or by
+// patrons of one category.
+type ReportFilter struct {
+	From     time.Time
+	To       time.Time
+	BranchID *uuid.UUID
+	Category string
+	Interval string
+	Limit    int
+}
+
+type LoanVolume struct {
+	Period  time.Time `json:"period"`
+	Loans   int       `json:"loans"`
+	Patrons int       `json:"patrons"`
+}
+
+type TopBook struct {
+	BookID uuid.UUID `json:"book_id"`
+	Title  string    `json:"title"`
+	Author string    `json:"author"`
+	Isbn   string    `json:"isbn"`
+	Loans  int       `json:"loans"`
+}
+
+type ActivePatrons struct {
+	Category string `json:"category"`
+	Patrons  int    `json:"patrons"`
+	Loans    int    `json:"loans"`
+}
+
+// LoanDuration covers the loans of the period that were already returned.
+type LoanDuration struct {
+	ReturnedLoans int     `json:"returned_loans"`
+	AverageDays   float64 `json:"average_days"`
+	MinDays       float64 `json:"min_days"`
+	MaxDays       float64 `json:"max_days"`
+}
+
+// OverdueRate counts the loans returned after their due date, or still out
+// past it.
+type OverdueRate struct {
+	Loans   int     `json:"loans"`
+	Overdue int     `json:"overdue"`
+	Rate    float64 `json:"rate"`
+}
+
+// CollectionTurnover divides the loans of the period by the items of the
+// collection, that is, those homed at the branch when one is given.
+type CollectionTurnover struct {
+	Loans    int     `json:"loans"`
+	Items    int     `json:"items"`
+	Turnover float64 `json:"turnover"`
+}
+
+// WeedingFilter selects the books that have not circulated: never when Years
+// is nil, otherwise not in the last Years years. Books younger than that are
+// left out, since they had no chance to circulate.
+type WeedingFilter struct {
+	Years     *int
+	BranchID  *uuid.UUID
+	SubjectID *uuid.UUID
+	Subject   string
+	Limit     int
+	Offset    int
+}
+
+// WeedingCandidate is a book of the dead-stock report. AddedAt and AgeDays
+// are unknown for books catalogued before they were recorded.
+type WeedingCandidate struct {
+	BookID        uuid.UUID  `json:"book_id"`
+	Title         string     `json:"title"`
+	Author        string     `json:"author"`
+	Isbn          string     `json:"isbn"`
+	CallNumber    string     `json:"call_number,omitempty"`
+	BranchCode    string     `json:"branch_code,omitempty"`
+	AddedAt       *time.Time `json:"added_at,omitempty"`
+	AgeDays       *int       `json:"age_days,omitempty"`
+	LastLoanAt    *time.Time `json:"last_loan_at,omitempty"`
+	LifetimeLoans int        `json:"lifetime_loans"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Series groups the volumes of a series or multi-volume work. Volumes are
+// ordered by the VolumeNumber of each book.
+type Series struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	VolumeCount int       `json:"volume_count"`
+	CreatedAt   time.Time `json:"created_at"`
+	Volumes     []Book    `json:"volumes,omitempty"`
+}
+
+// BookSeries places a book in its series and points at the volume that
+// follows it, so patrons know what to borrow next.
+type BookSeries struct {
+	ID           uuid.UUID     `json:"id"`
+	Title        string        `json:"title"`
+	VolumeNumber *int          `json:"volume_number,omitempty"`
+	VolumeCount  int           `json:"volume_count"`
+	Next         *SeriesVolume `json:"next,omitempty"`
+}
+
+type SeriesVolume struct {
+	BookID       uuid.UUID `json:"book_id"`
+	Title        string    `json:"title"`
+	VolumeNumber int       `json:"volume_number"`
+	Available    bool      `json:"available"`
+}
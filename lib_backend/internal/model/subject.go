@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subject is a heading of the controlled vocabulary. Headings form a tree
+// through ParentID, so browsing a broad subject can reach the narrower ones.
+type Subject struct {
+	ID        uuid.UUID  `json:"id"`
+	Heading   string     `json:"heading"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+	BookCount int        `json:"book_count"`
+	CreatedAt time.Time  `json:"created_at"`
+	Parent    *Subject   `json:"parent,omitempty"`
+	Narrower  []Subject  `json:"narrower,omitempty"`
+}
+
+// SubjectFilter narrows GET /api/subjects. Without Query or ParentID only
+// top-level headings are listed.
+type SubjectFilter struct {
+	Query    string
+	ParentID *uuid.UUID
+	Limit    int
+}
+
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
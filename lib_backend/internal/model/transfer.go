@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TransferStatusRequested = "requested"
+	TransferStatusInTransit = "in_transit"
+	TransferStatusReceived  = "received"
+)
+
+const (
+	TransferReasonReturn = "return"
+	TransferReasonHold   = "hold"
+	TransferReasonManual = "manual"
+)
+
+type Transfer struct {
+	ID           uuid.UUID  `json:"id"`
+	BookID       uuid.UUID  `json:"book_id"`
+	FromBranchID uuid.UUID  `json:"from_branch_id"`
+	ToBranchID   uuid.UUID  `json:"to_branch_id"`
+	HoldID       *uuid.UUID `json:"hold_id,omitempty"`
+	Reason       string     `json:"reason"`
+	Status       string     `json:"status"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ShippedAt    *time.Time `json:"shipped_at,omitempty"`
+	ReceivedAt   *time.Time `json:"received_at,omitempty"`
+	Book         *Book      `json:"book,omitempty"`
+}
+
+type TransferFilter struct {
+	FromBranchID *uuid.UUID
+	ToBranchID   *uuid.UUID
+	Status       string
+}
@@ -1,10 +1,28 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
 	ID           uuid.UUID `json:"id"`
 	Name         string    `json:"name"`
 	Registration string    `json:"registration"`
 	Email        string    `json:"email"`
+	Category     string    `json:"category"`
+	Language     string    `json:"language"`
+
+	// KeepLoanHistory opts out of the anonymization of returned loans. Left
+	// out of an update, the current choice is kept.
+	KeepLoanHistory *bool `json:"keep_loan_history"`
+
+	// ErasedAt is set once the personal data was erased on request.
+	ErasedAt *time.Time `json:"erased_at,omitempty"`
 }
+
+const DefaultLanguage = "pt"
+
+// SupportedLanguages lists the languages notifications can be sent in.
+var SupportedLanguages = []string{"pt", "en"}
@@ -0,0 +1,16 @@
+package model
+
+import "github.com/google/uuid"
+
+type WithdrawalItemResult struct {
+	BookID uuid.UUID `json:"book_id"`
+	Book   *Book     `json:"book,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// WithdrawalResult reports each book of a bulk withdrawal or reinstatement;
+// books that could not be changed carry the reason in Error.
+type WithdrawalResult struct {
+	Changed int                    `json:"changed"`
+	Items   []WithdrawalItemResult `json:"items"`
+}
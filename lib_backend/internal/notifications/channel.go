@@ -0,0 +1,31 @@
+package notifications
+
+import (
+	"log"
+
+	"lib_backend/internal/model"
+)
+
+// Channel delivers rendered messages to users. Address returns an empty
+// string when the user cannot be reached on the channel.
+type Channel interface {
+	Name() string
+	Address(user *model.User) string
+	Send(to, subject, body string) error
+}
+
+// LogChannel writes messages to the server log instead of delivering them.
+type LogChannel struct{}
+
+func (LogChannel) Name() string {
+	return "log"
+}
+
+func (LogChannel) Address(user *model.User) string {
+	return user.Email
+}
+
+func (LogChannel) Send(to, subject, body string) error {
+	log.Printf("NOTIFICATION to %s: %s\n%s", to, subject, body)
+	return nil
+}
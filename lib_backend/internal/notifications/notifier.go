@@ -0,0 +1,84 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// Notifier renders messages in the user's language and sends them on every
+// configured channel, recording each attempt in the notifications log.
+type Notifier struct {
+	templates        *Templates
+	channels         []Channel
+	userRepo         repository.UserRepository
+	notificationRepo repository.NotificationRepository
+}
+
+func NewNotifier(templates *Templates, userRepo repository.UserRepository, notificationRepo repository.NotificationRepository, channels ...Channel) *Notifier {
+	return &Notifier{templates: templates, channels: channels, userRepo: userRepo, notificationRepo: notificationRepo}
+}
+
+// Notify sends a message of the given kind to a user. The user is added to the
+// template data as .User; referenceID identifies the loan, hold or user the
+// message is about.
+func (n *Notifier) Notify(userID uuid.UUID, kind string, data map[string]any, referenceID *uuid.UUID) error {
+	user, err := n.userRepo.GetUserByID(userID)
+
+	if err != nil {
+		return fmt.Errorf("failed to get user for %s notification: %w", kind, err)
+	}
+
+	if user == nil {
+		return fmt.Errorf("user with ID %s not found for notification", userID.String())
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["User"] = user
+
+	subject, body, err := n.templates.Render(kind, user.Language, data)
+
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, channel := range n.channels {
+		recipient := channel.Address(user)
+		if recipient == "" {
+			continue
+		}
+
+		notification := &model.Notification{
+			UserID:      user.ID,
+			Kind:        kind,
+			Channel:     channel.Name(),
+			Recipient:   recipient,
+			Subject:     subject,
+			Body:        body,
+			Status:      model.NotificationStatusSent,
+			ReferenceID: referenceID,
+		}
+
+		if err := channel.Send(recipient, subject, body); err != nil {
+			message := err.Error()
+			notification.Status = model.NotificationStatusFailed
+			notification.Error = &message
+			errs = append(errs, err)
+		}
+
+		if err := n.notificationRepo.CreateNotification(notification); err != nil {
+			log.Printf("ERROR: failed to record %s notification for user %s: %v", kind, user.ID.String(), err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
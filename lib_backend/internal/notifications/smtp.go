@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/model"
+)
+
+// SMTPChannel sends plain text emails. Authentication is only used when a
+// username is configured, so local stand-ins like MailHog work unchanged.
+type SMTPChannel struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPChannel(cfg config.NotificationConfig) *SMTPChannel {
+	channel := &SMTPChannel{addr: net.JoinHostPort(cfg.SMTPHost, cfg.SMTPPort), from: cfg.From}
+
+	if cfg.SMTPUsername != "" {
+		channel.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return channel
+}
+
+func (c *SMTPChannel) Name() string {
+	return "email"
+}
+
+func (c *SMTPChannel) Address(user *model.User) string {
+	return user.Email
+}
+
+func (c *SMTPChannel) Send(to, subject, body string) error {
+	var msg strings.Builder
+	msg.WriteString("From: " + c.from + "\r\n")
+	msg.WriteString("To: " + to + "\r\n")
+	msg.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	if err := smtp.SendMail(c.addr, c.auth, c.from, []string{to}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"lib_backend/internal/config"
+)
+
+// smtpMessage is what the fake server received for one mail transaction.
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTP accepts a single SMTP session on a random local port and
+// sends the message it received on the returned channel.
+func startFakeSMTP(t *testing.T) (string, string, <-chan smtpMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan smtpMessage, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		msg := smtpMessage{}
+
+		reply("220 fake ESMTP")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 fake")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				msg.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 end with .")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				msg.data = data.String()
+				reply("250 queued")
+				received <- msg
+			case command == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to split listener address: %v", err)
+	}
+
+	return host, port, received
+}
+
+func TestSMTPChannelSendsMessage(t *testing.T) {
+	host, port, received := startFakeSMTP(t)
+	channel := NewSMTPChannel(config.NotificationConfig{SMTPHost: host, SMTPPort: port, From: "biblioteca@example.com"})
+
+	if err := channel.Send("leitor@example.com", "Empréstimo vencido", "Olá\nDevolva o livro."); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if msg.from != "biblioteca@example.com" {
+			t.Errorf("MAIL FROM = %q, want the configured sender", msg.from)
+		}
+		if len(msg.to) != 1 || msg.to[0] != "leitor@example.com" {
+			t.Errorf("RCPT TO = %v, want the patron", msg.to)
+		}
+		for _, want := range []string{
+			"To: leitor@example.com\r\n",
+			"Subject: =?utf-8?q?Empr=C3=A9stimo_vencido?=\r\n",
+			"Content-Type: text/plain; charset=UTF-8\r\n",
+			"\r\nOlá\r\nDevolva o livro.",
+		} {
+			if !strings.Contains(msg.data, want) {
+				t.Errorf("message does not contain %q:\n%s", want, msg.data)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server received no message")
+	}
+}
+
+func TestSMTPChannelReportsUnreachableServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	channel := NewSMTPChannel(config.NotificationConfig{SMTPHost: host, SMTPPort: port, From: "biblioteca@example.com"})
+
+	err = channel.Send("leitor@example.com", "Aviso", "corpo")
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to send email to leitor@example.com") {
+		t.Fatalf("Send() error = %v, want a send failure", err)
+	}
+}
@@ -0,0 +1,87 @@
+package notifications
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"lib_backend/internal/model"
+)
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+var dateLayouts = map[string]string{
+	"pt": "02/01/2006",
+	"en": "Jan 2, 2006",
+}
+
+// Templates renders notification messages. Each message is a file named
+// <kind>.<language>.tmpl defining a "subject" and a "body" template; files in
+// the override directory take precedence over the built-in ones and are read
+// on every render, so they can be edited without restarting the server.
+type Templates struct {
+	dir string
+}
+
+func NewTemplates(dir string) *Templates {
+	return &Templates{dir: dir}
+}
+
+func (t *Templates) Render(kind, language string, data any) (string, string, error) {
+	source, err := t.load(kind, language)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	layout, ok := dateLayouts[language]
+	if !ok {
+		layout = dateLayouts[model.DefaultLanguage]
+	}
+
+	funcs := template.FuncMap{
+		"date": func(value time.Time) string { return value.Format(layout) },
+	}
+
+	tmpl, err := template.New(kind).Funcs(funcs).Parse(source)
+
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse %s template for language %s: %w", kind, language, err)
+	}
+
+	var subject, body strings.Builder
+
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s subject: %w", kind, err)
+	}
+
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s body: %w", kind, err)
+	}
+
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(body.String()) + "\n", nil
+}
+
+// load finds the template for the language, falling back to the default language.
+func (t *Templates) load(kind, language string) (string, error) {
+	for _, lang := range []string{language, model.DefaultLanguage} {
+		name := kind + "." + lang + ".tmpl"
+
+		if t.dir != "" {
+			if content, err := os.ReadFile(filepath.Join(t.dir, name)); err == nil {
+				return string(content), nil
+			}
+		}
+
+		if content, err := embeddedTemplates.ReadFile("templates/" + name); err == nil {
+			return string(content), nil
+		}
+	}
+
+	return "", fmt.Errorf("no %s template found for language %s", kind, language)
+}
@@ -0,0 +1,227 @@
+// Package openapi serves and enforces the OpenAPI description of the API.
+// The document is written by hand in openapi.json; the server checks at
+// startup that it matches the registered routes.
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed openapi.json
+var document []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+type Spec struct {
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas   map[string]*Schema   `json:"schemas"`
+		Responses map[string]*Response `json:"responses"`
+	} `json:"components"`
+}
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters"`
+	RequestBody *RequestBody         `json:"requestBody"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref     string               `json:"$ref"`
+	Content map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Load parses the embedded document and resolves the component references.
+// A schema using a keyword the validator does not enforce is an error, so
+// the document cannot promise a constraint that is silently skipped.
+func Load() (*Spec, error) {
+	return parse(document)
+}
+
+func parse(data []byte) (*Spec, error) {
+	var spec Spec
+
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse openapi document: %w", err)
+	}
+
+	if err := spec.resolve(); err != nil {
+		return nil, err
+	}
+
+	return &spec, nil
+}
+
+// Document returns the raw JSON document served to clients.
+func Document() []byte {
+	return document
+}
+
+// DocsPage returns the HTML page that renders the document. It loads no
+// third-party assets, only the document itself. It stands in for Swagger UI
+// until swagger-ui-dist is vendored under swagger-ui/ and embedded.
+func DocsPage() []byte {
+	return docsPage
+}
+
+func (s *Spec) resolve() error {
+	for name, schema := range s.Components.Schemas {
+		if err := s.resolveSchema(schema); err != nil {
+			return fmt.Errorf("schema %s: %w", name, err)
+		}
+	}
+
+	for path, operations := range s.Paths {
+		for method, operation := range operations {
+			for _, parameter := range operation.Parameters {
+				if err := s.resolveSchema(parameter.Schema); err != nil {
+					return fmt.Errorf("%s %s: %w", method, path, err)
+				}
+			}
+
+			if operation.RequestBody != nil {
+				for _, media := range operation.RequestBody.Content {
+					if err := s.resolveSchema(media.Schema); err != nil {
+						return fmt.Errorf("%s %s: %w", method, path, err)
+					}
+				}
+			}
+
+			for status, response := range operation.Responses {
+				if response.Ref != "" {
+					target, ok := s.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+					if !ok {
+						return fmt.Errorf("%s %s: unknown response %s", method, path, response.Ref)
+					}
+					operation.Responses[status] = target
+					response = target
+				}
+				for _, media := range response.Content {
+					if err := s.resolveSchema(media.Schema); err != nil {
+						return fmt.Errorf("%s %s: %w", method, path, err)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Spec) resolveSchema(schema *Schema) error {
+	if schema == nil || schema.resolved {
+		return nil
+	}
+	schema.resolved = true
+
+	if len(schema.unsupported) > 0 {
+		return fmt.Errorf("unsupported schema keywords %s", strings.Join(schema.unsupported, ", "))
+	}
+
+	if schema.Format != "" && !formats[schema.Format] {
+		return fmt.Errorf("unsupported format %s", schema.Format)
+	}
+
+	if schema.Ref != "" {
+		target, ok := s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			return fmt.Errorf("unknown schema %s", schema.Ref)
+		}
+		schema.target = target
+	}
+
+	children := []*Schema{schema.Items}
+	children = append(children, schema.OneOf...)
+	for _, property := range schema.Properties {
+		children = append(children, property)
+	}
+
+	for _, child := range children {
+		if err := s.resolveSchema(child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PathTemplate converts a gin route such as /api/users/:id into the OpenAPI
+// form /api/users/{id}.
+func PathTemplate(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Operation returns the documented operation for a gin route, or nil.
+func (s *Spec) Operation(method string, route string) *Operation {
+	return s.Paths[PathTemplate(route)][strings.ToLower(method)]
+}
+
+// CheckRoutes compares the routes registered under the documented prefixes
+// with the document and describes every route or operation missing from the
+// other side.
+func (s *Spec) CheckRoutes(routes gin.RoutesInfo, prefixes ...string) []string {
+	documented := func(path string) bool {
+		for _, prefix := range prefixes {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	problems := make([]string, 0)
+	registered := make(map[string]bool)
+
+	for _, route := range routes {
+		path := PathTemplate(route.Path)
+		if !documented(path) {
+			continue
+		}
+		registered[route.Method+" "+path] = true
+
+		if s.Operation(route.Method, route.Path) == nil {
+			problems = append(problems, fmt.Sprintf("route %s %s is not documented", route.Method, path))
+		}
+	}
+
+	for path, operations := range s.Paths {
+		for method := range operations {
+			if !registered[strings.ToUpper(method)+" "+path] {
+				problems = append(problems, fmt.Sprintf("operation %s %s has no route", strings.ToUpper(method), path))
+			}
+		}
+	}
+
+	sort.Strings(problems)
+	return problems
+}
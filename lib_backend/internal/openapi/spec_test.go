@@ -0,0 +1,43 @@
+package openapi
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCheckRoutesReportsBothDirections(t *testing.T) {
+	spec := &Spec{Paths: map[string]map[string]*Operation{
+		"/api/users":      {"get": {}, "post": {}},
+		"/api/users/{id}": {"get": {}},
+	}}
+
+	routes := gin.RoutesInfo{
+		{Method: "GET", Path: "/api/users"},
+		{Method: "GET", Path: "/api/users/:id"},
+		{Method: "DELETE", Path: "/api/users/:id"},
+		{Method: "GET", Path: "/api/tags"},
+	}
+
+	got := spec.CheckRoutes(routes, "/api/users")
+	want := []string{
+		"operation POST /api/users has no route",
+		"route DELETE /api/users/{id} is not documented",
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatalf("CheckRoutes() = %q, want %q", got, want)
+	}
+}
+
+func TestDocumentLoads(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if spec.Operation("GET", "/api/users/:id") == nil {
+		t.Fatal("GET /api/users/:id is not documented")
+	}
+}
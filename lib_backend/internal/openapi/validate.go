@@ -0,0 +1,397 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schema is the subset of JSON Schema used by the document.
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 Types              `json:"type"`
+	Format               string             `json:"format"`
+	Enum                 []any              `json:"enum"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	OneOf                []*Schema          `json:"oneOf"`
+	MinItems             *int               `json:"minItems"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+	Maximum              *float64           `json:"maximum"`
+
+	target      *Schema
+	resolved    bool
+	unsupported []string
+}
+
+// schemaKeywords are the keywords Schema enforces, followed by annotations
+// that do not constrain values.
+var schemaKeywords = map[string]bool{
+	"$ref": true, "type": true, "format": true, "enum": true, "properties": true, "required": true,
+	"additionalProperties": true, "items": true, "oneOf": true, "minItems": true, "minLength": true,
+	"minimum": true, "maximum": true,
+
+	"title": true, "description": true, "default": true, "example": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true, "contentMediaType": true, "contentEncoding": true,
+}
+
+// UnmarshalJSON records the keywords the validator does not know, such as
+// maxLength or allOf, for Load to reject.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type plain Schema
+	if err := json.Unmarshal(data, (*plain)(s)); err != nil {
+		return err
+	}
+
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keywords); err != nil {
+		return err
+	}
+
+	for keyword := range keywords {
+		if !schemaKeywords[keyword] {
+			s.unsupported = append(s.unsupported, keyword)
+		}
+	}
+	sort.Strings(s.unsupported)
+
+	return nil
+}
+
+// Types accepts both "type": "string" and "type": ["string", "null"].
+type Types []string
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*t = list
+	return nil
+}
+
+// ValidateRequest checks the path and query parameters and, for JSON
+// requests, the body of a request to the operation. A body without a content
+// type is taken as JSON.
+func (o *Operation) ValidateRequest(pathParams map[string]string, query url.Values, contentType string, body []byte) error {
+	for _, parameter := range o.Parameters {
+		var value string
+		var present bool
+
+		switch parameter.In {
+		case "path":
+			value, present = pathParams[parameter.Name]
+		case "query":
+			// handlers treat an empty parameter as a missing one
+			value = query.Get(parameter.Name)
+			present = value != ""
+		default:
+			continue
+		}
+
+		if !present {
+			if parameter.Required {
+				return fmt.Errorf("%s parameter %s is required", parameter.In, parameter.Name)
+			}
+			continue
+		}
+
+		if err := validateParameter(parameter.Schema, value); err != nil {
+			return fmt.Errorf("%s parameter %s: %w", parameter.In, parameter.Name, err)
+		}
+	}
+
+	if o.RequestBody == nil {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if mediaType == "" {
+		if len(bytes.TrimSpace(body)) == 0 {
+			if o.RequestBody.Required {
+				return fmt.Errorf("request body is required")
+			}
+			return nil
+		}
+		// the handlers bind JSON whatever the declared content type
+		mediaType = "application/json"
+	}
+
+	media, ok := o.RequestBody.Content[mediaType]
+
+	if !ok {
+		return fmt.Errorf("content type %q is not accepted", mediaType)
+	}
+
+	if media.Schema == nil || mediaType != "application/json" {
+		return nil
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return fmt.Errorf("request body is required")
+	}
+
+	return validateJSON(media.Schema, body, "body")
+}
+
+// ValidateResponse checks a JSON response against the schema documented for
+// its status. Undocumented statuses and content types other than JSON are
+// not checked.
+func (o *Operation) ValidateResponse(status int, contentType string, body []byte) error {
+	response, ok := o.Responses[strconv.Itoa(status)]
+
+	if !ok {
+		return nil
+	}
+
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			return fmt.Errorf("status %d must not have a body", status)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := response.Content[mediaType]
+
+	if !ok {
+		return fmt.Errorf("content type %q is not documented for status %d", contentType, status)
+	}
+
+	if media.Schema == nil || mediaType != "application/json" {
+		return nil
+	}
+
+	return validateJSON(media.Schema, body, "response")
+}
+
+func validateJSON(schema *Schema, data []byte, path string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%s is not valid JSON: %w", path, err)
+	}
+
+	return schema.validate(value, path)
+}
+
+// validateParameter converts the text of a parameter to the documented type
+// before validating it.
+func validateParameter(schema *Schema, text string) error {
+	if schema == nil {
+		return nil
+	}
+
+	var value any = text
+
+	switch {
+	case schema.allows("integer"):
+		if _, err := strconv.ParseInt(text, 10, 64); err != nil {
+			return fmt.Errorf("%q is not an integer", text)
+		}
+		value = json.Number(text)
+	case schema.allows("boolean"):
+		parsed, err := strconv.ParseBool(text)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", text)
+		}
+		value = parsed
+	}
+
+	if err := schema.validate(value, ""); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Schema) allows(kind string) bool {
+	for _, t := range s.Type {
+		if t == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func fail(path string, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	if path == "" {
+		return fmt.Errorf("%s", message)
+	}
+	return fmt.Errorf("%s: %s", path, message)
+}
+
+func (s *Schema) validate(value any, path string) error {
+	if s.target != nil {
+		return s.target.validate(value, path)
+	}
+
+	if len(s.OneOf) > 0 {
+		matches := 0
+		for _, option := range s.OneOf {
+			if option.validate(value, path) == nil {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fail(path, "must match exactly one schema, matched %d", matches)
+		}
+		return nil
+	}
+
+	kind := typeOf(value)
+
+	if len(s.Type) > 0 && !s.allows(kind) && !(kind == "integer" && s.allows("number")) {
+		return fail(path, "must be %s, got %s", strings.Join(s.Type, " or "), kind)
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, option := range s.Enum {
+			if sameValue(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fail(path, "must be one of %v", s.Enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		if s.MinLength != nil && len([]rune(v)) < *s.MinLength {
+			return fail(path, "must have at least %d characters", *s.MinLength)
+		}
+		if err := checkFormat(s.Format, v); err != nil {
+			return fail(path, "%v", err)
+		}
+	case json.Number:
+		n, _ := v.Float64()
+		if s.Minimum != nil && n < *s.Minimum {
+			return fail(path, "must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fail(path, "must be at most %v", *s.Maximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			return fail(path, "must have at least %d items", *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fail(join(path, name), "is required")
+			}
+		}
+
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			property, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fail(join(path, name), "is not allowed")
+				}
+				continue
+			}
+			if err := property.validate(v[name], join(path, name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sameValue compares an enum option, decoded without UseNumber, with a
+// validated value.
+func sameValue(option any, value any) bool {
+	if number, ok := value.(json.Number); ok {
+		n, err := number.Float64()
+		return err == nil && option == n
+	}
+	return option == value
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// formats are the values of format that checkFormat enforces.
+var formats = map[string]bool{"uuid": true, "date-time": true, "email": true}
+
+func checkFormat(format string, value string) error {
+	switch format {
+	case "uuid":
+		if _, err := uuid.Parse(value); err != nil {
+			return fmt.Errorf("%q is not a valid UUID", value)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+			return fmt.Errorf("%q is not a valid date-time", value)
+		}
+	case "email":
+		if _, err := mail.ParseAddress(value); err != nil {
+			return fmt.Errorf("%q is not a valid email", value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// parseSchema resolves a schema against components holding a Branch schema.
+func parseSchema(t *testing.T, source string) *Schema {
+	t.Helper()
+
+	spec := &Spec{}
+	branch := &Schema{}
+	if err := json.Unmarshal([]byte(`{"type": "object", "required": ["code"], "properties": {"code": {"type": "string"}}}`), branch); err != nil {
+		t.Fatalf("branch schema: %v", err)
+	}
+	spec.Components.Schemas = map[string]*Schema{"Branch": branch}
+
+	schema := &Schema{}
+	if err := json.Unmarshal([]byte(source), schema); err != nil {
+		t.Fatalf("schema %s: %v", source, err)
+	}
+	if err := spec.resolveSchema(schema); err != nil {
+		t.Fatalf("resolveSchema(%s) error = %v", source, err)
+	}
+	return schema
+}
+
+func TestSchemaValidate(t *testing.T) {
+	cases := []struct {
+		name   string
+		schema string
+		value  string
+		want   string
+	}{
+		{"required present", `{"type": "object", "required": ["title"]}`, `{"title": "Dom Casmurro"}`, ""},
+		{"required missing", `{"type": "object", "required": ["title"]}`, `{}`, "body.title: is required"},
+		{"required nested", `{"type": "object", "properties": {"branch": {"$ref": "#/components/schemas/Branch"}}}`, `{"branch": {}}`, "body.branch.code: is required"},
+		{"type", `{"type": "integer"}`, `"1"`, "body: must be integer, got string"},
+		{"integer as number", `{"type": "number"}`, `1`, ""},
+		{"nullable", `{"type": ["string", "null"]}`, `null`, ""},
+		{"enum match", `{"type": "string", "enum": ["author", "editor"]}`, `"editor"`, ""},
+		{"enum miss", `{"type": "string", "enum": ["author", "editor"]}`, `"reader"`, "body: must be one of [author editor]"},
+		{"enum number", `{"type": "integer", "enum": [1, 2]}`, `2`, ""},
+		{"format uuid", `{"type": "string", "format": "uuid"}`, `"not-a-uuid"`, `body: "not-a-uuid" is not a valid UUID`},
+		{"format date-time", `{"type": "string", "format": "date-time"}`, `"2026-10-19T10:00:00Z"`, ""},
+		{"format date-time invalid", `{"type": "string", "format": "date-time"}`, `"19/10/2026"`, `body: "19/10/2026" is not a valid date-time`},
+		{"format email", `{"type": "string", "format": "email"}`, `"leitor@example.com"`, ""},
+		{"format email invalid", `{"type": "string", "format": "email"}`, `"leitor"`, `body: "leitor" is not a valid email`},
+		{"additional allowed", `{"type": "object", "properties": {"a": {"type": "string"}}}`, `{"b": 1}`, ""},
+		{"additional rejected", `{"type": "object", "additionalProperties": false, "properties": {"a": {"type": "string"}}}`, `{"a": "x", "b": 1}`, "body.b: is not allowed"},
+		{"oneOf one match", `{"oneOf": [{"type": "string"}, {"type": "integer"}]}`, `1`, ""},
+		{"oneOf no match", `{"oneOf": [{"type": "string"}, {"type": "integer"}]}`, `true`, "body: must match exactly one schema, matched 0"},
+		{"oneOf two matches", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, "body: must match exactly one schema, matched 2"},
+		{"minimum", `{"type": "integer", "minimum": 1}`, `0`, "body: must be at least 1"},
+		{"maximum", `{"type": "integer", "maximum": 100}`, `101`, "body: must be at most 100"},
+		{"minLength", `{"type": "string", "minLength": 2}`, `"é"`, "body: must have at least 2 characters"},
+		{"minItems", `{"type": "array", "minItems": 1}`, `[]`, "body: must have at least 1 items"},
+		{"items", `{"type": "array", "items": {"type": "string", "format": "uuid"}}`, `["x"]`, `body[0]: "x" is not a valid UUID`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateJSON(parseSchema(t, tc.schema), []byte(tc.value), "body")
+
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.want {
+				t.Fatalf("validate(%s) error = %q, want %q", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateRequestParameters(t *testing.T) {
+	operation := &Operation{Parameters: []Parameter{
+		{Name: "id", In: "path", Required: true, Schema: parseSchema(t, `{"type": "string", "format": "uuid"}`)},
+		{Name: "limit", In: "query", Schema: parseSchema(t, `{"type": "integer", "minimum": 1, "maximum": 100}`)},
+		{Name: "withdrawn", In: "query", Schema: parseSchema(t, `{"type": "boolean"}`)},
+	}}
+	id := map[string]string{"id": "0b8e4b4e-7d39-4a4a-9d36-7d1a54c3f0f1"}
+
+	cases := []struct {
+		name   string
+		params map[string]string
+		query  string
+		want   string
+	}{
+		{"valid", id, "limit=10&withdrawn=true", ""},
+		{"missing path", map[string]string{}, "", "path parameter id is required"},
+		{"invalid path", map[string]string{"id": "1"}, "", `path parameter id: "1" is not a valid UUID`},
+		{"not an integer", id, "limit=ten", `query parameter limit: "ten" is not an integer`},
+		{"out of range", id, "limit=0", "query parameter limit: must be at least 1"},
+		{"not a boolean", id, "withdrawn=maybe", `query parameter withdrawn: "maybe" is not a boolean`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tc.query)
+			err := operation.ValidateRequest(tc.params, query, "", nil)
+
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.want {
+				t.Fatalf("ValidateRequest() error = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsUnsupportedKeywords(t *testing.T) {
+	cases := map[string]string{
+		"maxLength": `{"type": "string", "maxLength": 10}`,
+		"pattern":   `{"type": "string", "pattern": "^[0-9]+$"}`,
+		"maxItems":  `{"type": "array", "maxItems": 3}`,
+		"allOf":     `{"allOf": [{"type": "object"}]}`,
+		"anyOf":     `{"anyOf": [{"type": "string"}]}`,
+		"format":    `{"type": "string", "format": "uri"}`,
+	}
+
+	for keyword, schema := range cases {
+		t.Run(keyword, func(t *testing.T) {
+			document := `{"paths": {}, "components": {"schemas": {"Book": {"type": "object", "properties": {"field": ` + schema + `}}}}}`
+
+			_, err := parse([]byte(document))
+
+			if err == nil || !strings.HasPrefix(err.Error(), "schema Book: unsupported ") || !strings.Contains(err.Error(), keyword) {
+				t.Fatalf("parse() error = %v, want %s rejected", err, keyword)
+			}
+		})
+	}
+
+	document := `{"paths": {}, "components": {"schemas": {"Book": {"type": "object", "description": "A copy", "properties": {"title": {"type": "string", "example": "Dom Casmurro"}}}}}}`
+	if _, err := parse([]byte(document)); err != nil {
+		t.Fatalf("parse() with annotations only error = %v", err)
+	}
+}
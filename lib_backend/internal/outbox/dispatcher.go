@@ -0,0 +1,140 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+)
+
+// Dispatcher relays committed outbox events to its sinks in seq order. An
+// event is marked published once every sink accepted it; if a sink fails the
+// batch stops there and the event is retried on the next poll, so sinks get
+// each event at least once and must deduplicate by event ID.
+type Dispatcher struct {
+	transactor repository.Transactor
+	outboxRepo repository.OutboxRepository
+	sinks      []events.Sink
+	cfg        config.OutboxConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(transactor repository.Transactor, outboxRepo repository.OutboxRepository, cfg config.OutboxConfig) *Dispatcher {
+	return &Dispatcher{transactor: transactor, outboxRepo: outboxRepo, cfg: cfg}
+}
+
+// AddSink must be called before Start.
+func (d *Dispatcher) AddSink(sink events.Sink) {
+	d.sinks = append(d.sinks, sink)
+}
+
+func (d *Dispatcher) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	d.cancel = cancel
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.loop(ctx)
+	}()
+
+	log.Printf("Outbox dispatcher started with %d sinks", len(d.sinks))
+}
+
+func (d *Dispatcher) Stop() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) loop(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// keep going while full batches come back, so a backlog drains quickly
+		for {
+			sent, err := d.Dispatch(ctx)
+			if err != nil {
+				log.Printf("ERROR: outbox dispatch failed: %v", err)
+			}
+			if err != nil || sent < d.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+	}
+}
+
+// Dispatch relays one batch of pending events and returns how many were
+// published. It does nothing while another replica holds the dispatch lock.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	published := make([]int64, 0, d.cfg.BatchSize)
+	var sendErr error
+
+	err := d.transactor.WithinTx(func(tx *sql.Tx) error {
+		outboxRepo := d.outboxRepo.WithTx(tx)
+		acquired, err := outboxRepo.TryLockDispatch()
+
+		if err != nil || !acquired {
+			return err
+		}
+
+		pending, err := outboxRepo.GetUnpublished(d.cfg.BatchSize)
+
+		if err != nil {
+			return err
+		}
+
+		for _, outboxEvent := range pending {
+			if sendErr = d.send(ctx, ToEvent(outboxEvent)); sendErr != nil {
+				break
+			}
+			published = append(published, outboxEvent.Seq)
+		}
+
+		if len(published) == 0 {
+			return nil
+		}
+
+		return outboxRepo.MarkPublished(published)
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(published), sendErr
+}
+
+func ToEvent(outboxEvent model.OutboxEvent) events.Event {
+	return events.Event{Seq: outboxEvent.Seq, ID: outboxEvent.ID, Type: outboxEvent.Type, OccurredAt: outboxEvent.OccurredAt, Data: outboxEvent.Data}
+}
+
+func (d *Dispatcher) send(ctx context.Context, event events.Event) error {
+	for _, sink := range d.sinks {
+		if err := sink.Send(ctx, event); err != nil {
+			return fmt.Errorf("sink %s failed on event %s %s: %w", sink.Name(), event.Type, event.ID.String(), err)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) PurgePublished(before time.Time) (int64, error) {
+	return d.outboxRepo.PurgePublishedBefore(before)
+}
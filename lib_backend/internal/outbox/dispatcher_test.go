@@ -0,0 +1,213 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeOutbox is an in-memory outbox whose dispatch lock, like the advisory
+// lock, is held by one transaction until it ends.
+type fakeOutbox struct {
+	repository.OutboxRepository
+	mu     sync.Mutex
+	events []model.OutboxEvent
+	holder *sql.Tx
+}
+
+// txOutbox is the fake outbox as seen from inside one transaction.
+type txOutbox struct {
+	*fakeOutbox
+	tx *sql.Tx
+}
+
+func newFakeOutbox(count int) *fakeOutbox {
+	outbox := &fakeOutbox{}
+	for i := 1; i <= count; i++ {
+		outbox.events = append(outbox.events, model.OutboxEvent{Seq: int64(i), ID: uuid.New(), Type: events.LoanCreated, Data: []byte(`{}`)})
+	}
+	return outbox
+}
+
+func (o *fakeOutbox) WithTx(tx *sql.Tx) repository.OutboxRepository {
+	return &txOutbox{fakeOutbox: o, tx: tx}
+}
+
+func (o *txOutbox) TryLockDispatch() (bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.holder != nil {
+		return false, nil
+	}
+	o.holder = o.tx
+	return true, nil
+}
+
+func (o *txOutbox) GetUnpublished(limit int) ([]model.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	pending := make([]model.OutboxEvent, 0, limit)
+	for _, event := range o.events {
+		if event.PublishedAt == nil && len(pending) < limit {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+func (o *txOutbox) MarkPublished(seqs []int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	now := time.Now()
+	for _, seq := range seqs {
+		o.events[seq-1].PublishedAt = &now
+	}
+	return nil
+}
+
+// end releases the dispatch lock if tx holds it, as committing or rolling
+// back releases a transaction-level advisory lock.
+func (o *fakeOutbox) end(tx *sql.Tx) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.holder == tx {
+		o.holder = nil
+	}
+}
+
+// concurrentTransactor lets transactions overlap, like separate replicas, and
+// gives each one its own identity.
+type concurrentTransactor struct {
+	outbox *fakeOutbox
+}
+
+func (t *concurrentTransactor) WithinTx(fn func(tx *sql.Tx) error) error {
+	tx := new(sql.Tx)
+	defer t.outbox.end(tx)
+	return fn(tx)
+}
+
+// recordingSink remembers the seq of every event it accepted.
+type recordingSink struct {
+	mu   sync.Mutex
+	seqs []int64
+	fail func(event events.Event) error
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Send(ctx context.Context, event events.Event) error {
+	// a slow sink keeps the lock held while other dispatchers try to run
+	time.Sleep(100 * time.Microsecond)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail != nil {
+		if err := s.fail(event); err != nil {
+			return err
+		}
+	}
+	s.seqs = append(s.seqs, event.Seq)
+	return nil
+}
+
+func TestConcurrentDispatchersSendEachEventOnceInOrder(t *testing.T) {
+	const total = 60
+	outbox := newFakeOutbox(total)
+	sink := &recordingSink{}
+	dispatcher := NewDispatcher(&concurrentTransactor{outbox: outbox}, outbox, config.OutboxConfig{BatchSize: 7})
+	dispatcher.AddSink(sink)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				outbox.mu.Lock()
+				done := outbox.events[total-1].PublishedAt != nil
+				outbox.mu.Unlock()
+				if done {
+					return
+				}
+				sent, err := dispatcher.Dispatch(context.Background())
+				if err != nil {
+					t.Errorf("Dispatch() error = %v", err)
+					return
+				}
+				if sent == 0 {
+					// another dispatcher holds the lock, as the poll loop would wait
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(sink.seqs) != total {
+		t.Fatalf("sink received %d events, want each of the %d exactly once", len(sink.seqs), total)
+	}
+
+	for i, seq := range sink.seqs {
+		if seq != int64(i+1) {
+			t.Fatalf("event %d sent as seq %d, want events in seq order", i, seq)
+		}
+	}
+}
+
+func TestDispatchStopsAtFailedEventAndRetriesIt(t *testing.T) {
+	outbox := newFakeOutbox(5)
+	failures := 1
+	sink := &recordingSink{fail: func(event events.Event) error {
+		if event.Seq == 3 && failures > 0 {
+			failures--
+			return errors.New("sink unavailable")
+		}
+		return nil
+	}}
+	dispatcher := NewDispatcher(&concurrentTransactor{outbox: outbox}, outbox, config.OutboxConfig{BatchSize: 10})
+	dispatcher.AddSink(sink)
+
+	sent, err := dispatcher.Dispatch(context.Background())
+	if sent != 2 || err == nil {
+		t.Fatalf("first Dispatch() = %d, %v, want 2 published and the sink error", sent, err)
+	}
+
+	sent, err = dispatcher.Dispatch(context.Background())
+	if sent != 3 || err != nil {
+		t.Fatalf("second Dispatch() = %d, %v, want the remaining 3 published", sent, err)
+	}
+
+	want := []int64{1, 2, 3, 4, 5}
+	if len(sink.seqs) != len(want) {
+		t.Fatalf("sink received %v, want %v", sink.seqs, want)
+	}
+	for i := range want {
+		if sink.seqs[i] != want[i] {
+			t.Fatalf("sink received %v, want %v", sink.seqs, want)
+		}
+	}
+}
+
+func TestDispatchSkipsWhileLockIsHeld(t *testing.T) {
+	outbox := newFakeOutbox(3)
+	outbox.holder = new(sql.Tx)
+	sink := &recordingSink{}
+	dispatcher := NewDispatcher(&concurrentTransactor{outbox: outbox}, outbox, config.OutboxConfig{BatchSize: 10})
+	dispatcher.AddSink(sink)
+
+	sent, err := dispatcher.Dispatch(context.Background())
+	if sent != 0 || err != nil || len(sink.seqs) != 0 {
+		t.Fatalf("Dispatch() = %d, %v with %d events sent, want nothing while another replica dispatches", sent, err, len(sink.seqs))
+	}
+}
@@ -0,0 +1,68 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"lib_backend/internal/events"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsFlushTimeout bounds how long Send waits for the server to acknowledge a
+// published event before reporting the event as failed.
+const natsFlushTimeout = 5 * time.Second
+
+// NATSSink publishes events to <prefix>.<event type>, e.g. library.loan.created.
+// The event ID goes in the Nats-Msg-Id header so JetStream streams drop the
+// duplicates at-least-once delivery can produce.
+type NATSSink struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+func NewNATSSink(url string, prefix string) (*NATSSink, error) {
+	conn, err := nats.Connect(url, nats.Name("lib_backend"), nats.RetryOnFailedConnect(true), nats.MaxReconnects(-1))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS at %s: %w", url, err)
+	}
+
+	return &NATSSink{conn: conn, prefix: prefix}, nil
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Send(ctx context.Context, event events.Event) error {
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode event for NATS: %w", err)
+	}
+
+	msg := nats.NewMsg(s.prefix + "." + event.Type)
+	msg.Header.Set(nats.MsgIdHdr, event.ID.String())
+	msg.Data = data
+
+	if err := s.conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish event to NATS: %w", err)
+	}
+
+	// publishing only buffers the message; flushing confirms the server got it
+	flushCtx, cancel := context.WithTimeout(ctx, natsFlushTimeout)
+	defer cancel()
+
+	if err := s.conn.FlushWithContext(flushCtx); err != nil {
+		return fmt.Errorf("failed to flush event to NATS: %w", err)
+	}
+
+	return nil
+}
+
+func (s *NATSSink) Close() {
+	s.conn.Close()
+}
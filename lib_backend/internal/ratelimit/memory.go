@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"lib_backend/internal/config"
+)
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryStore keeps the buckets of a single replica. Buckets idle for longer
+// than idleTTL are full again, so they are dropped to bound memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	idleTTL   time.Duration
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore(idleTTL time.Duration) *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket), idleTTL: idleTTL, lastSweep: time.Now(), now: time.Now}
+}
+
+func (s *MemoryStore) Take(key string, limit config.RateLimit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Requests), b.tokens+now.Sub(b.updatedAt).Seconds()*ratePerSecond(limit))
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return newResult(limit, b.tokens, false), nil
+	}
+
+	b.tokens--
+	return newResult(limit, b.tokens, true), nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.idleTTL {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) >= s.idleTTL {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
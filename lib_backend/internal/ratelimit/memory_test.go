@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lib_backend/internal/config"
+)
+
+func TestMemoryStoreSpendsEachTokenOnceUnderConcurrency(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	start := time.Now()
+	store.now = func() time.Time { return start }
+	limit := config.RateLimit{Requests: 5, Period: time.Minute}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := store.Take("books:ip:10.0.0.1", limit)
+			if err != nil {
+				t.Errorf("Take() error = %v", err)
+				return
+			}
+			if result.Allowed {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != 5 {
+		t.Fatalf("%d requests allowed, want 5", got)
+	}
+}
+
+func TestMemoryStoreRefillsAndReportsRetryAfter(t *testing.T) {
+	store := NewMemoryStore(time.Hour)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	limit := config.RateLimit{Requests: 2, Period: time.Minute}
+
+	for i := range 2 {
+		if result, _ := store.Take("k", limit); !result.Allowed {
+			t.Fatalf("request %d refused, want allowed", i+1)
+		}
+	}
+
+	result, _ := store.Take("k", limit)
+	if result.Allowed || result.RetryAfter != 30*time.Second {
+		t.Fatalf("third request = %+v, want refused with 30s retry", result)
+	}
+
+	now = now.Add(30 * time.Second)
+	if result, _ := store.Take("k", limit); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("request after refill = %+v, want allowed with none remaining", result)
+	}
+
+	if result, _ := store.Take("other", limit); !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("request of another key = %+v, want its own full bucket", result)
+	}
+}
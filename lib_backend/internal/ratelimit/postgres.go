@@ -0,0 +1,56 @@
+package ratelimit
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/repository"
+)
+
+// PostgresStore shares the buckets between replicas through the database.
+// Each request costs one statement; idle buckets are purged at most once per
+// idleTTL by whichever replica notices first.
+type PostgresStore struct {
+	rateLimitRepo repository.RateLimitRepository
+	idleTTL       time.Duration
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(rateLimitRepo repository.RateLimitRepository, idleTTL time.Duration) *PostgresStore {
+	return &PostgresStore{rateLimitRepo: rateLimitRepo, idleTTL: idleTTL, lastSweep: time.Now()}
+}
+
+func (s *PostgresStore) Take(key string, limit config.RateLimit) (Result, error) {
+	s.sweep()
+
+	tokens, allowed, err := s.rateLimitRepo.TakeToken(key, float64(limit.Requests), ratePerSecond(limit))
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	return newResult(limit, tokens, allowed), nil
+}
+
+func (s *PostgresStore) sweep() {
+	s.mu.Lock()
+	due := time.Since(s.lastSweep) >= s.idleTTL
+	if due {
+		s.lastSweep = time.Now()
+	}
+	s.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	go func() {
+		if _, err := s.rateLimitRepo.PurgeIdleBuckets(time.Now().Add(-s.idleTTL)); err != nil {
+			log.Printf("ERROR: failed to purge idle rate limit buckets: %v", err)
+		}
+	}()
+}
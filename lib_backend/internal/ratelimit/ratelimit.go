@@ -0,0 +1,58 @@
+// Package ratelimit implements token buckets: each key holds up to Requests
+// tokens, refilled continuously at Requests per Period, and every request
+// takes one.
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"lib_backend/internal/config"
+)
+
+// Result describes a bucket after a request tried to take a token.
+// RetryAfter is set when the request was refused; Reset is when the bucket
+// will be full again.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Reset      time.Duration
+}
+
+// Store keeps the buckets. Implementations must take tokens atomically, so
+// concurrent requests for one key never spend the same token twice.
+type Store interface {
+	Take(key string, limit config.RateLimit) (Result, error)
+}
+
+func ratePerSecond(limit config.RateLimit) float64 {
+	return float64(limit.Requests) / limit.Period.Seconds()
+}
+
+// newResult builds the result from the tokens left in the bucket, after the
+// request's token was taken when allowed.
+func newResult(limit config.RateLimit, tokens float64, allowed bool) Result {
+	rate := ratePerSecond(limit)
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsToDuration((float64(limit.Requests) - tokens) / rate),
+	}
+
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
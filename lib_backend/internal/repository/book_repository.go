@@ -4,34 +4,102 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"lib_backend/internal/model"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type BookRepository interface {
+	WithTx(tx *sql.Tx) BookRepository
 	CreateBook(book *model.Book) error
 	GetBookByID(id uuid.UUID) (*model.Book, error)
+	LockBook(id uuid.UUID) (*model.Book, error)
 	GetBookByISBN(isbn string) (*model.Book, error)
+	GetBookByBarcode(barcode string) (*model.Book, error)
 	UpdateBook(book *model.Book) error
 	DeleteBook(id uuid.UUID) error
 	GetAllBooks() ([]model.Book, error)
+	SearchBooks(filter model.BookFilter) ([]model.Book, error)
+	GetFacets(filter model.BookFilter, limit int) (*model.BookFacets, error)
+	GetBranchAvailability(bookID uuid.UUID) ([]model.BranchAvailability, error)
+	SetCoverUpdatedAt(id uuid.UUID, updatedAt *time.Time) error
+	SetWithdrawn(book *model.Book) error
+	NextItemBarcodeSequence() (int64, error)
+}
+
+const bookColumns = `id, title, author, isbn, available, item_type, barcode, home_branch_id, current_branch_id, cover_updated_at,
+	subtitle, publisher, publication_year, edition, language, page_count, description, call_number, tags,
+	series_id, volume_number`
+
+// bookSelectColumns adds the columns the database fills in and the subject
+// headings, which live in book_subjects, to the stored columns. Queries using
+// it must select FROM books unaliased.
+const bookSelectColumns = bookColumns + `, added_at, withdrawn_at, withdrawal_reason,
+	ARRAY(SELECT s.heading FROM book_subjects bs JOIN subjects s ON s.id = bs.subject_id WHERE bs.book_id = books.id ORDER BY s.heading)`
+
+func scanBook(row rowScanner, book *model.Book) error {
+	var subtitle, publisher, edition, language, description, callNumber, withdrawalReason sql.NullString
+
+	err := row.Scan(&book.ID, &book.Title, &book.Author, &book.Isbn, &book.Available, &book.ItemType, &book.Barcode, &book.HomeBranchID, &book.CurrentBranchID, &book.CoverUpdatedAt,
+		&subtitle, &publisher, &book.PublicationYear, &edition, &language, &book.PageCount, &description, &callNumber, pq.Array(&book.Tags),
+		&book.SeriesID, &book.VolumeNumber, &book.AddedAt, &book.WithdrawnAt, &withdrawalReason, pq.Array(&book.Subjects))
+
+	if err != nil {
+		return err
+	}
+
+	book.Subtitle = subtitle.String
+	book.Publisher = publisher.String
+	book.Edition = edition.String
+	book.Language = language.String
+	book.Description = description.String
+	book.CallNumber = callNumber.String
+	book.WithdrawalReason = withdrawalReason.String
+
+	if book.CoverUpdatedAt != nil {
+		book.Cover = model.NewBookCover(book.ID, *book.CoverUpdatedAt)
+	}
+
+	return nil
+}
+
+// nullIfEmpty stores blank optional text as NULL.
+func nullIfEmpty(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// textArray stores a nil slice as an empty array rather than NULL.
+func textArray(values []string) any {
+	if values == nil {
+		values = []string{}
+	}
+	return pq.Array(values)
 }
 
 type bookRepositoryImpl struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewBookRepository(db *sql.DB) BookRepository {
 	return &bookRepositoryImpl{db: db}
 }
 
+func (r *bookRepositoryImpl) WithTx(tx *sql.Tx) BookRepository {
+	return &bookRepositoryImpl{db: tx}
+}
+
 func (r *bookRepositoryImpl) CreateBook(book *model.Book) error {
 	book.ID = uuid.New()
 
-	query := `INSERT INTO books (id, title, author, isbn, available) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Exec(query, book.ID, book.Title, book.Author, book.Isbn, book.Available)
+	query := `INSERT INTO books (` + bookColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING added_at`
+	err := r.db.QueryRow(query, book.ID, book.Title, book.Author, book.Isbn, book.Available, book.ItemType, book.Barcode, book.HomeBranchID, book.CurrentBranchID, book.CoverUpdatedAt,
+		nullIfEmpty(book.Subtitle), nullIfEmpty(book.Publisher), book.PublicationYear, nullIfEmpty(book.Edition), nullIfEmpty(book.Language), book.PageCount, nullIfEmpty(book.Description), nullIfEmpty(book.CallNumber), textArray(book.Tags),
+		book.SeriesID, book.VolumeNumber).Scan(&book.AddedAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to create book: %w", err)
@@ -42,8 +110,8 @@ func (r *bookRepositoryImpl) CreateBook(book *model.Book) error {
 
 func (r *bookRepositoryImpl) GetBookByID(id uuid.UUID) (*model.Book, error) {
 	book := &model.Book{}
-	query := `SELECT id, title, author, isbn, available FROM books WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&book.ID, &book.Title, &book.Author, &book.Isbn, &book.Available)
+	query := `SELECT ` + bookSelectColumns + ` FROM books WHERE id = $1`
+	err := scanBook(r.db.QueryRow(query, id), book)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -54,10 +122,30 @@ func (r *bookRepositoryImpl) GetBookByID(id uuid.UUID) (*model.Book, error) {
 	return book, nil
 }
 
+// LockBook reads a copy and locks its row until the transaction ends, so
+// concurrent checkouts of one copy check its availability one at a time.
+func (r *bookRepositoryImpl) LockBook(id uuid.UUID) (*model.Book, error) {
+	book := &model.Book{}
+	query := `SELECT ` + bookSelectColumns + ` FROM books WHERE id = $1 FOR UPDATE`
+	err := scanBook(r.db.QueryRow(query, id), book)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to lock book ID %s: %w", id.String(), err)
+	}
+
+	return book, nil
+}
+
+// GetBookByISBN returns one copy of the title, preferring an available copy
+// that has not been withdrawn.
 func (r *bookRepositoryImpl) GetBookByISBN(isbn string) (*model.Book, error) {
 	book := &model.Book{}
-	query := `SELECT id, title, author, isbn, available FROM books WHERE isbn = $1`
-	err := r.db.QueryRow(query, isbn).Scan(&book.ID, &book.Title, &book.Author, &book.Isbn, &book.Available)
+	query := `SELECT ` + bookSelectColumns + ` FROM books WHERE isbn = $1
+		ORDER BY withdrawn_at IS NOT NULL, available DESC, barcode
+		LIMIT 1`
+	err := scanBook(r.db.QueryRow(query, isbn), book)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -68,9 +156,28 @@ func (r *bookRepositoryImpl) GetBookByISBN(isbn string) (*model.Book, error) {
 	return book, nil
 }
 
+func (r *bookRepositoryImpl) GetBookByBarcode(barcode string) (*model.Book, error) {
+	book := &model.Book{}
+	query := `SELECT ` + bookSelectColumns + ` FROM books WHERE barcode = $1`
+	err := scanBook(r.db.QueryRow(query, barcode), book)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get book by barcode %s: %w", barcode, err)
+	}
+
+	return book, nil
+}
+
 func (r *bookRepositoryImpl) UpdateBook(book *model.Book) error {
-	query := `UPDATE books SET title = $2, author = $3, isbn = $4, available = $5 WHERE id = $1`
-	res, err := r.db.Exec(query, book.ID, book.Title, book.Author, book.Isbn, book.Available)
+	query := `UPDATE books SET title = $2, author = $3, isbn = $4, available = $5, item_type = $6, barcode = $7, home_branch_id = $8, current_branch_id = $9,
+		subtitle = $10, publisher = $11, publication_year = $12, edition = $13, language = $14, page_count = $15, description = $16, call_number = $17, tags = $18,
+		series_id = $19, volume_number = $20
+		WHERE id = $1`
+	res, err := r.db.Exec(query, book.ID, book.Title, book.Author, book.Isbn, book.Available, book.ItemType, book.Barcode, book.HomeBranchID, book.CurrentBranchID,
+		nullIfEmpty(book.Subtitle), nullIfEmpty(book.Publisher), book.PublicationYear, nullIfEmpty(book.Edition), nullIfEmpty(book.Language), book.PageCount, nullIfEmpty(book.Description), nullIfEmpty(book.CallNumber), textArray(book.Tags),
+		book.SeriesID, book.VolumeNumber)
 
 	if err != nil {
 		return fmt.Errorf("failed to update book: %w", err)
@@ -109,7 +216,7 @@ func (r *bookRepositoryImpl) DeleteBook(id uuid.UUID) error {
 }
 
 func (r *bookRepositoryImpl) GetAllBooks() ([]model.Book, error) {
-	query := `SELECT id, title, author, isbn, available FROM books`
+	query := `SELECT ` + bookSelectColumns + ` FROM books`
 	rows, err := r.db.Query(query)
 
 	if err != nil {
@@ -125,7 +232,140 @@ func (r *bookRepositoryImpl) GetAllBooks() ([]model.Book, error) {
 
 	for rows.Next() {
 		book := model.Book{}
-		if err := rows.Scan(&book.ID, &book.Title, &book.Author, &book.Isbn, &book.Available); err != nil {
+		if err := scanBook(rows, &book); err != nil {
+			return nil, fmt.Errorf("failed to scan book row: %w", err)
+		}
+		books = append(books, book)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during book rows iteration: %w", err)
+	}
+
+	return books, nil
+}
+
+// bookFilterConditions turns a filter into a WHERE clause over books and
+// its arguments, shared by the search and facet queries.
+func bookFilterConditions(filter model.BookFilter) (string, []any) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.Query != "" {
+		addCondition(`(title ILIKE ? OR subtitle ILIKE ? OR author ILIKE ? OR isbn ILIKE ? OR barcode ILIKE ? OR publisher ILIKE ?
+			OR edition ILIKE ? OR description ILIKE ? OR call_number ILIKE ? OR EXISTS (SELECT 1 FROM unnest(tags) AS t WHERE t ILIKE ?)
+			OR EXISTS (SELECT 1 FROM book_subjects bs JOIN subjects s ON s.id = bs.subject_id WHERE bs.book_id = books.id AND s.heading ILIKE ?)
+			OR EXISTS (SELECT 1 FROM book_contributors bc JOIN contributors c ON c.id = bc.contributor_id WHERE bc.book_id = books.id AND c.name ILIKE ?))`, "%"+filter.Query+"%")
+	}
+	if filter.Title != "" {
+		addCondition(`(title ILIKE ? OR subtitle ILIKE ?)`, "%"+filter.Title+"%")
+	}
+	if filter.Author != "" {
+		addCondition(`(author ILIKE ? OR EXISTS (SELECT 1 FROM book_contributors bc JOIN contributors c ON c.id = bc.contributor_id
+			WHERE bc.book_id = books.id AND c.name ILIKE ?))`, "%"+filter.Author+"%")
+	}
+	if filter.ContributorID != nil {
+		addCondition(`EXISTS (SELECT 1 FROM book_contributors bc WHERE bc.book_id = books.id AND bc.contributor_id = ?)`, *filter.ContributorID)
+	}
+	if filter.Publisher != "" {
+		addCondition(`publisher ILIKE ?`, "%"+filter.Publisher+"%")
+	}
+	if filter.Edition != "" {
+		addCondition(`edition ILIKE ?`, "%"+filter.Edition+"%")
+	}
+	if filter.Description != "" {
+		addCondition(`description ILIKE ?`, "%"+filter.Description+"%")
+	}
+	if filter.Language != "" {
+		addCondition(`lower(language) = lower(?)`, filter.Language)
+	}
+	if filter.Subject != "" {
+		addCondition(`EXISTS (SELECT 1 FROM book_subjects bs JOIN subjects s ON s.id = bs.subject_id
+			WHERE bs.book_id = books.id AND s.heading_key = subject_heading_key(?))`, filter.Subject)
+	}
+	if filter.SubjectID != nil {
+		// browsing a subject includes the books under its narrower subjects
+		addCondition(`EXISTS (SELECT 1 FROM book_subjects bs WHERE bs.book_id = books.id AND bs.subject_id IN (
+			WITH RECURSIVE tree AS (SELECT id FROM subjects WHERE id = ? UNION SELECT s.id FROM subjects s JOIN tree ON s.parent_id = tree.id)
+			SELECT id FROM tree))`, *filter.SubjectID)
+	}
+	if filter.Tag != "" {
+		addCondition(`? = ANY(tags)`, strings.ToLower(filter.Tag))
+	}
+	if filter.SeriesID != nil {
+		addCondition(`series_id = ?`, *filter.SeriesID)
+	}
+	if filter.Withdrawn == nil {
+		conditions = append(conditions, `withdrawn_at IS NULL`)
+	} else {
+		addCondition(`(withdrawn_at IS NOT NULL) = ?`, *filter.Withdrawn)
+	}
+	if filter.CallNumber != "" {
+		// call numbers are browsed by class, so this matches a prefix
+		addCondition(`call_number ILIKE ?`, filter.CallNumber+"%")
+	}
+	if filter.YearFrom != nil {
+		addCondition(`publication_year >= ?`, *filter.YearFrom)
+	}
+	if filter.YearTo != nil {
+		addCondition(`publication_year <= ?`, *filter.YearTo)
+	}
+	if filter.PagesMin != nil {
+		addCondition(`page_count >= ?`, *filter.PagesMin)
+	}
+	if filter.PagesMax != nil {
+		addCondition(`page_count <= ?`, *filter.PagesMax)
+	}
+	if filter.BranchCode != "" {
+		addCondition(`current_branch_id = (SELECT id FROM branches WHERE code = ?)`, filter.BranchCode)
+	}
+	if filter.HomeBranch != "" {
+		addCondition(`home_branch_id = (SELECT id FROM branches WHERE code = ?)`, filter.HomeBranch)
+	}
+	if filter.Available != nil {
+		addCondition(`available = ?`, *filter.Available)
+	}
+	if filter.ItemType != "" {
+		addCondition(`item_type = ?`, filter.ItemType)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return ` WHERE ` + strings.Join(conditions, " AND "), args
+}
+
+func (r *bookRepositoryImpl) SearchBooks(filter model.BookFilter) ([]model.Book, error) {
+	where, args := bookFilterConditions(filter)
+	query := `SELECT ` + bookSelectColumns + ` FROM books` + where + ` ORDER BY title`
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+	}
+
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search books: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("error closing rows in SearchBooks: %v", closeErr)
+		}
+	}()
+
+	books := make([]model.Book, 0)
+
+	for rows.Next() {
+		book := model.Book{}
+		if err := scanBook(rows, &book); err != nil {
 			return nil, fmt.Errorf("failed to scan book row: %w", err)
 		}
 		books = append(books, book)
@@ -137,3 +377,212 @@ func (r *bookRepositoryImpl) GetAllBooks() ([]model.Book, error) {
 
 	return books, nil
 }
+
+// GetFacets counts the books matching filter by subject, tag, author,
+// language, decade of publication and availability, ignoring its limit and
+// offset. Each facet keeps its limit most frequent values.
+func (r *bookRepositoryImpl) GetFacets(filter model.BookFilter, limit int) (*model.BookFacets, error) {
+	where, args := bookFilterConditions(filter)
+	matched := `WITH matched AS (SELECT id, tags, language, publication_year, available FROM books` + where + `) `
+	args = append(args, limit)
+	limitParam := fmt.Sprintf(`$%d`, len(args))
+
+	facets := &model.BookFacets{}
+	var err error
+
+	facets.Subjects, err = r.queryFacetValues(matched+`SELECT s.id, s.heading, COUNT(*) FROM matched
+		JOIN book_subjects bs ON bs.book_id = matched.id
+		JOIN subjects s ON s.id = bs.subject_id
+		GROUP BY s.id, s.heading ORDER BY COUNT(*) DESC, s.heading LIMIT `+limitParam, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count subject facet: %w", err)
+	}
+
+	facets.Authors, err = r.queryFacetValues(matched+`SELECT c.id, c.name, COUNT(DISTINCT matched.id) FROM matched
+		JOIN book_contributors bc ON bc.book_id = matched.id AND bc.role = 'author'
+		JOIN contributors c ON c.id = bc.contributor_id
+		GROUP BY c.id, c.name, c.sort_name ORDER BY COUNT(DISTINCT matched.id) DESC, c.sort_name LIMIT `+limitParam, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count author facet: %w", err)
+	}
+
+	facets.Tags, err = r.queryFacetValues(matched+`SELECT NULL::uuid, t.tag, COUNT(*) FROM matched, unnest(matched.tags) AS t(tag)
+		GROUP BY t.tag ORDER BY COUNT(*) DESC, t.tag LIMIT `+limitParam, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tag facet: %w", err)
+	}
+
+	facets.Languages, err = r.queryFacetValues(matched+`SELECT NULL::uuid, language, COUNT(*) FROM matched
+		WHERE language IS NOT NULL
+		GROUP BY language ORDER BY COUNT(*) DESC, language LIMIT `+limitParam, args)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count language facet: %w", err)
+	}
+
+	// the facet limit is not used by the remaining queries
+	args = args[:len(args)-1]
+
+	rows, err := r.db.Query(matched+`SELECT publication_year / 10 * 10, COUNT(*) FROM matched
+		WHERE publication_year IS NOT NULL
+		GROUP BY 1 ORDER BY 1`, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count year facet: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("error closing rows in GetFacets: %v", closeErr)
+		}
+	}()
+
+	facets.Years = make([]model.YearRange, 0)
+
+	for rows.Next() {
+		yearRange := model.YearRange{}
+		if err := rows.Scan(&yearRange.From, &yearRange.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan year facet row: %w", err)
+		}
+		yearRange.To = yearRange.From + 9
+		facets.Years = append(facets.Years, yearRange)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during year facet rows iteration: %w", err)
+	}
+
+	err = r.db.QueryRow(matched+`SELECT COUNT(*) FILTER (WHERE available), COUNT(*) FILTER (WHERE NOT available) FROM matched`, args...).
+		Scan(&facets.Availability.Available, &facets.Availability.Unavailable)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to count availability facet: %w", err)
+	}
+
+	return facets, nil
+}
+
+func (r *bookRepositoryImpl) queryFacetValues(query string, args []any) ([]model.FacetValue, error) {
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("error closing rows in queryFacetValues: %v", closeErr)
+		}
+	}()
+
+	values := make([]model.FacetValue, 0)
+
+	for rows.Next() {
+		value := model.FacetValue{}
+		if err := rows.Scan(&value.ID, &value.Value, &value.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan facet row: %w", err)
+		}
+		values = append(values, value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during facet rows iteration: %w", err)
+	}
+
+	return values, nil
+}
+
+// GetBranchAvailability counts the copies of a title (books sharing its ISBN) per current branch,
+// leaving out withdrawn copies.
+// Copies with an open transfer are unavailable and reported as in transit.
+func (r *bookRepositoryImpl) GetBranchAvailability(bookID uuid.UUID) ([]model.BranchAvailability, error) {
+	query := `SELECT br.id, COALESCE(br.code, ''), COALESCE(br.name, ''), COUNT(*),
+			COUNT(*) FILTER (WHERE b.available),
+			COUNT(t.id)
+		FROM books b
+		LEFT JOIN branches br ON br.id = b.current_branch_id
+		LEFT JOIN transfers t ON t.book_id = b.id AND t.status IN ('requested', 'in_transit')
+		WHERE b.isbn = (SELECT isbn FROM books WHERE id = $1) AND b.withdrawn_at IS NULL
+		GROUP BY br.id, br.code, br.name
+		ORDER BY br.code NULLS LAST`
+	rows, err := r.db.Query(query, bookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch availability for book ID %s: %w", bookID.String(), err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("error closing rows in GetBranchAvailability: %v", closeErr)
+		}
+	}()
+
+	availability := make([]model.BranchAvailability, 0)
+
+	for rows.Next() {
+		entry := model.BranchAvailability{}
+		if err := rows.Scan(&entry.BranchID, &entry.BranchCode, &entry.BranchName, &entry.Total, &entry.Available, &entry.InTransit); err != nil {
+			return nil, fmt.Errorf("failed to scan branch availability row: %w", err)
+		}
+		availability = append(availability, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during branch availability rows iteration: %w", err)
+	}
+
+	return availability, nil
+}
+
+// SetCoverUpdatedAt records when the book's cover was last uploaded; nil means it has none.
+func (r *bookRepositoryImpl) SetCoverUpdatedAt(id uuid.UUID, updatedAt *time.Time) error {
+	res, err := r.db.Exec(`UPDATE books SET cover_updated_at = $2 WHERE id = $1`, id, updatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update cover of book %s: %w", id.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating cover of book %s: %w", id.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("book with ID %s not found for cover update", id)
+	}
+
+	return nil
+}
+
+// SetWithdrawn saves the withdrawal of a book, or its reinstatement when
+// WithdrawnAt is nil, together with its availability.
+func (r *bookRepositoryImpl) SetWithdrawn(book *model.Book) error {
+	query := `UPDATE books SET withdrawn_at = $2, withdrawal_reason = $3, available = $4 WHERE id = $1`
+	res, err := r.db.Exec(query, book.ID, book.WithdrawnAt, nullIfEmpty(book.WithdrawalReason), book.Available)
+
+	if err != nil {
+		return fmt.Errorf("failed to update withdrawal of book %s: %w", book.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating withdrawal of book %s: %w", book.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("book with ID %s not found for withdrawal", book.ID)
+	}
+
+	return nil
+}
+
+func (r *bookRepositoryImpl) NextItemBarcodeSequence() (int64, error) {
+	var next int64
+	err := r.db.QueryRow(`SELECT nextval('item_barcode_seq')`).Scan(&next)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next item barcode sequence: %w", err)
+	}
+
+	return next, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type BranchRepository interface {
+	CreateBranch(branch *model.Branch) error
+	GetBranchByID(id uuid.UUID) (*model.Branch, error)
+	GetBranchByCode(code string) (*model.Branch, error)
+	UpdateBranch(branch *model.Branch) error
+	DeleteBranch(id uuid.UUID) error
+	GetAllBranches() ([]model.Branch, error)
+}
+
+type branchRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewBranchRepository(db *sql.DB) BranchRepository {
+	return &branchRepositoryImpl{db: db}
+}
+
+func (r *branchRepositoryImpl) CreateBranch(branch *model.Branch) error {
+	branch.ID = uuid.New()
+
+	query := `INSERT INTO branches (id, code, name, address) VALUES ($1, $2, $3, $4)`
+	_, err := r.db.Exec(query, branch.ID, branch.Code, branch.Name, branch.Address)
+
+	if err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", branch.Code, err)
+	}
+
+	return nil
+}
+
+func (r *branchRepositoryImpl) GetBranchByID(id uuid.UUID) (*model.Branch, error) {
+	branch := &model.Branch{}
+	query := `SELECT id, code, name, address FROM branches WHERE id = $1`
+	err := r.db.QueryRow(query, id).Scan(&branch.ID, &branch.Code, &branch.Name, &branch.Address)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get branch by ID %s: %w", id.String(), err)
+	}
+
+	return branch, nil
+}
+
+func (r *branchRepositoryImpl) GetBranchByCode(code string) (*model.Branch, error) {
+	branch := &model.Branch{}
+	query := `SELECT id, code, name, address FROM branches WHERE code = $1`
+	err := r.db.QueryRow(query, code).Scan(&branch.ID, &branch.Code, &branch.Name, &branch.Address)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get branch by code %s: %w", code, err)
+	}
+
+	return branch, nil
+}
+
+func (r *branchRepositoryImpl) UpdateBranch(branch *model.Branch) error {
+	query := `UPDATE branches SET code = $2, name = $3, address = $4 WHERE id = $1`
+	res, err := r.db.Exec(query, branch.ID, branch.Code, branch.Name, branch.Address)
+
+	if err != nil {
+		return fmt.Errorf("failed to update branch %s: %w", branch.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating branch %s: %w", branch.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("branch with ID %s not found for update", branch.ID)
+	}
+
+	return nil
+}
+
+func (r *branchRepositoryImpl) DeleteBranch(id uuid.UUID) error {
+	query := `DELETE FROM branches WHERE id = $1`
+	res, err := r.db.Exec(query, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", id.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after deleting branch %s: %w", id.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("branch with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
+
+func (r *branchRepositoryImpl) GetAllBranches() ([]model.Branch, error) {
+	query := `SELECT id, code, name, address FROM branches ORDER BY code`
+	rows, err := r.db.Query(query)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query branches: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting branches: %v", closeErr)
+		}
+	}()
+
+	branches := make([]model.Branch, 0)
+
+	for rows.Next() {
+		branch := model.Branch{}
+		if err := rows.Scan(&branch.ID, &branch.Code, &branch.Name, &branch.Address); err != nil {
+			return nil, fmt.Errorf("failed to scan branch row: %w", err)
+		}
+		branches = append(branches, branch)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during branch rows iteration: %w", err)
+	}
+
+	return branches, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type CirculationRuleRepository interface {
+	CreateRule(rule *model.CirculationRule) error
+	GetRuleByID(id uuid.UUID) (*model.CirculationRule, error)
+	UpdateRule(rule *model.CirculationRule) error
+	DeleteRule(id uuid.UUID) error
+	GetAllRules() ([]model.CirculationRule, error)
+	GetMatchingRules(category, itemType, branchCode string) ([]model.CirculationRule, error)
+}
+
+const circulationRuleColumns = `id, patron_category, item_type, branch_code, loan_period_days, max_renewals, fine_per_day_cents, holds_allowed, non_circulating`
+
+func scanCirculationRule(row rowScanner, rule *model.CirculationRule) error {
+	return row.Scan(&rule.ID, &rule.PatronCategory, &rule.ItemType, &rule.BranchCode, &rule.LoanPeriodDays, &rule.MaxRenewals, &rule.FinePerDayCents, &rule.HoldsAllowed, &rule.NonCirculating)
+}
+
+type circulationRuleRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewCirculationRuleRepository(db *sql.DB) CirculationRuleRepository {
+	return &circulationRuleRepositoryImpl{db: db}
+}
+
+func (r *circulationRuleRepositoryImpl) CreateRule(rule *model.CirculationRule) error {
+	rule.ID = uuid.New()
+
+	query := `INSERT INTO circulation_rules (` + circulationRuleColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.db.Exec(query, rule.ID, rule.PatronCategory, rule.ItemType, rule.BranchCode, rule.LoanPeriodDays, rule.MaxRenewals, rule.FinePerDayCents, rule.HoldsAllowed, rule.NonCirculating)
+
+	if err != nil {
+		return fmt.Errorf("failed to create circulation rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *circulationRuleRepositoryImpl) GetRuleByID(id uuid.UUID) (*model.CirculationRule, error) {
+	rule := &model.CirculationRule{}
+	query := `SELECT ` + circulationRuleColumns + ` FROM circulation_rules WHERE id = $1`
+	err := scanCirculationRule(r.db.QueryRow(query, id), rule)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get circulation rule by ID %s: %w", id.String(), err)
+	}
+
+	return rule, nil
+}
+
+func (r *circulationRuleRepositoryImpl) UpdateRule(rule *model.CirculationRule) error {
+	query := `UPDATE circulation_rules SET patron_category = $2, item_type = $3, branch_code = $4, loan_period_days = $5, max_renewals = $6, fine_per_day_cents = $7, holds_allowed = $8, non_circulating = $9 WHERE id = $1`
+	res, err := r.db.Exec(query, rule.ID, rule.PatronCategory, rule.ItemType, rule.BranchCode, rule.LoanPeriodDays, rule.MaxRenewals, rule.FinePerDayCents, rule.HoldsAllowed, rule.NonCirculating)
+
+	if err != nil {
+		return fmt.Errorf("failed to update circulation rule %s: %w", rule.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating circulation rule %s: %w", rule.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("circulation rule with ID %s not found for update", rule.ID)
+	}
+
+	return nil
+}
+
+func (r *circulationRuleRepositoryImpl) DeleteRule(id uuid.UUID) error {
+	query := `DELETE FROM circulation_rules WHERE id = $1`
+	res, err := r.db.Exec(query, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete circulation rule %s: %w", id.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after deleting circulation rule %s: %w", id.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("circulation rule with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
+
+func (r *circulationRuleRepositoryImpl) GetAllRules() ([]model.CirculationRule, error) {
+	query := `SELECT ` + circulationRuleColumns + ` FROM circulation_rules ORDER BY patron_category NULLS LAST, item_type NULLS LAST, branch_code NULLS LAST`
+	return r.queryRules(query)
+}
+
+func (r *circulationRuleRepositoryImpl) GetMatchingRules(category, itemType, branchCode string) ([]model.CirculationRule, error) {
+	query := `SELECT ` + circulationRuleColumns + ` FROM circulation_rules
+		WHERE (patron_category IS NULL OR patron_category = $1)
+		AND (item_type IS NULL OR item_type = $2)
+		AND (branch_code IS NULL OR branch_code = $3)
+		ORDER BY (CASE WHEN patron_category IS NULL THEN 0 ELSE 4 END
+			+ CASE WHEN item_type IS NULL THEN 0 ELSE 2 END
+			+ CASE WHEN branch_code IS NULL THEN 0 ELSE 1 END) DESC, id`
+	return r.queryRules(query, category, itemType, branchCode)
+}
+
+func (r *circulationRuleRepositoryImpl) queryRules(query string, args ...any) ([]model.CirculationRule, error) {
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query circulation rules: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after querying circulation rules: %v", closeErr)
+		}
+	}()
+
+	rules := make([]model.CirculationRule, 0)
+
+	for rows.Next() {
+		rule := model.CirculationRule{}
+		if err := scanCirculationRule(rows, &rule); err != nil {
+			return nil, fmt.Errorf("failed to scan circulation rule row: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during circulation rule rows iteration: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type ContributorRepository interface {
+	WithTx(tx *sql.Tx) ContributorRepository
+	UpsertContributor(contributor *model.Contributor) error
+	GetContributorByID(id uuid.UUID) (*model.Contributor, error)
+	SearchContributors(query string, limit int) ([]model.Contributor, error)
+	SetBookContributors(bookID uuid.UUID, contributors []model.BookContributor) error
+	GetBookContributors(bookIDs []uuid.UUID) (map[uuid.UUID][]model.BookContributor, error)
+	GetWorks(contributorID uuid.UUID) ([]model.ContributorWork, error)
+}
+
+const contributorColumns = `id, name, sort_name, created_at,
+	(SELECT COUNT(DISTINCT bc.book_id) FROM book_contributors bc WHERE bc.contributor_id = contributors.id)`
+
+func scanContributor(row rowScanner, contributor *model.Contributor) error {
+	return row.Scan(&contributor.ID, &contributor.Name, &contributor.SortName, &contributor.CreatedAt, &contributor.BookCount)
+}
+
+type contributorRepositoryImpl struct {
+	db DBTX
+}
+
+func NewContributorRepository(db *sql.DB) ContributorRepository {
+	return &contributorRepositoryImpl{db: db}
+}
+
+func (r *contributorRepositoryImpl) WithTx(tx *sql.Tx) ContributorRepository {
+	return &contributorRepositoryImpl{db: tx}
+}
+
+// UpsertContributor finds the contributor whose name has the same key
+// (ignoring case, accents and punctuation) or creates it, and fills in the
+// stored contributor. An existing contributor keeps its name.
+func (r *contributorRepositoryImpl) UpsertContributor(contributor *model.Contributor) error {
+	query := `INSERT INTO contributors (id, name, sort_name, name_key, created_at)
+		VALUES ($1, $2, $3, contributor_name_key($2), CURRENT_TIMESTAMP)
+		ON CONFLICT (name_key) DO UPDATE SET name_key = EXCLUDED.name_key
+		RETURNING id, name, sort_name, created_at`
+	err := r.db.QueryRow(query, uuid.New(), contributor.Name, contributor.SortName).Scan(&contributor.ID, &contributor.Name, &contributor.SortName, &contributor.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to save contributor %s: %w", contributor.Name, err)
+	}
+
+	return nil
+}
+
+func (r *contributorRepositoryImpl) GetContributorByID(id uuid.UUID) (*model.Contributor, error) {
+	contributor := &model.Contributor{}
+	query := `SELECT ` + contributorColumns + ` FROM contributors WHERE id = $1`
+	err := scanContributor(r.db.QueryRow(query, id), contributor)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get contributor by ID %s: %w", id.String(), err)
+	}
+
+	return contributor, nil
+}
+
+// SearchContributors lists contributors in sort order, optionally only those
+// whose name contains query.
+func (r *contributorRepositoryImpl) SearchContributors(query string, limit int) ([]model.Contributor, error) {
+	sqlQuery := `SELECT ` + contributorColumns + ` FROM contributors
+		WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR name_key LIKE '%' || contributor_name_key($1) || '%'
+		ORDER BY sort_name LIMIT $2`
+	rows, err := r.db.Query(sqlQuery, query, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search contributors: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after searching contributors: %v", closeErr)
+		}
+	}()
+
+	contributors := make([]model.Contributor, 0)
+
+	for rows.Next() {
+		contributor := model.Contributor{}
+		if err := scanContributor(rows, &contributor); err != nil {
+			return nil, fmt.Errorf("failed to scan contributor row: %w", err)
+		}
+		contributors = append(contributors, contributor)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during contributor rows iteration: %w", err)
+	}
+
+	return contributors, nil
+}
+
+// SetBookContributors replaces the credits of a book, keeping the given order.
+func (r *contributorRepositoryImpl) SetBookContributors(bookID uuid.UUID, contributors []model.BookContributor) error {
+	if _, err := r.db.Exec(`DELETE FROM book_contributors WHERE book_id = $1`, bookID); err != nil {
+		return fmt.Errorf("failed to clear contributors of book %s: %w", bookID.String(), err)
+	}
+
+	for position, contributor := range contributors {
+		query := `INSERT INTO book_contributors (book_id, contributor_id, role, position) VALUES ($1, $2, $3, $4) ON CONFLICT DO NOTHING`
+		if _, err := r.db.Exec(query, bookID, contributor.ID, contributor.Role, position); err != nil {
+			return fmt.Errorf("failed to add contributor %s to book %s: %w", contributor.ID.String(), bookID.String(), err)
+		}
+	}
+
+	return nil
+}
+
+// GetBookContributors loads the credits of several books at once, keyed by book ID.
+func (r *contributorRepositoryImpl) GetBookContributors(bookIDs []uuid.UUID) (map[uuid.UUID][]model.BookContributor, error) {
+	query := `SELECT bc.book_id, c.id, c.name, c.sort_name, bc.role
+		FROM book_contributors bc
+		JOIN contributors c ON c.id = bc.contributor_id
+		WHERE bc.book_id = ANY($1)
+		ORDER BY bc.book_id, bc.position`
+	rows, err := r.db.Query(query, pq.Array(bookIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book contributors: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting book contributors: %v", closeErr)
+		}
+	}()
+
+	contributors := make(map[uuid.UUID][]model.BookContributor)
+
+	for rows.Next() {
+		var bookID uuid.UUID
+		contributor := model.BookContributor{}
+		if err := rows.Scan(&bookID, &contributor.ID, &contributor.Name, &contributor.SortName, &contributor.Role); err != nil {
+			return nil, fmt.Errorf("failed to scan book contributor row: %w", err)
+		}
+		contributors[bookID] = append(contributors[bookID], contributor)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during book contributor rows iteration: %w", err)
+	}
+
+	return contributors, nil
+}
+
+// GetWorks returns the bibliography of a contributor, newest first. A book
+// appears once per role the contributor had on it.
+func (r *contributorRepositoryImpl) GetWorks(contributorID uuid.UUID) ([]model.ContributorWork, error) {
+	query := `SELECT ` + bookSelectColumns + `, bc.role
+		FROM books
+		JOIN book_contributors bc ON bc.book_id = books.id
+		WHERE bc.contributor_id = $1
+		ORDER BY books.publication_year DESC NULLS LAST, books.title`
+	rows, err := r.db.Query(query, contributorID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get works of contributor %s: %w", contributorID.String(), err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting contributor works: %v", closeErr)
+		}
+	}()
+
+	works := make([]model.ContributorWork, 0)
+
+	for rows.Next() {
+		work := model.ContributorWork{}
+		if err := scanBook(extraColumns{row: rows, extra: []any{&work.Role}}, &work.Book); err != nil {
+			return nil, fmt.Errorf("failed to scan contributor work row: %w", err)
+		}
+		works = append(works, work)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during contributor work rows iteration: %w", err)
+	}
+
+	return works, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type FineRepository interface {
+	WithTx(tx *sql.Tx) FineRepository
+	CreateFine(fine *model.Fine) error
+	GetFineByID(id uuid.UUID) (*model.Fine, error)
+	UpdateFine(fine *model.Fine) error
+	GetFinesByUserID(userID uuid.UUID) ([]model.Fine, error)
+	DetachAnonymizedLoans() (int64, error)
+}
+
+const fineColumns = `id, loan_id, user_id, days_late, amount_cents, paid, created_at, paid_at`
+
+func scanFine(row rowScanner, fine *model.Fine) error {
+	return row.Scan(&fine.ID, &fine.LoanID, &fine.UserID, &fine.DaysLate, &fine.AmountCents, &fine.Paid, &fine.CreatedAt, &fine.PaidAt)
+}
+
+type fineRepositoryImpl struct {
+	db DBTX
+}
+
+func NewFineRepository(db *sql.DB) FineRepository {
+	return &fineRepositoryImpl{db: db}
+}
+
+func (r *fineRepositoryImpl) WithTx(tx *sql.Tx) FineRepository {
+	return &fineRepositoryImpl{db: tx}
+}
+
+func (r *fineRepositoryImpl) CreateFine(fine *model.Fine) error {
+	fine.ID = uuid.New()
+	fine.CreatedAt = time.Now()
+
+	query := `INSERT INTO fines (` + fineColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.Exec(query, fine.ID, fine.LoanID, fine.UserID, fine.DaysLate, fine.AmountCents, fine.Paid, fine.CreatedAt, fine.PaidAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create fine for user ID %s: %w", fine.UserID.String(), err)
+	}
+
+	return nil
+}
+
+func (r *fineRepositoryImpl) GetFineByID(id uuid.UUID) (*model.Fine, error) {
+	fine := &model.Fine{}
+	query := `SELECT ` + fineColumns + ` FROM fines WHERE id = $1`
+	err := scanFine(r.db.QueryRow(query, id), fine)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get fine by ID %s: %w", id.String(), err)
+	}
+
+	return fine, nil
+}
+
+func (r *fineRepositoryImpl) UpdateFine(fine *model.Fine) error {
+	query := `UPDATE fines SET paid = $2, paid_at = $3 WHERE id = $1`
+	res, err := r.db.Exec(query, fine.ID, fine.Paid, fine.PaidAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update fine %s: %w", fine.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating fine %s: %w", fine.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("fine with ID %s not found for update", fine.ID)
+	}
+
+	return nil
+}
+
+func (r *fineRepositoryImpl) GetFinesByUserID(userID uuid.UUID) ([]model.Fine, error) {
+	query := `SELECT ` + fineColumns + ` FROM fines WHERE user_id = $1 ORDER BY created_at`
+	rows, err := r.db.Query(query, userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fines for user ID %s: %w", userID.String(), err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting fines by user ID %s: %v", userID.String(), closeErr)
+		}
+	}()
+
+	fines := make([]model.Fine, 0)
+
+	for rows.Next() {
+		fine := model.Fine{}
+		if err := scanFine(rows, &fine); err != nil {
+			return nil, fmt.Errorf("failed to scan fine row for user ID %s: %w", userID.String(), err)
+		}
+		fines = append(fines, fine)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during fine rows iteration for user ID %s: %w", userID.String(), err)
+	}
+
+	return fines, nil
+}
+
+// DetachAnonymizedLoans drops the loan from the fines of anonymized loans, so
+// a fine kept for accounting no longer points at the book the patron read.
+func (r *fineRepositoryImpl) DetachAnonymizedLoans() (int64, error) {
+	query := `UPDATE fines SET loan_id = NULL FROM loans WHERE fines.loan_id = loans.id AND loans.anonymized_at IS NOT NULL`
+	res, err := r.db.Exec(query)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to detach fines from anonymized loans: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type HoldRepository interface {
+	WithTx(tx *sql.Tx) HoldRepository
+	CreateHold(hold *model.Hold) error
+	GetHoldByID(id uuid.UUID) (*model.Hold, error)
+	UpdateHold(hold *model.Hold) error
+	GetHoldsByUserID(userID uuid.UUID) ([]model.Hold, error)
+	GetHoldsByBookID(bookID uuid.UUID) ([]model.Hold, error)
+	CountActiveHoldsByUserID(userID uuid.UUID) (int, error)
+	GetNextWaitingHold(bookID uuid.UUID) (*model.Hold, error)
+	GetNextWaitingHoldForTitle(bookID uuid.UUID) (*model.Hold, error)
+	GetHoldsForTitle(bookID uuid.UUID) ([]model.Hold, error)
+	GetReadyHold(bookID uuid.UUID) (*model.Hold, error)
+	GetExpiredHolds(now time.Time) ([]model.Hold, error)
+	DeleteHoldsByUserID(userID uuid.UUID) (int64, error)
+}
+
+const holdColumns = `id, user_id, book_id, status, created_at, ready_at, expires_at, pickup_branch_id`
+
+func scanHold(row rowScanner, hold *model.Hold) error {
+	return row.Scan(&hold.ID, &hold.UserID, &hold.BookID, &hold.Status, &hold.CreatedAt, &hold.ReadyAt, &hold.ExpiresAt, &hold.PickupBranchID)
+}
+
+type holdRepositoryImpl struct {
+	db DBTX
+}
+
+func NewHoldRepository(db *sql.DB) HoldRepository {
+	return &holdRepositoryImpl{db: db}
+}
+
+func (r *holdRepositoryImpl) WithTx(tx *sql.Tx) HoldRepository {
+	return &holdRepositoryImpl{db: tx}
+}
+
+func (r *holdRepositoryImpl) CreateHold(hold *model.Hold) error {
+	hold.ID = uuid.New()
+	hold.CreatedAt = time.Now()
+
+	if hold.Status == "" {
+		hold.Status = model.HoldStatusWaiting
+	}
+
+	query := `INSERT INTO holds (` + holdColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := r.db.Exec(query, hold.ID, hold.UserID, hold.BookID, hold.Status, hold.CreatedAt, hold.ReadyAt, hold.ExpiresAt, hold.PickupBranchID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create hold for user ID %s and book ID %s: %w", hold.UserID.String(), hold.BookID.String(), err)
+	}
+
+	return nil
+}
+
+func (r *holdRepositoryImpl) GetHoldByID(id uuid.UUID) (*model.Hold, error) {
+	hold := &model.Hold{}
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE id = $1`
+	err := scanHold(r.db.QueryRow(query, id), hold)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get hold by ID %s: %w", id.String(), err)
+	}
+
+	return hold, nil
+}
+
+func (r *holdRepositoryImpl) UpdateHold(hold *model.Hold) error {
+	query := `UPDATE holds SET status = $2, ready_at = $3, expires_at = $4, book_id = $5 WHERE id = $1`
+	res, err := r.db.Exec(query, hold.ID, hold.Status, hold.ReadyAt, hold.ExpiresAt, hold.BookID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update hold %s: %w", hold.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating hold %s: %w", hold.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("hold with ID %s not found for update", hold.ID)
+	}
+
+	return nil
+}
+
+func (r *holdRepositoryImpl) GetHoldsByUserID(userID uuid.UUID) ([]model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE user_id = $1 ORDER BY created_at`
+	return r.queryHolds(query, userID)
+}
+
+func (r *holdRepositoryImpl) GetHoldsByBookID(bookID uuid.UUID) ([]model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE book_id = $1 ORDER BY created_at`
+	return r.queryHolds(query, bookID)
+}
+
+// titleCopies selects the IDs of every copy sharing the ISBN of the book in $1.
+const titleCopies = `SELECT id FROM books WHERE isbn = (SELECT isbn FROM books WHERE id = $1)`
+
+// GetHoldsForTitle returns the holds on any copy of the book's title.
+func (r *holdRepositoryImpl) GetHoldsForTitle(bookID uuid.UUID) ([]model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE book_id IN (` + titleCopies + `) ORDER BY created_at`
+	return r.queryHolds(query, bookID)
+}
+
+func (r *holdRepositoryImpl) CountActiveHoldsByUserID(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM holds WHERE user_id = $1 AND status IN ('waiting', 'ready')`
+	err := r.db.QueryRow(query, userID).Scan(&count)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active holds for user ID %s: %w", userID.String(), err)
+	}
+
+	return count, nil
+}
+
+func (r *holdRepositoryImpl) GetNextWaitingHold(bookID uuid.UUID) (*model.Hold, error) {
+	return r.getHoldByStatus(bookID, model.HoldStatusWaiting)
+}
+
+// GetNextWaitingHoldForTitle returns the oldest waiting hold on any copy of
+// the book's title, so the first copy to come back serves the queue. Holds a
+// copy is already being shipped to are skipped.
+func (r *holdRepositoryImpl) GetNextWaitingHoldForTitle(bookID uuid.UUID) (*model.Hold, error) {
+	hold := &model.Hold{}
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE book_id IN (` + titleCopies + `) AND status = 'waiting'
+		AND NOT EXISTS (SELECT 1 FROM transfers t WHERE t.hold_id = holds.id AND t.status IN ('requested', 'in_transit'))
+		ORDER BY created_at LIMIT 1`
+	err := scanHold(r.db.QueryRow(query, bookID), hold)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get waiting hold for title of book ID %s: %w", bookID.String(), err)
+	}
+
+	return hold, nil
+}
+
+func (r *holdRepositoryImpl) GetReadyHold(bookID uuid.UUID) (*model.Hold, error) {
+	return r.getHoldByStatus(bookID, model.HoldStatusReady)
+}
+
+// GetExpiredHolds returns the holds left on the hold shelf past their pickup deadline.
+func (r *holdRepositoryImpl) GetExpiredHolds(now time.Time) ([]model.Hold, error) {
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE status = 'ready' AND expires_at < $1 ORDER BY expires_at`
+	return r.queryHolds(query, now)
+}
+
+func (r *holdRepositoryImpl) getHoldByStatus(bookID uuid.UUID, status string) (*model.Hold, error) {
+	hold := &model.Hold{}
+	query := `SELECT ` + holdColumns + ` FROM holds WHERE book_id = $1 AND status = $2 ORDER BY created_at LIMIT 1`
+	err := scanHold(r.db.QueryRow(query, bookID, status), hold)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get %s hold for book ID %s: %w", status, bookID.String(), err)
+	}
+
+	return hold, nil
+}
+
+func (r *holdRepositoryImpl) queryHolds(query string, args ...any) ([]model.Hold, error) {
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query holds: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after querying holds: %v", closeErr)
+		}
+	}()
+
+	holds := make([]model.Hold, 0)
+
+	for rows.Next() {
+		hold := model.Hold{}
+		if err := scanHold(rows, &hold); err != nil {
+			return nil, fmt.Errorf("failed to scan hold row: %w", err)
+		}
+		holds = append(holds, hold)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during hold rows iteration: %w", err)
+	}
+
+	return holds, nil
+}
+
+func (r *holdRepositoryImpl) DeleteHoldsByUserID(userID uuid.UUID) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM holds WHERE user_id = $1`, userID)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete holds of user ID %s: %w", userID.String(), err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"lib_backend/internal/model"
+)
+
+type IdempotencyRepository interface {
+	Reserve(scope string, key string, requestHash string, expiredBefore time.Time, staleBefore time.Time) (bool, error)
+	GetRecord(scope string, key string) (*model.IdempotencyRecord, error)
+	Complete(scope string, key string, statusCode int, contentType string, body []byte) error
+	Release(scope string, key string) error
+	PurgeBefore(before time.Time) (int64, error)
+}
+
+const idempotencyColumns = `scope, key, request_hash, status_code, content_type, response_body, created_at, completed_at`
+
+func scanIdempotencyRecord(row rowScanner, record *model.IdempotencyRecord) error {
+	var contentType sql.NullString
+
+	err := row.Scan(&record.Scope, &record.Key, &record.RequestHash, &record.StatusCode, &contentType, &record.ResponseBody, &record.CreatedAt, &record.CompletedAt)
+
+	if err != nil {
+		return err
+	}
+
+	record.ContentType = contentType.String
+	return nil
+}
+
+type idempotencyRepositoryImpl struct {
+	db DBTX
+}
+
+func NewIdempotencyRepository(db *sql.DB) IdempotencyRepository {
+	return &idempotencyRepositoryImpl{db: db}
+}
+
+// Reserve claims key within scope for a new request and reports whether it did. A key is
+// also claimed when its record expired, or when the same request started
+// before staleBefore and never finished.
+func (r *idempotencyRepositoryImpl) Reserve(scope string, key string, requestHash string, expiredBefore time.Time, staleBefore time.Time) (bool, error) {
+	query := `INSERT INTO idempotency_keys (scope, key, request_hash, created_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (scope, key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status_code = NULL, content_type = NULL,
+			response_body = NULL, created_at = EXCLUDED.created_at, completed_at = NULL
+		WHERE idempotency_keys.created_at < $4
+			OR (idempotency_keys.completed_at IS NULL AND idempotency_keys.created_at < $5 AND idempotency_keys.request_hash = EXCLUDED.request_hash)
+		RETURNING key`
+
+	var reserved string
+	err := r.db.QueryRow(query, scope, key, requestHash, expiredBefore, staleBefore).Scan(&reserved)
+
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key %s: %w", key, err)
+	}
+
+	return true, nil
+}
+
+func (r *idempotencyRepositoryImpl) GetRecord(scope string, key string) (*model.IdempotencyRecord, error) {
+	record := &model.IdempotencyRecord{}
+	query := `SELECT ` + idempotencyColumns + ` FROM idempotency_keys WHERE scope = $1 AND key = $2`
+	err := scanIdempotencyRecord(r.db.QueryRow(query, scope, key), record)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get idempotency key %s: %w", key, err)
+	}
+
+	return record, nil
+}
+
+func (r *idempotencyRepositoryImpl) Complete(scope string, key string, statusCode int, contentType string, body []byte) error {
+	query := `UPDATE idempotency_keys SET status_code = $3, content_type = $4, response_body = $5, completed_at = CURRENT_TIMESTAMP
+		WHERE scope = $1 AND key = $2`
+	res, err := r.db.Exec(query, scope, key, statusCode, nullIfEmpty(contentType), body)
+
+	if err != nil {
+		return fmt.Errorf("failed to store response of idempotency key %s: %w", key, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after storing response of idempotency key %s: %w", key, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("idempotency key %s not found for update", key)
+	}
+
+	return nil
+}
+
+// Release forgets a request that did not finish, so the key can be retried.
+func (r *idempotencyRepositoryImpl) Release(scope string, key string) error {
+	if _, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2 AND completed_at IS NULL`, scope, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key %s: %w", key, err)
+	}
+
+	return nil
+}
+
+func (r *idempotencyRepositoryImpl) PurgeBefore(before time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM idempotency_keys WHERE created_at < $1`, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	return res.RowsAffected()
+}
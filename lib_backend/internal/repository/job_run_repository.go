@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type JobRunRepository interface {
+	CreateRun(run *model.JobRun) error
+	UpdateRun(run *model.JobRun) error
+	GetRunsByJobName(jobName string, limit int) ([]model.JobRun, error)
+	GetLastRun(jobName string) (*model.JobRun, error)
+	PurgeRunsBefore(before time.Time) (int64, error)
+}
+
+const jobRunColumns = `id, job_name, trigger, status, attempts, result, error, started_at, finished_at`
+
+func scanJobRun(row rowScanner, run *model.JobRun) error {
+	return row.Scan(&run.ID, &run.JobName, &run.Trigger, &run.Status, &run.Attempts, &run.Result, &run.Error, &run.StartedAt, &run.FinishedAt)
+}
+
+type jobRunRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewJobRunRepository(db *sql.DB) JobRunRepository {
+	return &jobRunRepositoryImpl{db: db}
+}
+
+func (r *jobRunRepositoryImpl) CreateRun(run *model.JobRun) error {
+	run.ID = uuid.New()
+	run.StartedAt = time.Now()
+
+	query := `INSERT INTO job_runs (` + jobRunColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.db.Exec(query, run.ID, run.JobName, run.Trigger, run.Status, run.Attempts, run.Result, run.Error, run.StartedAt, run.FinishedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create run of job %s: %w", run.JobName, err)
+	}
+
+	return nil
+}
+
+func (r *jobRunRepositoryImpl) UpdateRun(run *model.JobRun) error {
+	query := `UPDATE job_runs SET status = $2, attempts = $3, result = $4, error = $5, finished_at = $6 WHERE id = $1`
+	res, err := r.db.Exec(query, run.ID, run.Status, run.Attempts, run.Result, run.Error, run.FinishedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update job run %s: %w", run.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating job run %s: %w", run.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("job run with ID %s not found for update", run.ID)
+	}
+
+	return nil
+}
+
+func (r *jobRunRepositoryImpl) GetRunsByJobName(jobName string, limit int) ([]model.JobRun, error) {
+	query := `SELECT ` + jobRunColumns + ` FROM job_runs WHERE job_name = $1 ORDER BY started_at DESC LIMIT $2`
+	rows, err := r.db.Query(query, jobName, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get runs of job %s: %w", jobName, err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting runs of job %s: %v", jobName, closeErr)
+		}
+	}()
+
+	runs := make([]model.JobRun, 0)
+
+	for rows.Next() {
+		run := model.JobRun{}
+		if err := scanJobRun(rows, &run); err != nil {
+			return nil, fmt.Errorf("failed to scan job run row for job %s: %w", jobName, err)
+		}
+		runs = append(runs, run)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during job run rows iteration for job %s: %w", jobName, err)
+	}
+
+	return runs, nil
+}
+
+func (r *jobRunRepositoryImpl) GetLastRun(jobName string) (*model.JobRun, error) {
+	run := &model.JobRun{}
+	query := `SELECT ` + jobRunColumns + ` FROM job_runs WHERE job_name = $1 ORDER BY started_at DESC LIMIT 1`
+	err := scanJobRun(r.db.QueryRow(query, jobName), run)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get last run of job %s: %w", jobName, err)
+	}
+
+	return run, nil
+}
+
+func (r *jobRunRepositoryImpl) PurgeRunsBefore(before time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM job_runs WHERE started_at < $1 AND status <> 'running'`, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge job runs: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -12,6 +12,7 @@ import (
 )
 
 type LoanRepository interface {
+	WithTx(tx *sql.Tx) LoanRepository
 	CreateLoan(loan *model.Loan) error
 	GetLoanByID(id uuid.UUID) (*model.Loan, error)
 	GetLoansByUserID(userID uuid.UUID) ([]model.Loan, error)
@@ -19,22 +20,40 @@ type LoanRepository interface {
 	UpdateLoan(loan *model.Loan) error
 	DeleteLoan(id uuid.UUID) error
 	GetAllLoans() ([]model.Loan, error)
+	CountActiveLoansByUserID(userID uuid.UUID) (int, error)
+	GetActiveLoanByBookID(bookID uuid.UUID) (*model.Loan, error)
+	GetActiveLoansDueBetween(from, to time.Time) ([]model.Loan, error)
+	AnonymizeReturnedLoans(returnedBefore time.Time) (int64, error)
+	AnonymizeLoansByUserID(userID uuid.UUID) (int64, error)
+}
+
+const loanColumns = `id, user_id, book_id, loaned_at, due_at, returned, returned_at, renewals, circulation_rule_id, checkout_branch_id, return_branch_id, overdue_at, anonymized_at`
+
+func scanLoan(row rowScanner, loan *model.Loan) error {
+	return row.Scan(&loan.ID, &loan.UserID, &loan.BookID, &loan.LoanedAt, &loan.DueAt, &loan.Returned, &loan.ReturnedAt, &loan.Renewals, &loan.RuleID, &loan.CheckoutBranchID, &loan.ReturnBranchID, &loan.OverdueAt, &loan.AnonymizedAt)
 }
 
 type loanRepositoryImpl struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewLoanRepository(db *sql.DB) LoanRepository {
 	return &loanRepositoryImpl{db: db}
 }
 
+func (r *loanRepositoryImpl) WithTx(tx *sql.Tx) LoanRepository {
+	return &loanRepositoryImpl{db: tx}
+}
+
 func (r *loanRepositoryImpl) CreateLoan(loan *model.Loan) error {
 	loan.ID = uuid.New()
-	loan.LoanedAt = time.Now()
 
-	query := `INSERT INTO loans (id, user_id, book_id, loaned_at, returned) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.Exec(query, loan.ID, loan.UserID, loan.BookID, loan.LoanedAt, loan.Returned)
+	if loan.LoanedAt.IsZero() {
+		loan.LoanedAt = time.Now()
+	}
+
+	query := `INSERT INTO loans (id, user_id, book_id, loaned_at, due_at, returned, renewals, circulation_rule_id, checkout_branch_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err := r.db.Exec(query, loan.ID, loan.UserID, loan.BookID, loan.LoanedAt, loan.DueAt, loan.Returned, loan.Renewals, loan.RuleID, loan.CheckoutBranchID)
 
 	if err != nil {
 		return fmt.Errorf("failed to create loan for user ID %s and book ID %s: %w", loan.UserID.String(), loan.BookID.String(), err)
@@ -45,8 +64,8 @@ func (r *loanRepositoryImpl) CreateLoan(loan *model.Loan) error {
 
 func (r *loanRepositoryImpl) GetLoanByID(id uuid.UUID) (*model.Loan, error) {
 	loan := &model.Loan{}
-	query := `SELECT id, user_id, book_id, loaned_at, returned FROM loans WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&loan.ID, &loan.UserID, &loan.BookID, &loan.LoanedAt, &loan.Returned)
+	query := `SELECT ` + loanColumns + ` FROM loans WHERE id = $1`
+	err := scanLoan(r.db.QueryRow(query, id), loan)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -57,8 +76,22 @@ func (r *loanRepositoryImpl) GetLoanByID(id uuid.UUID) (*model.Loan, error) {
 	return loan, nil
 }
 
+func (r *loanRepositoryImpl) GetActiveLoanByBookID(bookID uuid.UUID) (*model.Loan, error) {
+	loan := &model.Loan{}
+	query := `SELECT ` + loanColumns + ` FROM loans WHERE book_id = $1 AND returned = FALSE ORDER BY loaned_at DESC LIMIT 1`
+	err := scanLoan(r.db.QueryRow(query, bookID), loan)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get active loan for book ID %s: %w", bookID.String(), err)
+	}
+
+	return loan, nil
+}
+
 func (r *loanRepositoryImpl) GetLoansByUserID(userID uuid.UUID) ([]model.Loan, error) {
-	query := `SELECT id, user_id, book_id, loaned_at, returned FROM loans WHERE user_id = $1`
+	query := `SELECT ` + loanColumns + ` FROM loans WHERE user_id = $1`
 	rows, err := r.db.Query(query, userID)
 
 	if err != nil {
@@ -76,7 +109,7 @@ func (r *loanRepositoryImpl) GetLoansByUserID(userID uuid.UUID) ([]model.Loan, e
 	for rows.Next() {
 		loan := model.Loan{}
 
-		if err := rows.Scan(&loan.ID, &loan.UserID, &loan.BookID, &loan.LoanedAt, &loan.Returned); err != nil {
+		if err := scanLoan(rows, &loan); err != nil {
 			return nil, fmt.Errorf("failed to scan loan row for user ID %s: %w", userID.String(), err)
 		}
 		loans = append(loans, loan)
@@ -90,7 +123,7 @@ func (r *loanRepositoryImpl) GetLoansByUserID(userID uuid.UUID) ([]model.Loan, e
 }
 
 func (r *loanRepositoryImpl) GetLoansByBookID(bookID uuid.UUID) ([]model.Loan, error) {
-	query := `SELECT id, user_id, book_id, loaned_at, returned FROM loans WHERE book_id = $1`
+	query := `SELECT ` + loanColumns + ` FROM loans WHERE book_id = $1`
 	rows, err := r.db.Query(query, bookID)
 
 	if err != nil {
@@ -108,7 +141,7 @@ func (r *loanRepositoryImpl) GetLoansByBookID(bookID uuid.UUID) ([]model.Loan, e
 	for rows.Next() {
 		loan := model.Loan{}
 
-		if err := rows.Scan(&loan.ID, &loan.UserID, &loan.BookID, &loan.LoanedAt, &loan.Returned); err != nil {
+		if err := scanLoan(rows, &loan); err != nil {
 			return nil, fmt.Errorf("failed to scan loan row for book ID %s: %w", bookID.String(), err)
 		}
 
@@ -123,8 +156,8 @@ func (r *loanRepositoryImpl) GetLoansByBookID(bookID uuid.UUID) ([]model.Loan, e
 }
 
 func (r *loanRepositoryImpl) UpdateLoan(loan *model.Loan) error {
-	query := `UPDATE loans SET user_id = $2, book_id = $3, loaned_at = $4, returned = $5 WHERE id = $1`
-	res, err := r.db.Exec(query, loan.ID, loan.UserID, loan.BookID, loan.LoanedAt, loan.Returned)
+	query := `UPDATE loans SET user_id = $2, book_id = $3, loaned_at = $4, due_at = $5, returned = $6, returned_at = $7, renewals = $8, return_branch_id = $9, overdue_at = $10 WHERE id = $1`
+	res, err := r.db.Exec(query, loan.ID, loan.UserID, loan.BookID, loan.LoanedAt, loan.DueAt, loan.Returned, loan.ReturnedAt, loan.Renewals, loan.ReturnBranchID, loan.OverdueAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to execute update query for loan ID %s: %w", loan.ID.String(), err)
@@ -163,7 +196,7 @@ func (r *loanRepositoryImpl) DeleteLoan(id uuid.UUID) error {
 }
 
 func (r *loanRepositoryImpl) GetAllLoans() ([]model.Loan, error) {
-	query := `SELECT id, user_id, book_id, loaned_at, returned FROM loans`
+	query := `SELECT ` + loanColumns + ` FROM loans`
 	rows, err := r.db.Query(query)
 
 	if err != nil {
@@ -181,7 +214,7 @@ func (r *loanRepositoryImpl) GetAllLoans() ([]model.Loan, error) {
 	for rows.Next() {
 		loan := model.Loan{}
 
-		if err := rows.Scan(&loan.ID, &loan.UserID, &loan.BookID, &loan.LoanedAt, &loan.Returned); err != nil {
+		if err := scanLoan(rows, &loan); err != nil {
 			return nil, fmt.Errorf("failed to scan loan row into struct: %w", err)
 		}
 		loans = append(loans, loan)
@@ -193,3 +226,92 @@ func (r *loanRepositoryImpl) GetAllLoans() ([]model.Loan, error) {
 
 	return loans, nil
 }
+
+func (r *loanRepositoryImpl) CountActiveLoansByUserID(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM loans WHERE user_id = $1 AND returned = FALSE`
+	err := r.db.QueryRow(query, userID).Scan(&count)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active loans for user ID %s: %w", userID.String(), err)
+	}
+
+	return count, nil
+}
+
+// GetActiveLoansDueBetween returns the loans still out whose due date falls in [from, to).
+func (r *loanRepositoryImpl) GetActiveLoansDueBetween(from, to time.Time) ([]model.Loan, error) {
+	query := `SELECT ` + loanColumns + ` FROM loans WHERE returned = FALSE AND due_at >= $1 AND due_at < $2 ORDER BY due_at`
+	rows, err := r.db.Query(query, from, to)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active loans due between %s and %s: %w", from.Format(time.RFC3339), to.Format(time.RFC3339), err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting loans by due date: %v", closeErr)
+		}
+	}()
+
+	loans := make([]model.Loan, 0)
+
+	for rows.Next() {
+		loan := model.Loan{}
+		if err := scanLoan(rows, &loan); err != nil {
+			return nil, fmt.Errorf("failed to scan loan row: %w", err)
+		}
+		loans = append(loans, loan)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during loan rows iteration: %w", err)
+	}
+
+	return loans, nil
+}
+
+// AnonymizeReturnedLoans removes the patron from the loans returned before
+// returnedBefore, skipping patrons who keep their history and loans with
+// unpaid fines. Each patron gets a fresh random reference, so the loans stay
+// countable per patron without pointing back to anyone.
+func (r *loanRepositoryImpl) AnonymizeReturnedLoans(returnedBefore time.Time) (int64, error) {
+	query := `WITH expired AS (
+			SELECT l.id, l.user_id, u.category FROM loans l
+			JOIN users u ON u.id = l.user_id
+			WHERE l.returned AND l.returned_at < $1 AND NOT u.keep_loan_history
+				AND NOT EXISTS (SELECT 1 FROM fines f WHERE f.loan_id = l.id AND NOT f.paid)
+		), refs AS (
+			SELECT user_id, gen_random_uuid() AS ref FROM (SELECT DISTINCT user_id FROM expired) patrons
+		)
+		UPDATE loans SET user_id = NULL, anonymous_ref = refs.ref, patron_category = expired.category, anonymized_at = CURRENT_TIMESTAMP
+		FROM expired JOIN refs ON refs.user_id = expired.user_id
+		WHERE loans.id = expired.id`
+	res, err := r.db.Exec(query, returnedBefore)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize loans returned before %s: %w", returnedBefore.Format(time.RFC3339), err)
+	}
+
+	anonymized, err := res.RowsAffected()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected after anonymizing loans: %w", err)
+	}
+
+	return anonymized, nil
+}
+
+// AnonymizeLoansByUserID removes the patron from all of its returned loans
+// at once, whatever the retention period, under a single fresh reference.
+func (r *loanRepositoryImpl) AnonymizeLoansByUserID(userID uuid.UUID) (int64, error) {
+	query := `UPDATE loans SET user_id = NULL, anonymous_ref = $2, patron_category = u.category, anonymized_at = CURRENT_TIMESTAMP
+		FROM users u
+		WHERE u.id = loans.user_id AND loans.user_id = $1 AND loans.returned`
+	res, err := r.db.Exec(query, userID, uuid.New())
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize loans of user ID %s: %w", userID.String(), err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"lib_backend/internal/model"
+)
+
+type MetadataCacheRepository interface {
+	GetEntry(isbn string) (*model.MetadataCacheEntry, error)
+	PutEntry(entry *model.MetadataCacheEntry) error
+}
+
+type metadataCacheRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewMetadataCacheRepository(db *sql.DB) MetadataCacheRepository {
+	return &metadataCacheRepositoryImpl{db: db}
+}
+
+func (r *metadataCacheRepositoryImpl) GetEntry(isbn string) (*model.MetadataCacheEntry, error) {
+	entry := &model.MetadataCacheEntry{}
+	var metadata []byte
+
+	query := `SELECT isbn, found, metadata, fetched_at FROM book_metadata_cache WHERE isbn = $1`
+	err := r.db.QueryRow(query, isbn).Scan(&entry.ISBN, &entry.Found, &metadata, &entry.FetchedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get cached metadata for ISBN %s: %w", isbn, err)
+	}
+
+	if metadata != nil {
+		entry.Metadata = &model.BookMetadata{}
+		if err := json.Unmarshal(metadata, entry.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode cached metadata for ISBN %s: %w", isbn, err)
+		}
+	}
+
+	return entry, nil
+}
+
+func (r *metadataCacheRepositoryImpl) PutEntry(entry *model.MetadataCacheEntry) error {
+	var metadata []byte
+	var source *string
+
+	if entry.Metadata != nil {
+		encoded, err := json.Marshal(entry.Metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode metadata for ISBN %s: %w", entry.ISBN, err)
+		}
+		metadata = encoded
+		source = &entry.Metadata.Source
+	}
+
+	entry.FetchedAt = time.Now()
+
+	query := `INSERT INTO book_metadata_cache (isbn, found, source, metadata, fetched_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (isbn) DO UPDATE SET found = EXCLUDED.found, source = EXCLUDED.source, metadata = EXCLUDED.metadata, fetched_at = EXCLUDED.fetched_at`
+	_, err := r.db.Exec(query, entry.ISBN, entry.Found, source, metadata, entry.FetchedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to cache metadata for ISBN %s: %w", entry.ISBN, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type NotificationRepository interface {
+	WithTx(tx *sql.Tx) NotificationRepository
+	CreateNotification(notification *model.Notification) error
+	GetNotificationsByUserID(userID uuid.UUID) ([]model.Notification, error)
+	HasNotification(kind string, referenceID uuid.UUID, since time.Time) (bool, error)
+	PurgeNotificationsBefore(before time.Time) (int64, error)
+	DeleteNotificationsByUserID(userID uuid.UUID) (int64, error)
+}
+
+const notificationColumns = `id, user_id, kind, channel, recipient, subject, body, status, error, reference_id, created_at`
+
+func scanNotification(row rowScanner, notification *model.Notification) error {
+	return row.Scan(&notification.ID, &notification.UserID, &notification.Kind, &notification.Channel, &notification.Recipient, &notification.Subject, &notification.Body, &notification.Status, &notification.Error, &notification.ReferenceID, &notification.CreatedAt)
+}
+
+type notificationRepositoryImpl struct {
+	db DBTX
+}
+
+func NewNotificationRepository(db *sql.DB) NotificationRepository {
+	return &notificationRepositoryImpl{db: db}
+}
+
+func (r *notificationRepositoryImpl) WithTx(tx *sql.Tx) NotificationRepository {
+	return &notificationRepositoryImpl{db: tx}
+}
+
+func (r *notificationRepositoryImpl) CreateNotification(notification *model.Notification) error {
+	notification.ID = uuid.New()
+	notification.CreatedAt = time.Now()
+
+	query := `INSERT INTO notifications (` + notificationColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.Exec(query, notification.ID, notification.UserID, notification.Kind, notification.Channel, notification.Recipient, notification.Subject, notification.Body, notification.Status, notification.Error, notification.ReferenceID, notification.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create %s notification for user ID %s: %w", notification.Kind, notification.UserID.String(), err)
+	}
+
+	return nil
+}
+
+func (r *notificationRepositoryImpl) GetNotificationsByUserID(userID uuid.UUID) ([]model.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications WHERE user_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Query(query, userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications for user ID %s: %w", userID.String(), err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting notifications by user ID %s: %v", userID.String(), closeErr)
+		}
+	}()
+
+	notifications := make([]model.Notification, 0)
+
+	for rows.Next() {
+		notification := model.Notification{}
+		if err := scanNotification(rows, &notification); err != nil {
+			return nil, fmt.Errorf("failed to scan notification row for user ID %s: %w", userID.String(), err)
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during notification rows iteration for user ID %s: %w", userID.String(), err)
+	}
+
+	return notifications, nil
+}
+
+// HasNotification reports whether a notification of the given kind about the
+// referenced record was already sent since the given time.
+func (r *notificationRepositoryImpl) HasNotification(kind string, referenceID uuid.UUID, since time.Time) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM notifications WHERE kind = $1 AND reference_id = $2 AND status = 'sent' AND created_at >= $3)`
+	err := r.db.QueryRow(query, kind, referenceID, since).Scan(&exists)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s notifications for reference %s: %w", kind, referenceID.String(), err)
+	}
+
+	return exists, nil
+}
+
+// PurgeNotificationsBefore deletes old notifications, keeping those about
+// loans still out: HasNotification relies on them to send each overdue notice
+// only once per due date, however late the loan gets.
+func (r *notificationRepositoryImpl) PurgeNotificationsBefore(before time.Time) (int64, error) {
+	query := `DELETE FROM notifications n WHERE n.created_at < $1
+		AND NOT EXISTS (SELECT 1 FROM loans l WHERE l.id = n.reference_id AND NOT l.returned)`
+	res, err := r.db.Exec(query, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge notifications: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *notificationRepositoryImpl) DeleteNotificationsByUserID(userID uuid.UUID) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM notifications WHERE user_id = $1`, userID)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete notifications of user ID %s: %w", userID.String(), err)
+	}
+
+	return res.RowsAffected()
+}
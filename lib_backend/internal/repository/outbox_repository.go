@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type OutboxRepository interface {
+	WithTx(tx *sql.Tx) OutboxRepository
+	Append(event *model.OutboxEvent) error
+	GetUnpublished(limit int) ([]model.OutboxEvent, error)
+	GetSince(afterSeq int64, limit int) ([]model.OutboxEvent, error)
+	GetLastSeq() (int64, error)
+	TryLockDispatch() (bool, error)
+	MarkPublished(seqs []int64) error
+	PurgePublishedBefore(before time.Time) (int64, error)
+	GetEventsByUserID(userID uuid.UUID) ([]model.OutboxEvent, error)
+	RedactUserEvents(userID uuid.UUID) (int64, error)
+	RedactEventsBefore(before time.Time) (int64, error)
+}
+
+const outboxColumns = `seq, id, type, data, occurred_at, published_at`
+
+func scanOutboxEvent(row rowScanner, event *model.OutboxEvent) error {
+	return row.Scan(&event.Seq, &event.ID, &event.Type, &event.Data, &event.OccurredAt, &event.PublishedAt)
+}
+
+// userEventCondition matches the events about a user: the user events
+// themselves, and the loans and holds that carry its user_id.
+const userEventCondition = `(data->>'user_id' = $1 OR (type LIKE 'user.%' AND data->>'id' = $1))`
+
+// redactedEventData is the data of an event once stripped of its patron.
+const redactedEventData = `CASE WHEN type LIKE 'user.%' THEN jsonb_build_object('id', data->'id') ELSE data - 'user_id' END`
+
+// outboxAppendLockKey serialises writers so seq order matches commit order and
+// the dispatcher never skips over a transaction that commits late.
+const outboxAppendLockKey = "lib_backend:outbox:append"
+
+// outboxDispatchLockKey makes sure only one replica relays the outbox at a
+// time, which keeps events in order for every sink.
+const outboxDispatchLockKey = "lib_backend:outbox:dispatch"
+
+type outboxRepositoryImpl struct {
+	db DBTX
+}
+
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepositoryImpl{db: db}
+}
+
+func (r *outboxRepositoryImpl) WithTx(tx *sql.Tx) OutboxRepository {
+	return &outboxRepositoryImpl{db: tx}
+}
+
+// Append must run inside the transaction of the change the event describes.
+func (r *outboxRepositoryImpl) Append(event *model.OutboxEvent) error {
+	if _, err := r.db.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, outboxAppendLockKey); err != nil {
+		return fmt.Errorf("failed to lock outbox for event %s: %w", event.Type, err)
+	}
+
+	query := `INSERT INTO outbox (id, type, data, occurred_at) VALUES ($1, $2, $3, $4) RETURNING seq`
+	err := r.db.QueryRow(query, event.ID, event.Type, []byte(event.Data), event.OccurredAt).Scan(&event.Seq)
+
+	if err != nil {
+		return fmt.Errorf("failed to append event %s to outbox: %w", event.Type, err)
+	}
+
+	return nil
+}
+
+// TryLockDispatch takes the dispatch lock until the transaction ends and
+// reports whether it was free.
+func (r *outboxRepositoryImpl) TryLockDispatch() (bool, error) {
+	var acquired bool
+
+	if err := r.db.QueryRow(`SELECT pg_try_advisory_xact_lock(hashtext($1))`, outboxDispatchLockKey).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("failed to take outbox dispatch lock: %w", err)
+	}
+
+	return acquired, nil
+}
+
+func (r *outboxRepositoryImpl) GetUnpublished(limit int) ([]model.OutboxEvent, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE published_at IS NULL ORDER BY seq LIMIT $1`
+	return r.queryOutboxEvents(query, limit)
+}
+
+// GetSince returns committed events after the given seq, published or not.
+func (r *outboxRepositoryImpl) GetSince(afterSeq int64, limit int) ([]model.OutboxEvent, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE seq > $1 ORDER BY seq LIMIT $2`
+	return r.queryOutboxEvents(query, afterSeq, limit)
+}
+
+func (r *outboxRepositoryImpl) GetLastSeq() (int64, error) {
+	var seq int64
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(seq), 0) FROM outbox`).Scan(&seq)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last outbox seq: %w", err)
+	}
+
+	return seq, nil
+}
+
+func (r *outboxRepositoryImpl) queryOutboxEvents(query string, args ...any) ([]model.OutboxEvent, error) {
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox events: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after querying outbox events: %v", closeErr)
+		}
+	}()
+
+	outboxEvents := make([]model.OutboxEvent, 0)
+
+	for rows.Next() {
+		event := model.OutboxEvent{}
+		if err := scanOutboxEvent(rows, &event); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event row: %w", err)
+		}
+		outboxEvents = append(outboxEvents, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during outbox event rows iteration: %w", err)
+	}
+
+	return outboxEvents, nil
+}
+
+func (r *outboxRepositoryImpl) MarkPublished(seqs []int64) error {
+	_, err := r.db.Exec(`UPDATE outbox SET published_at = $2 WHERE seq = ANY($1)`, pq.Array(seqs), time.Now())
+
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox events as published: %w", err)
+	}
+
+	return nil
+}
+
+func (r *outboxRepositoryImpl) PurgePublishedBefore(before time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM outbox WHERE published_at IS NOT NULL AND occurred_at < $1`, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox events: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+func (r *outboxRepositoryImpl) GetEventsByUserID(userID uuid.UUID) ([]model.OutboxEvent, error) {
+	query := `SELECT ` + outboxColumns + ` FROM outbox WHERE ` + userEventCondition + ` ORDER BY seq`
+	return r.queryOutboxEvents(query, userID.String())
+}
+
+// RedactUserEvents strips a user from its events: user events keep only the
+// ID and the others lose their user_id.
+func (r *outboxRepositoryImpl) RedactUserEvents(userID uuid.UUID) (int64, error) {
+	query := `UPDATE outbox SET data = ` + redactedEventData + ` WHERE ` + userEventCondition
+	res, err := r.db.Exec(query, userID.String())
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to redact outbox events of user ID %s: %w", userID.String(), err)
+	}
+
+	return res.RowsAffected()
+}
+
+// RedactEventsBefore applies the redaction of RedactUserEvents to every event
+// that occurred before the given time and still names a patron.
+func (r *outboxRepositoryImpl) RedactEventsBefore(before time.Time) (int64, error) {
+	query := `UPDATE outbox SET data = ` + redactedEventData + `
+		WHERE occurred_at < $1 AND data <> ` + redactedEventData
+	res, err := r.db.Exec(query, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to redact outbox events: %w", err)
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+)
+
+type PatronCategoryRepository interface {
+	GetCategoryByCode(code string) (*model.PatronCategory, error)
+	UpdateCategory(category *model.PatronCategory) error
+	GetAllCategories() ([]model.PatronCategory, error)
+}
+
+type patronCategoryRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewPatronCategoryRepository(db *sql.DB) PatronCategoryRepository {
+	return &patronCategoryRepositoryImpl{db: db}
+}
+
+func (r *patronCategoryRepositoryImpl) GetCategoryByCode(code string) (*model.PatronCategory, error) {
+	category := &model.PatronCategory{}
+	query := `SELECT code, name, max_loans, loan_period_days, max_renewals, max_holds FROM patron_categories WHERE code = $1`
+	err := r.db.QueryRow(query, code).Scan(&category.Code, &category.Name, &category.MaxLoans, &category.LoanPeriodDays, &category.MaxRenewals, &category.MaxHolds)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get patron category %s: %w", code, err)
+	}
+
+	return category, nil
+}
+
+func (r *patronCategoryRepositoryImpl) UpdateCategory(category *model.PatronCategory) error {
+	query := `UPDATE patron_categories SET name = $2, max_loans = $3, loan_period_days = $4, max_renewals = $5, max_holds = $6 WHERE code = $1`
+	res, err := r.db.Exec(query, category.Code, category.Name, category.MaxLoans, category.LoanPeriodDays, category.MaxRenewals, category.MaxHolds)
+
+	if err != nil {
+		return fmt.Errorf("failed to update patron category %s: %w", category.Code, err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating patron category %s: %w", category.Code, err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("patron category %s not found for update", category.Code)
+	}
+
+	return nil
+}
+
+func (r *patronCategoryRepositoryImpl) GetAllCategories() ([]model.PatronCategory, error) {
+	query := `SELECT code, name, max_loans, loan_period_days, max_renewals, max_holds FROM patron_categories ORDER BY code`
+	rows, err := r.db.Query(query)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query patron categories: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting patron categories: %v", closeErr)
+		}
+	}()
+
+	categories := make([]model.PatronCategory, 0)
+
+	for rows.Next() {
+		category := model.PatronCategory{}
+		if err := rows.Scan(&category.Code, &category.Name, &category.MaxLoans, &category.LoanPeriodDays, &category.MaxRenewals, &category.MaxHolds); err != nil {
+			return nil, fmt.Errorf("failed to scan patron category row: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during patron category rows iteration: %w", err)
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type RateLimitRepository interface {
+	TakeToken(key string, capacity float64, ratePerSecond float64) (float64, bool, error)
+	PurgeIdleBuckets(before time.Time) (int64, error)
+}
+
+type rateLimitRepositoryImpl struct {
+	db DBTX
+}
+
+func NewRateLimitRepository(db *sql.DB) RateLimitRepository {
+	return &rateLimitRepositoryImpl{db: db}
+}
+
+// TakeToken refills the bucket of key and takes a token from it in a single
+// statement, so concurrent replicas cannot spend the same token. It returns
+// the tokens left and whether one was taken; a refused request leaves the
+// bucket untouched.
+func (r *rateLimitRepositoryImpl) TakeToken(key string, capacity float64, ratePerSecond float64) (float64, bool, error) {
+	const refilled = `LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - b.updated_at)::float8 * $3::float8)`
+
+	query := `INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at) VALUES ($1, $2::float8 - 1, CURRENT_TIMESTAMP)
+		ON CONFLICT (key) DO UPDATE SET tokens = ` + refilled + ` - 1, updated_at = CURRENT_TIMESTAMP
+		WHERE ` + refilled + ` >= 1
+		RETURNING tokens`
+
+	var tokens float64
+	err := r.db.QueryRow(query, key, capacity, ratePerSecond).Scan(&tokens)
+
+	if err == nil {
+		return tokens, true, nil
+	}
+
+	if err != sql.ErrNoRows {
+		return 0, false, fmt.Errorf("failed to take rate limit token for %s: %w", key, err)
+	}
+
+	query = `SELECT ` + refilled + ` FROM rate_limit_buckets b WHERE b.key = $1`
+
+	if err := r.db.QueryRow(query, key, capacity, ratePerSecond).Scan(&tokens); err != nil {
+		return 0, false, fmt.Errorf("failed to read rate limit bucket for %s: %w", key, err)
+	}
+
+	return tokens, false, nil
+}
+
+func (r *rateLimitRepositoryImpl) PurgeIdleBuckets(before time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM rate_limit_buckets WHERE updated_at < $1`, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idle rate limit buckets: %w", err)
+	}
+
+	return res.RowsAffected()
+}
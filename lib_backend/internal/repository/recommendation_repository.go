@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type RecommendationRepository interface {
+	WithTx(tx *sql.Tx) RecommendationRepository
+	RefreshSimilarities(minSupport int, maxRelated int) (int, error)
+	GetRelatedBooks(bookID uuid.UUID, minSupport int, limit int) ([]model.Recommendation, error)
+	GetRecommendations(userID uuid.UUID, minSupport int, limit int) ([]model.Recommendation, error)
+}
+
+type recommendationRepositoryImpl struct {
+	db DBTX
+}
+
+func NewRecommendationRepository(db *sql.DB) RecommendationRepository {
+	return &recommendationRepositoryImpl{db: db}
+}
+
+func (r *recommendationRepositoryImpl) WithTx(tx *sql.Tx) RecommendationRepository {
+	return &recommendationRepositoryImpl{db: tx}
+}
+
+// RefreshSimilarities rebuilds title_similarities from the loans. Copies of a
+// title share its ISBN, so the borrowers of every copy count for the title
+// and each patron counts once per title however often they borrowed it. The
+// score is the cosine similarity of the two titles' sets of borrowers, and
+// only the maxRelated best pairs with at least minSupport shared borrowers
+// are kept per title. It returns the number of pairs stored.
+func (r *recommendationRepositoryImpl) RefreshSimilarities(minSupport int, maxRelated int) (int, error) {
+	if _, err := r.db.Exec(`DELETE FROM title_similarities`); err != nil {
+		return 0, fmt.Errorf("failed to clear title similarities: %w", err)
+	}
+
+	query := `WITH borrowers AS (
+			SELECT DISTINCT COALESCE(loans.user_id, loans.anonymous_ref) AS user_id, books.isbn
+			FROM loans
+			JOIN books ON books.id = loans.book_id
+		), borrower_counts AS (
+			SELECT isbn, COUNT(*) AS borrowers FROM borrowers GROUP BY isbn
+		), pairs AS (
+			SELECT a.isbn, b.isbn AS related_isbn, COUNT(*) AS support
+			FROM borrowers a
+			JOIN borrowers b ON b.user_id = a.user_id AND b.isbn <> a.isbn
+			GROUP BY a.isbn, b.isbn
+			HAVING COUNT(*) >= $1
+		), ranked AS (
+			SELECT pairs.isbn, pairs.related_isbn, pairs.support,
+				pairs.support / sqrt(ca.borrowers::float8 * cb.borrowers) AS score
+			FROM pairs
+			JOIN borrower_counts ca ON ca.isbn = pairs.isbn
+			JOIN borrower_counts cb ON cb.isbn = pairs.related_isbn
+		)
+		INSERT INTO title_similarities (isbn, related_isbn, support, score, computed_at)
+		SELECT isbn, related_isbn, support, score, CURRENT_TIMESTAMP FROM (
+			SELECT ranked.*, row_number() OVER (PARTITION BY isbn ORDER BY score DESC, support DESC, related_isbn) AS rank_in_title
+			FROM ranked
+		) best
+		WHERE rank_in_title <= $2`
+	res, err := r.db.Exec(query, minSupport, maxRelated)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute title similarities: %w", err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to check rows affected after computing title similarities: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// titleCopy picks the copy that stands for a related title: one still in the
+// collection, preferring a copy on the shelf.
+const titleCopy = `JOIN LATERAL (
+			SELECT c.id FROM books c
+			WHERE c.isbn = %s AND c.withdrawn_at IS NULL
+			ORDER BY c.available DESC, c.id
+			LIMIT 1
+		) title_copy ON true
+		JOIN books ON books.id = title_copy.id`
+
+// GetRelatedBooks lists the titles most often borrowed by the patrons who
+// borrowed bookID's title, one copy each; other copies of bookID's own title
+// are never listed. minSupport is checked again so raising it takes effect
+// before the next refresh.
+func (r *recommendationRepositoryImpl) GetRelatedBooks(bookID uuid.UUID, minSupport int, limit int) ([]model.Recommendation, error) {
+	query := `SELECT ` + bookSelectColumns + `, ts.score
+		FROM title_similarities ts
+		` + fmt.Sprintf(titleCopy, "ts.related_isbn") + `
+		WHERE ts.isbn = (SELECT isbn FROM books WHERE id = $1) AND ts.support >= $2
+		ORDER BY ts.score DESC, books.title
+		LIMIT $3`
+
+	return r.queryRecommendations(query, bookID, minSupport, limit)
+}
+
+// GetRecommendations adds up the similarities of the titles a patron has
+// borrowed and returns one copy of each of the best scoring titles they have
+// not borrowed any copy of yet.
+func (r *recommendationRepositoryImpl) GetRecommendations(userID uuid.UUID, minSupport int, limit int) ([]model.Recommendation, error) {
+	query := `WITH borrowed AS (
+			SELECT DISTINCT books.isbn FROM loans JOIN books ON books.id = loans.book_id WHERE loans.user_id = $1
+		), scores AS (
+			SELECT ts.related_isbn AS isbn, SUM(ts.score) AS score
+			FROM title_similarities ts
+			WHERE ts.isbn IN (SELECT isbn FROM borrowed)
+				AND ts.support >= $2
+				AND ts.related_isbn NOT IN (SELECT isbn FROM borrowed)
+			GROUP BY ts.related_isbn
+		)
+		SELECT ` + bookSelectColumns + `, scores.score
+		FROM scores
+		` + fmt.Sprintf(titleCopy, "scores.isbn") + `
+		ORDER BY scores.score DESC, books.title
+		LIMIT $3`
+
+	return r.queryRecommendations(query, userID, minSupport, limit)
+}
+
+func (r *recommendationRepositoryImpl) queryRecommendations(query string, args ...any) ([]model.Recommendation, error) {
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting recommendations: %v", closeErr)
+		}
+	}()
+
+	recommendations := make([]model.Recommendation, 0)
+
+	for rows.Next() {
+		recommendation := model.Recommendation{}
+		if err := scanBook(extraColumns{row: rows, extra: []any{&recommendation.Score}}, &recommendation.Book); err != nil {
+			return nil, fmt.Errorf("failed to scan recommendation row: %w", err)
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during recommendation rows iteration: %w", err)
+	}
+
+	return recommendations, nil
+}
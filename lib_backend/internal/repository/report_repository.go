@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"lib_backend/internal/model"
+)
+
+type ReportRepository interface {
+	GetLoanVolume(filter model.ReportFilter) ([]model.LoanVolume, error)
+	GetTopBooks(filter model.ReportFilter) ([]model.TopBook, error)
+	GetActivePatrons(filter model.ReportFilter) ([]model.ActivePatrons, error)
+	GetLoanDuration(filter model.ReportFilter) (*model.LoanDuration, error)
+	GetOverdueRate(filter model.ReportFilter) (*model.OverdueRate, error)
+	GetCollectionTurnover(filter model.ReportFilter) (*model.CollectionTurnover, error)
+	GetWeedingCandidates(filter model.WeedingFilter) ([]model.WeedingCandidate, error)
+}
+
+type reportRepositoryImpl struct {
+	db DBTX
+}
+
+func NewReportRepository(db *sql.DB) ReportRepository {
+	return &reportRepositoryImpl{db: db}
+}
+
+// reportLoans selects the loans covered by filter as a subquery aliased l,
+// with the borrower's category and a patron column that also identifies the
+// borrower of anonymized loans, and returns its arguments.
+func reportLoans(filter model.ReportFilter) (string, []any) {
+	conditions := []string{`l.loaned_at >= $1`, `l.loaned_at < $2`}
+	args := []any{filter.From, filter.To}
+
+	if filter.BranchID != nil {
+		args = append(args, *filter.BranchID)
+		conditions = append(conditions, fmt.Sprintf(`l.checkout_branch_id = $%d`, len(args)))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf(`COALESCE(u.category, l.patron_category) = $%d`, len(args)))
+	}
+
+	return `(SELECT l.*, COALESCE(u.category, l.patron_category) AS category, COALESCE(l.user_id, l.anonymous_ref) AS patron
+		FROM loans l LEFT JOIN users u ON u.id = l.user_id
+		WHERE ` + strings.Join(conditions, " AND ") + `) l`, args
+}
+
+// GetLoanVolume counts loans per day, week or month, including the periods
+// without any loans.
+func (r *reportRepositoryImpl) GetLoanVolume(filter model.ReportFilter) ([]model.LoanVolume, error) {
+	loans, args := reportLoans(filter)
+	args = append(args, filter.Interval)
+	interval := fmt.Sprintf(`$%d::text`, len(args))
+
+	query := `SELECT periods.period, COUNT(l.id), COUNT(DISTINCT l.patron)
+		FROM generate_series(date_trunc(` + interval + `, $1::timestamptz), $2::timestamptz - interval '1 microsecond', ('1 ' || ` + interval + `)::interval) AS periods(period)
+		LEFT JOIN ` + loans + ` ON date_trunc(` + interval + `, l.loaned_at) = periods.period
+		GROUP BY periods.period
+		ORDER BY periods.period`
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan volume: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting loan volume: %v", closeErr)
+		}
+	}()
+
+	volume := make([]model.LoanVolume, 0)
+
+	for rows.Next() {
+		entry := model.LoanVolume{}
+		if err := rows.Scan(&entry.Period, &entry.Loans, &entry.Patrons); err != nil {
+			return nil, fmt.Errorf("failed to scan loan volume row: %w", err)
+		}
+		volume = append(volume, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during loan volume rows iteration: %w", err)
+	}
+
+	return volume, nil
+}
+
+func (r *reportRepositoryImpl) GetTopBooks(filter model.ReportFilter) ([]model.TopBook, error) {
+	loans, args := reportLoans(filter)
+	args = append(args, filter.Limit)
+
+	query := fmt.Sprintf(`SELECT b.id, b.title, b.author, b.isbn, COUNT(*)
+		FROM %s
+		JOIN books b ON b.id = l.book_id
+		GROUP BY b.id, b.title, b.author, b.isbn
+		ORDER BY COUNT(*) DESC, b.title
+		LIMIT $%d`, loans, len(args))
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most borrowed books: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting most borrowed books: %v", closeErr)
+		}
+	}()
+
+	books := make([]model.TopBook, 0)
+
+	for rows.Next() {
+		book := model.TopBook{}
+		if err := rows.Scan(&book.BookID, &book.Title, &book.Author, &book.Isbn, &book.Loans); err != nil {
+			return nil, fmt.Errorf("failed to scan most borrowed book row: %w", err)
+		}
+		books = append(books, book)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during most borrowed book rows iteration: %w", err)
+	}
+
+	return books, nil
+}
+
+// GetActivePatrons counts the patrons who borrowed anything in the period,
+// per category.
+func (r *reportRepositoryImpl) GetActivePatrons(filter model.ReportFilter) ([]model.ActivePatrons, error) {
+	loans, args := reportLoans(filter)
+
+	query := `SELECT l.category, COUNT(DISTINCT l.patron), COUNT(*)
+		FROM ` + loans + `
+		GROUP BY l.category
+		ORDER BY l.category`
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active patrons: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting active patrons: %v", closeErr)
+		}
+	}()
+
+	patrons := make([]model.ActivePatrons, 0)
+
+	for rows.Next() {
+		entry := model.ActivePatrons{}
+		if err := rows.Scan(&entry.Category, &entry.Patrons, &entry.Loans); err != nil {
+			return nil, fmt.Errorf("failed to scan active patrons row: %w", err)
+		}
+		patrons = append(patrons, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during active patrons rows iteration: %w", err)
+	}
+
+	return patrons, nil
+}
+
+func (r *reportRepositoryImpl) GetLoanDuration(filter model.ReportFilter) (*model.LoanDuration, error) {
+	loans, args := reportLoans(filter)
+	duration := &model.LoanDuration{}
+
+	query := `SELECT COUNT(*),
+			COALESCE(AVG(EXTRACT(EPOCH FROM l.returned_at - l.loaned_at)), 0) / 86400,
+			COALESCE(MIN(EXTRACT(EPOCH FROM l.returned_at - l.loaned_at)), 0) / 86400,
+			COALESCE(MAX(EXTRACT(EPOCH FROM l.returned_at - l.loaned_at)), 0) / 86400
+		FROM ` + loans + `
+		WHERE l.returned_at IS NOT NULL`
+	err := r.db.QueryRow(query, args...).Scan(&duration.ReturnedLoans, &duration.AverageDays, &duration.MinDays, &duration.MaxDays)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan duration: %w", err)
+	}
+
+	return duration, nil
+}
+
+func (r *reportRepositoryImpl) GetOverdueRate(filter model.ReportFilter) (*model.OverdueRate, error) {
+	loans, args := reportLoans(filter)
+	rate := &model.OverdueRate{}
+
+	query := `SELECT COUNT(*),
+			COUNT(*) FILTER (WHERE COALESCE(l.returned_at, CURRENT_TIMESTAMP) > l.due_at),
+			COALESCE(COUNT(*) FILTER (WHERE COALESCE(l.returned_at, CURRENT_TIMESTAMP) > l.due_at)::float8 / NULLIF(COUNT(*), 0), 0)
+		FROM ` + loans
+	err := r.db.QueryRow(query, args...).Scan(&rate.Loans, &rate.Overdue, &rate.Rate)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue rate: %w", err)
+	}
+
+	return rate, nil
+}
+
+func (r *reportRepositoryImpl) GetCollectionTurnover(filter model.ReportFilter) (*model.CollectionTurnover, error) {
+	loans, args := reportLoans(filter)
+	turnover := &model.CollectionTurnover{}
+
+	items := `(SELECT COUNT(*) FROM books)`
+	if filter.BranchID != nil {
+		// reportLoans puts the branch right after the date range
+		items = `(SELECT COUNT(*) FROM books WHERE home_branch_id = $3)`
+	}
+
+	query := `SELECT COUNT(l.id), ` + items + `,
+			COALESCE(COUNT(l.id)::float8 / NULLIF(` + items + `, 0), 0)
+		FROM ` + loans
+	err := r.db.QueryRow(query, args...).Scan(&turnover.Loans, &turnover.Items, &turnover.Turnover)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection turnover: %w", err)
+	}
+
+	return turnover, nil
+}
+
+// GetWeedingCandidates lists the books in the collection that have not been
+// borrowed within the filter's window, those idle the longest first.
+func (r *reportRepositoryImpl) GetWeedingCandidates(filter model.WeedingFilter) ([]model.WeedingCandidate, error) {
+	conditions := []string{`b.withdrawn_at IS NULL`}
+	args := make([]any, 0)
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.Years == nil {
+		conditions = append(conditions, `stats.book_id IS NULL`)
+	} else {
+		cutoff := time.Now().AddDate(-*filter.Years, 0, 0)
+		addCondition(`(stats.last_loan_at IS NULL OR stats.last_loan_at < ?) AND (b.added_at IS NULL OR b.added_at < ?)`, cutoff)
+	}
+	if filter.BranchID != nil {
+		addCondition(`b.home_branch_id = ?`, *filter.BranchID)
+	}
+	if filter.Subject != "" {
+		addCondition(`EXISTS (SELECT 1 FROM book_subjects bs JOIN subjects s ON s.id = bs.subject_id
+			WHERE bs.book_id = b.id AND s.heading_key = subject_heading_key(?))`, filter.Subject)
+	}
+	if filter.SubjectID != nil {
+		addCondition(`EXISTS (SELECT 1 FROM book_subjects bs WHERE bs.book_id = b.id AND bs.subject_id IN (
+			WITH RECURSIVE tree AS (SELECT id FROM subjects WHERE id = ? UNION SELECT s.id FROM subjects s JOIN tree ON s.parent_id = tree.id)
+			SELECT id FROM tree))`, *filter.SubjectID)
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := fmt.Sprintf(`SELECT b.id, b.title, b.author, b.isbn, COALESCE(b.call_number, ''), COALESCE(br.code, ''),
+			b.added_at, CURRENT_DATE - b.added_at::date, stats.last_loan_at, COALESCE(stats.loans, 0)
+		FROM books b
+		LEFT JOIN branches br ON br.id = b.home_branch_id
+		LEFT JOIN (SELECT book_id, MAX(loaned_at) AS last_loan_at, COUNT(*) AS loans FROM loans GROUP BY book_id) stats ON stats.book_id = b.id
+		WHERE %s
+		ORDER BY stats.last_loan_at NULLS FIRST, b.added_at NULLS FIRST, b.title
+		LIMIT $%d OFFSET $%d`, strings.Join(conditions, " AND "), len(args)-1, len(args))
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weeding candidates: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting weeding candidates: %v", closeErr)
+		}
+	}()
+
+	candidates := make([]model.WeedingCandidate, 0)
+
+	for rows.Next() {
+		candidate := model.WeedingCandidate{}
+		if err := rows.Scan(&candidate.BookID, &candidate.Title, &candidate.Author, &candidate.Isbn, &candidate.CallNumber, &candidate.BranchCode,
+			&candidate.AddedAt, &candidate.AgeDays, &candidate.LastLoanAt, &candidate.LifetimeLoans); err != nil {
+			return nil, fmt.Errorf("failed to scan weeding candidate row: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during weeding candidate rows iteration: %w", err)
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// extraColumns scans columns selected after those of a scan helper, so
+// joined queries can reuse helpers such as scanBook.
+type extraColumns struct {
+	row   rowScanner
+	extra []any
+}
+
+func (e extraColumns) Scan(dest ...any) error {
+	return e.row.Scan(append(dest, e.extra...)...)
+}
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so a repository can run its
+// queries inside a transaction started by a service.
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+type Transactor interface {
+	// WithinTx runs fn in a transaction, committing if it returns nil and
+	// rolling back otherwise.
+	WithinTx(fn func(tx *sql.Tx) error) error
+}
+
+type transactorImpl struct {
+	db *sql.DB
+}
+
+func NewTransactor(db *sql.DB) Transactor {
+	return &transactorImpl{db: db}
+}
+
+func (t *transactorImpl) WithinTx(fn func(tx *sql.Tx) error) error {
+	tx, err := t.db.Begin()
+
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			log.Printf("ERROR: failed to roll back transaction: %v", rollbackErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
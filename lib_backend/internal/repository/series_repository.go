@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type SeriesRepository interface {
+	CreateSeries(series *model.Series) error
+	GetSeriesByID(id uuid.UUID) (*model.Series, error)
+	SearchSeries(query string, limit int) ([]model.Series, error)
+	UpdateSeries(series *model.Series) error
+	DeleteSeries(id uuid.UUID) error
+	GetVolumes(seriesID uuid.UUID) ([]model.Book, error)
+	GetBookSeries(bookIDs []uuid.UUID) (map[uuid.UUID]model.BookSeries, error)
+}
+
+const seriesColumns = `id, title, description, created_at,
+	(SELECT COUNT(DISTINCT COALESCE(b.volume_number::text, b.isbn)) FROM books b WHERE b.series_id = series.id)`
+
+func scanSeries(row rowScanner, series *model.Series) error {
+	var description sql.NullString
+	err := row.Scan(&series.ID, &series.Title, &description, &series.CreatedAt, &series.VolumeCount)
+	series.Description = description.String
+	return err
+}
+
+type seriesRepositoryImpl struct {
+	db DBTX
+}
+
+func NewSeriesRepository(db *sql.DB) SeriesRepository {
+	return &seriesRepositoryImpl{db: db}
+}
+
+func (r *seriesRepositoryImpl) CreateSeries(series *model.Series) error {
+	series.ID = uuid.New()
+
+	query := `INSERT INTO series (id, title, description, created_at) VALUES ($1, $2, $3, CURRENT_TIMESTAMP) RETURNING created_at`
+	err := r.db.QueryRow(query, series.ID, series.Title, nullIfEmpty(series.Description)).Scan(&series.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create series %s: %w", series.Title, err)
+	}
+
+	return nil
+}
+
+func (r *seriesRepositoryImpl) GetSeriesByID(id uuid.UUID) (*model.Series, error) {
+	series := &model.Series{}
+	query := `SELECT ` + seriesColumns + ` FROM series WHERE id = $1`
+	err := scanSeries(r.db.QueryRow(query, id), series)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get series by ID %s: %w", id.String(), err)
+	}
+
+	return series, nil
+}
+
+// SearchSeries lists series by title, optionally only those whose title
+// contains query.
+func (r *seriesRepositoryImpl) SearchSeries(query string, limit int) ([]model.Series, error) {
+	sqlQuery := `SELECT ` + seriesColumns + ` FROM series
+		WHERE $1 = '' OR title ILIKE '%' || $1 || '%'
+		ORDER BY lower(title) LIMIT $2`
+	rows, err := r.db.Query(sqlQuery, query, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search series: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after searching series: %v", closeErr)
+		}
+	}()
+
+	seriesList := make([]model.Series, 0)
+
+	for rows.Next() {
+		series := model.Series{}
+		if err := scanSeries(rows, &series); err != nil {
+			return nil, fmt.Errorf("failed to scan series row: %w", err)
+		}
+		seriesList = append(seriesList, series)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during series rows iteration: %w", err)
+	}
+
+	return seriesList, nil
+}
+
+func (r *seriesRepositoryImpl) UpdateSeries(series *model.Series) error {
+	query := `UPDATE series SET title = $2, description = $3 WHERE id = $1`
+	res, err := r.db.Exec(query, series.ID, series.Title, nullIfEmpty(series.Description))
+
+	if err != nil {
+		return fmt.Errorf("failed to update series %s: %w", series.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating series %s: %w", series.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("series with ID %s not found for update", series.ID)
+	}
+
+	return nil
+}
+
+// DeleteSeries removes a series; its books stay in the catalog as standalone titles.
+func (r *seriesRepositoryImpl) DeleteSeries(id uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM series WHERE id = $1`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete series %s: %w", id.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after deleting series %s: %w", id.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("series with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
+
+// GetVolumes returns the books of a series in reading order. Books without a
+// volume number come last, by title.
+func (r *seriesRepositoryImpl) GetVolumes(seriesID uuid.UUID) ([]model.Book, error) {
+	query := `SELECT ` + bookSelectColumns + ` FROM books
+		WHERE series_id = $1
+		ORDER BY volume_number NULLS LAST, title, barcode`
+	rows, err := r.db.Query(query, seriesID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volumes of series %s: %w", seriesID.String(), err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting series volumes: %v", closeErr)
+		}
+	}()
+
+	books := make([]model.Book, 0)
+
+	for rows.Next() {
+		book := model.Book{}
+		if err := scanBook(rows, &book); err != nil {
+			return nil, fmt.Errorf("failed to scan series volume row: %w", err)
+		}
+		books = append(books, book)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during series volume rows iteration: %w", err)
+	}
+
+	return books, nil
+}
+
+// GetBookSeries loads the series of several books at once, keyed by book ID.
+// The next volume is the lowest volume number after the book's own,
+// preferring a copy that is available.
+func (r *seriesRepositoryImpl) GetBookSeries(bookIDs []uuid.UUID) (map[uuid.UUID]model.BookSeries, error) {
+	query := `SELECT b.id, s.id, s.title, b.volume_number,
+			(SELECT COUNT(DISTINCT COALESCE(v.volume_number::text, v.isbn)) FROM books v WHERE v.series_id = s.id),
+			n.id, n.title, n.volume_number, n.available
+		FROM books b
+		JOIN series s ON s.id = b.series_id
+		LEFT JOIN LATERAL (
+			SELECT nb.id, nb.title, nb.volume_number, nb.available FROM books nb
+			WHERE nb.series_id = b.series_id AND nb.volume_number > b.volume_number AND nb.withdrawn_at IS NULL
+			ORDER BY nb.volume_number, nb.available DESC, nb.title
+			LIMIT 1
+		) n ON true
+		WHERE b.id = ANY($1)`
+	rows, err := r.db.Query(query, pq.Array(bookIDs))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series of books: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting book series: %v", closeErr)
+		}
+	}()
+
+	seriesByBook := make(map[uuid.UUID]model.BookSeries)
+
+	for rows.Next() {
+		var bookID uuid.UUID
+		var nextID *uuid.UUID
+		var nextTitle sql.NullString
+		var nextVolume sql.NullInt64
+		var nextAvailable sql.NullBool
+		series := model.BookSeries{}
+
+		if err := rows.Scan(&bookID, &series.ID, &series.Title, &series.VolumeNumber, &series.VolumeCount, &nextID, &nextTitle, &nextVolume, &nextAvailable); err != nil {
+			return nil, fmt.Errorf("failed to scan book series row: %w", err)
+		}
+
+		if nextID != nil {
+			series.Next = &model.SeriesVolume{BookID: *nextID, Title: nextTitle.String, VolumeNumber: int(nextVolume.Int64), Available: nextAvailable.Bool}
+		}
+		seriesByBook[bookID] = series
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during book series rows iteration: %w", err)
+	}
+
+	return seriesByBook, nil
+}
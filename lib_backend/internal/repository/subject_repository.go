@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type SubjectRepository interface {
+	WithTx(tx *sql.Tx) SubjectRepository
+	CreateSubject(subject *model.Subject) error
+	GetSubjectByID(id uuid.UUID) (*model.Subject, error)
+	GetSubjectByHeading(heading string) (*model.Subject, error)
+	SearchSubjects(filter model.SubjectFilter) ([]model.Subject, error)
+	IsDescendant(id uuid.UUID, ancestorID uuid.UUID) (bool, error)
+	UpdateSubject(subject *model.Subject) error
+	DeleteSubject(id uuid.UUID) error
+	SetBookSubjects(bookID uuid.UUID, subjectIDs []uuid.UUID) error
+	GetTags(query string, limit int) ([]model.TagCount, error)
+}
+
+const subjectColumns = `id, heading, parent_id, created_at,
+	(SELECT COUNT(*) FROM book_subjects bs WHERE bs.subject_id = subjects.id)`
+
+func scanSubject(row rowScanner, subject *model.Subject) error {
+	return row.Scan(&subject.ID, &subject.Heading, &subject.ParentID, &subject.CreatedAt, &subject.BookCount)
+}
+
+type subjectRepositoryImpl struct {
+	db DBTX
+}
+
+func NewSubjectRepository(db *sql.DB) SubjectRepository {
+	return &subjectRepositoryImpl{db: db}
+}
+
+func (r *subjectRepositoryImpl) WithTx(tx *sql.Tx) SubjectRepository {
+	return &subjectRepositoryImpl{db: tx}
+}
+
+func (r *subjectRepositoryImpl) CreateSubject(subject *model.Subject) error {
+	subject.ID = uuid.New()
+
+	query := `INSERT INTO subjects (id, heading, heading_key, parent_id, created_at)
+		VALUES ($1, $2, subject_heading_key($2), $3, CURRENT_TIMESTAMP) RETURNING created_at`
+	err := r.db.QueryRow(query, subject.ID, subject.Heading, subject.ParentID).Scan(&subject.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create subject %s: %w", subject.Heading, err)
+	}
+
+	return nil
+}
+
+func (r *subjectRepositoryImpl) GetSubjectByID(id uuid.UUID) (*model.Subject, error) {
+	subject := &model.Subject{}
+	query := `SELECT ` + subjectColumns + ` FROM subjects WHERE id = $1`
+	err := scanSubject(r.db.QueryRow(query, id), subject)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get subject by ID %s: %w", id.String(), err)
+	}
+
+	return subject, nil
+}
+
+// GetSubjectByHeading finds the subject whose heading matches ignoring case,
+// accents and punctuation.
+func (r *subjectRepositoryImpl) GetSubjectByHeading(heading string) (*model.Subject, error) {
+	subject := &model.Subject{}
+	query := `SELECT ` + subjectColumns + ` FROM subjects WHERE heading_key = subject_heading_key($1)`
+	err := scanSubject(r.db.QueryRow(query, heading), subject)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get subject by heading %s: %w", heading, err)
+	}
+
+	return subject, nil
+}
+
+// SearchSubjects lists headings alphabetically. A query searches the whole
+// vocabulary; otherwise the children of ParentID, or the top-level headings
+// when it is nil, are listed.
+func (r *subjectRepositoryImpl) SearchSubjects(filter model.SubjectFilter) ([]model.Subject, error) {
+	query := `SELECT ` + subjectColumns + ` FROM subjects
+		WHERE CASE
+			WHEN $1 <> '' THEN heading ILIKE '%' || $1 || '%' OR heading_key LIKE '%' || subject_heading_key($1) || '%'
+			WHEN $2::uuid IS NULL THEN parent_id IS NULL
+			ELSE parent_id = $2
+		END
+		ORDER BY heading LIMIT $3`
+	rows, err := r.db.Query(query, filter.Query, filter.ParentID, filter.Limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search subjects: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after searching subjects: %v", closeErr)
+		}
+	}()
+
+	subjects := make([]model.Subject, 0)
+
+	for rows.Next() {
+		subject := model.Subject{}
+		if err := scanSubject(rows, &subject); err != nil {
+			return nil, fmt.Errorf("failed to scan subject row: %w", err)
+		}
+		subjects = append(subjects, subject)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during subject rows iteration: %w", err)
+	}
+
+	return subjects, nil
+}
+
+// IsDescendant reports whether id is ancestorID itself or lies below it.
+func (r *subjectRepositoryImpl) IsDescendant(id uuid.UUID, ancestorID uuid.UUID) (bool, error) {
+	query := `WITH RECURSIVE tree AS (
+			SELECT id FROM subjects WHERE id = $2
+			UNION SELECT s.id FROM subjects s JOIN tree ON s.parent_id = tree.id
+		)
+		SELECT EXISTS (SELECT 1 FROM tree WHERE id = $1)`
+	var descendant bool
+	err := r.db.QueryRow(query, id, ancestorID).Scan(&descendant)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check subject hierarchy: %w", err)
+	}
+
+	return descendant, nil
+}
+
+func (r *subjectRepositoryImpl) UpdateSubject(subject *model.Subject) error {
+	query := `UPDATE subjects SET heading = $2, heading_key = subject_heading_key($2), parent_id = $3 WHERE id = $1`
+	res, err := r.db.Exec(query, subject.ID, subject.Heading, subject.ParentID)
+
+	if err != nil {
+		return fmt.Errorf("failed to update subject %s: %w", subject.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating subject %s: %w", subject.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("subject with ID %s not found for update", subject.ID)
+	}
+
+	return nil
+}
+
+// DeleteSubject removes a heading from the vocabulary and from the books
+// using it. Its narrower subjects become top-level headings.
+func (r *subjectRepositoryImpl) DeleteSubject(id uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM subjects WHERE id = $1`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete subject %s: %w", id.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after deleting subject %s: %w", id.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("subject with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
+
+// SetBookSubjects replaces the subject headings of a book.
+func (r *subjectRepositoryImpl) SetBookSubjects(bookID uuid.UUID, subjectIDs []uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM book_subjects WHERE book_id = $1`, bookID); err != nil {
+		return fmt.Errorf("failed to clear subjects of book %s: %w", bookID.String(), err)
+	}
+
+	query := `INSERT INTO book_subjects (book_id, subject_id) SELECT $1, unnest($2::uuid[]) ON CONFLICT DO NOTHING`
+	if _, err := r.db.Exec(query, bookID, pq.Array(subjectIDs)); err != nil {
+		return fmt.Errorf("failed to add subjects to book %s: %w", bookID.String(), err)
+	}
+
+	return nil
+}
+
+// GetTags lists the tags in use with how many books carry each, most used
+// first, optionally only those containing query.
+func (r *subjectRepositoryImpl) GetTags(query string, limit int) ([]model.TagCount, error) {
+	sqlQuery := `SELECT t.tag, COUNT(*) FROM books, unnest(books.tags) AS t(tag)
+		WHERE $1 = '' OR t.tag ILIKE '%' || $1 || '%'
+		GROUP BY t.tag ORDER BY COUNT(*) DESC, t.tag LIMIT $2`
+	rows, err := r.db.Query(sqlQuery, query, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after getting tags: %v", closeErr)
+		}
+	}()
+
+	tags := make([]model.TagCount, 0)
+
+	for rows.Next() {
+		tag := model.TagCount{}
+		if err := rows.Scan(&tag.Tag, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag row: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during tag rows iteration: %w", err)
+	}
+
+	return tags, nil
+}
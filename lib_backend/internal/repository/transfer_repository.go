@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+type TransferRepository interface {
+	WithTx(tx *sql.Tx) TransferRepository
+	CreateTransfer(transfer *model.Transfer) error
+	GetTransferByID(id uuid.UUID) (*model.Transfer, error)
+	UpdateTransfer(transfer *model.Transfer) error
+	GetOpenTransferByBookID(bookID uuid.UUID) (*model.Transfer, error)
+	SearchTransfers(filter model.TransferFilter) ([]model.Transfer, error)
+}
+
+const transferColumns = `id, book_id, from_branch_id, to_branch_id, hold_id, reason, status, requested_at, shipped_at, received_at`
+
+func scanTransfer(row rowScanner, transfer *model.Transfer) error {
+	return row.Scan(&transfer.ID, &transfer.BookID, &transfer.FromBranchID, &transfer.ToBranchID, &transfer.HoldID, &transfer.Reason, &transfer.Status, &transfer.RequestedAt, &transfer.ShippedAt, &transfer.ReceivedAt)
+}
+
+type transferRepositoryImpl struct {
+	db DBTX
+}
+
+func NewTransferRepository(db *sql.DB) TransferRepository {
+	return &transferRepositoryImpl{db: db}
+}
+
+func (r *transferRepositoryImpl) WithTx(tx *sql.Tx) TransferRepository {
+	return &transferRepositoryImpl{db: tx}
+}
+
+func (r *transferRepositoryImpl) CreateTransfer(transfer *model.Transfer) error {
+	transfer.ID = uuid.New()
+	transfer.RequestedAt = time.Now()
+
+	if transfer.Status == "" {
+		transfer.Status = model.TransferStatusRequested
+	}
+
+	query := `INSERT INTO transfers (` + transferColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.db.Exec(query, transfer.ID, transfer.BookID, transfer.FromBranchID, transfer.ToBranchID, transfer.HoldID, transfer.Reason, transfer.Status, transfer.RequestedAt, transfer.ShippedAt, transfer.ReceivedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create transfer for book ID %s: %w", transfer.BookID.String(), err)
+	}
+
+	return nil
+}
+
+func (r *transferRepositoryImpl) GetTransferByID(id uuid.UUID) (*model.Transfer, error) {
+	transfer := &model.Transfer{}
+	query := `SELECT ` + transferColumns + ` FROM transfers WHERE id = $1`
+	err := scanTransfer(r.db.QueryRow(query, id), transfer)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get transfer by ID %s: %w", id.String(), err)
+	}
+
+	return transfer, nil
+}
+
+func (r *transferRepositoryImpl) UpdateTransfer(transfer *model.Transfer) error {
+	query := `UPDATE transfers SET status = $2, shipped_at = $3, received_at = $4 WHERE id = $1`
+	res, err := r.db.Exec(query, transfer.ID, transfer.Status, transfer.ShippedAt, transfer.ReceivedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update transfer %s: %w", transfer.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating transfer %s: %w", transfer.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("transfer with ID %s not found for update", transfer.ID)
+	}
+
+	return nil
+}
+
+func (r *transferRepositoryImpl) GetOpenTransferByBookID(bookID uuid.UUID) (*model.Transfer, error) {
+	transfer := &model.Transfer{}
+	query := `SELECT ` + transferColumns + ` FROM transfers WHERE book_id = $1 AND status IN ('requested', 'in_transit')`
+	err := scanTransfer(r.db.QueryRow(query, bookID), transfer)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get open transfer for book ID %s: %w", bookID.String(), err)
+	}
+
+	return transfer, nil
+}
+
+func (r *transferRepositoryImpl) SearchTransfers(filter model.TransferFilter) ([]model.Transfer, error) {
+	conditions := make([]string, 0)
+	args := make([]any, 0)
+
+	addCondition := func(condition string, value any) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	if filter.FromBranchID != nil {
+		addCondition(`from_branch_id = ?`, *filter.FromBranchID)
+	}
+	if filter.ToBranchID != nil {
+		addCondition(`to_branch_id = ?`, *filter.ToBranchID)
+	}
+	if filter.Status != "" {
+		addCondition(`status = ?`, filter.Status)
+	}
+
+	query := `SELECT ` + transferColumns + ` FROM transfers`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY requested_at`
+
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transfers: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after searching transfers: %v", closeErr)
+		}
+	}()
+
+	transfers := make([]model.Transfer, 0)
+
+	for rows.Next() {
+		transfer := model.Transfer{}
+		if err := scanTransfer(rows, &transfer); err != nil {
+			return nil, fmt.Errorf("failed to scan transfer row: %w", err)
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during transfer rows iteration: %w", err)
+	}
+
+	return transfers, nil
+}
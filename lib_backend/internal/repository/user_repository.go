@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
 	"lib_backend/internal/model"
 
@@ -11,29 +12,49 @@ import (
 )
 
 type UserRepository interface {
+	WithTx(tx *sql.Tx) UserRepository
 	CreateUser(user *model.User) error
 	GetUserByID(id uuid.UUID) (*model.User, error)
+	LockUser(id uuid.UUID) error
 	GetUserByEmail(email string) (*model.User, error)
+	GetUserByRegistration(registration string) (*model.User, error)
+	NextRegistrationSequence() (int64, error)
 	UpdateUser(user *model.User) error
 	DeleteUser(id uuid.UUID) error
+	EraseUser(id uuid.UUID, erasedAt time.Time) error
 	GetAllUsers() ([]model.User, error)
 }
 
+const userColumns = `id, name, registration, email, category, language, keep_loan_history, erased_at`
+
+func scanUser(row rowScanner, user *model.User) error {
+	var keepLoanHistory bool
+
+	if err := row.Scan(&user.ID, &user.Name, &user.Registration, &user.Email, &user.Category, &user.Language, &keepLoanHistory, &user.ErasedAt); err != nil {
+		return err
+	}
+
+	user.KeepLoanHistory = &keepLoanHistory
+	return nil
+}
+
 type userRepositoryImpl struct {
-	db *sql.DB
+	db DBTX
 }
 
 func NewUserRepository(db *sql.DB) UserRepository {
 	return &userRepositoryImpl{db: db}
 }
 
+func (r *userRepositoryImpl) WithTx(tx *sql.Tx) UserRepository {
+	return &userRepositoryImpl{db: tx}
+}
+
 func (r *userRepositoryImpl) CreateUser(user *model.User) error {
 	user.ID = uuid.New()
 
-	user.Registration = uuid.New().String()
-
-	query := `INSERT INTO users (id, name, registration, email) VALUES ($1, $2, $3, $4)`
-	_, err := r.db.Exec(query, user.ID, user.Name, user.Registration, user.Email)
+	query := `INSERT INTO users (id, name, registration, email, category, language, keep_loan_history) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Exec(query, user.ID, user.Name, user.Registration, user.Email, user.Category, user.Language, user.KeepLoanHistory != nil && *user.KeepLoanHistory)
 
 	if err != nil {
 		return fmt.Errorf("failed to create user with email %s and registration %s: %w", user.Email, user.Registration, err)
@@ -44,8 +65,8 @@ func (r *userRepositoryImpl) CreateUser(user *model.User) error {
 
 func (r *userRepositoryImpl) GetUserByID(id uuid.UUID) (*model.User, error) {
 	user := &model.User{}
-	query := `SELECT id, name, registration, email FROM users WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Name, &user.Registration, &user.Email)
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	err := scanUser(r.db.QueryRow(query, id), user)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -56,10 +77,25 @@ func (r *userRepositoryImpl) GetUserByID(id uuid.UUID) (*model.User, error) {
 	return user, nil
 }
 
+// LockUser locks the user row until the transaction ends, so requests that
+// check and then use up a patron's limits run one at a time per patron.
+func (r *userRepositoryImpl) LockUser(id uuid.UUID) error {
+	var lockedID uuid.UUID
+	err := r.db.QueryRow(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, id).Scan(&lockedID)
+
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("user with ID %s not found for lock", id.String())
+	} else if err != nil {
+		return fmt.Errorf("failed to lock user ID %s: %w", id.String(), err)
+	}
+
+	return nil
+}
+
 func (r *userRepositoryImpl) GetUserByEmail(email string) (*model.User, error) {
 	user := &model.User{}
-	query := `SELECT id, name, registration, email FROM users WHERE email = $1`
-	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Name, &user.Registration, &user.Email)
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
+	err := scanUser(r.db.QueryRow(query, email), user)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -70,9 +106,34 @@ func (r *userRepositoryImpl) GetUserByEmail(email string) (*model.User, error) {
 	return user, nil
 }
 
+func (r *userRepositoryImpl) GetUserByRegistration(registration string) (*model.User, error) {
+	user := &model.User{}
+	query := `SELECT ` + userColumns + ` FROM users WHERE registration = $1`
+	err := scanUser(r.db.QueryRow(query, registration), user)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get user by registration %s: %w", registration, err)
+	}
+
+	return user, nil
+}
+
+func (r *userRepositoryImpl) NextRegistrationSequence() (int64, error) {
+	var next int64
+	err := r.db.QueryRow(`SELECT nextval('registration_seq')`).Scan(&next)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next registration sequence: %w", err)
+	}
+
+	return next, nil
+}
+
 func (r *userRepositoryImpl) UpdateUser(user *model.User) error {
-	query := `UPDATE users SET name = $2, registration = $3, email = $4 WHERE id = $1`
-	res, err := r.db.Exec(query, user.ID, user.Name, user.Registration, user.Email)
+	query := `UPDATE users SET name = $2, registration = $3, email = $4, category = $5, language = $6, keep_loan_history = $7 WHERE id = $1`
+	res, err := r.db.Exec(query, user.ID, user.Name, user.Registration, user.Email, user.Category, user.Language, user.KeepLoanHistory != nil && *user.KeepLoanHistory)
 
 	if err != nil {
 		return fmt.Errorf("failed to execute update query for user ID %s: %w", user.ID.String(), err)
@@ -111,7 +172,7 @@ func (r *userRepositoryImpl) DeleteUser(id uuid.UUID) error {
 }
 
 func (r *userRepositoryImpl) GetAllUsers() ([]model.User, error) {
-	query := `SELECT id, name, registration, email FROM users`
+	query := `SELECT ` + userColumns + ` FROM users`
 	rows, err := r.db.Query(query)
 
 	if err != nil {
@@ -127,7 +188,7 @@ func (r *userRepositoryImpl) GetAllUsers() ([]model.User, error) {
 
 	for rows.Next() {
 		user := model.User{}
-		if err := rows.Scan(&user.ID, &user.Name, &user.Registration, &user.Email); err != nil {
+		if err := scanUser(rows, &user); err != nil {
 			return nil, fmt.Errorf("failed to scan user row into struct: %w", err)
 		}
 		users = append(users, user)
@@ -139,3 +200,27 @@ func (r *userRepositoryImpl) GetAllUsers() ([]model.User, error) {
 
 	return users, nil
 }
+
+// EraseUser replaces the personal data of a user with placeholders derived
+// from its ID, which keep registration and email unique.
+func (r *userRepositoryImpl) EraseUser(id uuid.UUID, erasedAt time.Time) error {
+	query := `UPDATE users SET name = 'Erased patron', registration = 'ERASED-' || replace(id::text, '-', ''),
+		email = replace(id::text, '-', '') || '@erased.invalid', keep_loan_history = FALSE, erased_at = $2
+		WHERE id = $1`
+	res, err := r.db.Exec(query, id, erasedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to erase user ID %s: %w", id.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after erasing user ID %s: %w", id.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user with ID %s not found for erasure", id)
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type WebhookRepository interface {
+	WithTx(tx *sql.Tx) WebhookRepository
+	CreateSubscription(subscription *model.WebhookSubscription) error
+	GetSubscriptionByID(id uuid.UUID) (*model.WebhookSubscription, error)
+	UpdateSubscription(subscription *model.WebhookSubscription) error
+	DeleteSubscription(id uuid.UUID) error
+	GetAllSubscriptions() ([]model.WebhookSubscription, error)
+	GetActiveSubscriptionsForEvent(eventType string) ([]model.WebhookSubscription, error)
+
+	CreateDelivery(delivery *model.WebhookDelivery) error
+	GetDeliveryByID(id uuid.UUID) (*model.WebhookDelivery, error)
+	HasDelivery(subscriptionID uuid.UUID, eventID uuid.UUID) (bool, error)
+	UpdateDelivery(delivery *model.WebhookDelivery) error
+	GetDeliveriesBySubscriptionID(subscriptionID uuid.UUID, limit int) ([]model.WebhookDelivery, error)
+	GetDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error)
+	RedactUserDeliveries(userID uuid.UUID) (int64, error)
+	RedactDeliveriesBefore(before time.Time) (int64, error)
+	PurgeDeliveriesBefore(before time.Time) (int64, error)
+}
+
+const webhookSubscriptionColumns = `id, url, event_types, secret, active, created_at`
+
+func scanWebhookSubscription(row rowScanner, subscription *model.WebhookSubscription) error {
+	return row.Scan(&subscription.ID, &subscription.URL, pq.Array(&subscription.EventTypes), &subscription.Secret, &subscription.Active, &subscription.CreatedAt)
+}
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, created_at, delivered_at`
+
+func scanWebhookDelivery(row rowScanner, delivery *model.WebhookDelivery) error {
+	return row.Scan(&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType, &delivery.Payload, &delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode, &delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt)
+}
+
+type webhookRepositoryImpl struct {
+	db DBTX
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepositoryImpl{db: db}
+}
+
+func (r *webhookRepositoryImpl) WithTx(tx *sql.Tx) WebhookRepository {
+	return &webhookRepositoryImpl{db: tx}
+}
+
+func (r *webhookRepositoryImpl) CreateSubscription(subscription *model.WebhookSubscription) error {
+	subscription.ID = uuid.New()
+	subscription.CreatedAt = time.Now()
+
+	query := `INSERT INTO webhook_subscriptions (` + webhookSubscriptionColumns + `) VALUES ($1, $2, $3, $4, $5, $6)`
+	_, err := r.db.Exec(query, subscription.ID, subscription.URL, pq.Array(subscription.EventTypes), subscription.Secret, subscription.Active, subscription.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription for %s: %w", subscription.URL, err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepositoryImpl) GetSubscriptionByID(id uuid.UUID) (*model.WebhookSubscription, error) {
+	subscription := &model.WebhookSubscription{}
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	err := scanWebhookSubscription(r.db.QueryRow(query, id), subscription)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription by ID %s: %w", id.String(), err)
+	}
+
+	return subscription, nil
+}
+
+func (r *webhookRepositoryImpl) UpdateSubscription(subscription *model.WebhookSubscription) error {
+	query := `UPDATE webhook_subscriptions SET url = $2, event_types = $3, secret = $4, active = $5 WHERE id = $1`
+	res, err := r.db.Exec(query, subscription.ID, subscription.URL, pq.Array(subscription.EventTypes), subscription.Secret, subscription.Active)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription %s: %w", subscription.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating webhook subscription %s: %w", subscription.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription with ID %s not found for update", subscription.ID)
+	}
+
+	return nil
+}
+
+func (r *webhookRepositoryImpl) DeleteSubscription(id uuid.UUID) error {
+	res, err := r.db.Exec(`DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription %s: %w", id.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after deleting webhook subscription %s: %w", id.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook subscription with ID %s not found for deletion", id)
+	}
+
+	return nil
+}
+
+func (r *webhookRepositoryImpl) GetAllSubscriptions() ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions ORDER BY created_at`
+	return r.querySubscriptions(query)
+}
+
+func (r *webhookRepositoryImpl) GetActiveSubscriptionsForEvent(eventType string) ([]model.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE active AND $1 = ANY(event_types) ORDER BY created_at`
+	return r.querySubscriptions(query, eventType)
+}
+
+func (r *webhookRepositoryImpl) querySubscriptions(query string, args ...any) ([]model.WebhookSubscription, error) {
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook subscriptions: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after querying webhook subscriptions: %v", closeErr)
+		}
+	}()
+
+	subscriptions := make([]model.WebhookSubscription, 0)
+
+	for rows.Next() {
+		subscription := model.WebhookSubscription{}
+		if err := scanWebhookSubscription(rows, &subscription); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription row: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during webhook subscription rows iteration: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *webhookRepositoryImpl) CreateDelivery(delivery *model.WebhookDelivery) error {
+	delivery.ID = uuid.New()
+	delivery.CreatedAt = time.Now()
+
+	if delivery.Status == "" {
+		delivery.Status = model.WebhookDeliveryPending
+	}
+	if delivery.NextAttemptAt.IsZero() {
+		delivery.NextAttemptAt = delivery.CreatedAt
+	}
+
+	query := `INSERT INTO webhook_deliveries (` + webhookDeliveryColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err := r.db.Exec(query, delivery.ID, delivery.SubscriptionID, delivery.EventID, delivery.EventType, []byte(delivery.Payload), delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError, delivery.CreatedAt, delivery.DeliveredAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery of event %s: %w", delivery.EventID.String(), err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepositoryImpl) GetDeliveryByID(id uuid.UUID) (*model.WebhookDelivery, error) {
+	delivery := &model.WebhookDelivery{}
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	err := scanWebhookDelivery(r.db.QueryRow(query, id), delivery)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery by ID %s: %w", id.String(), err)
+	}
+
+	return delivery, nil
+}
+
+func (r *webhookRepositoryImpl) HasDelivery(subscriptionID uuid.UUID, eventID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM webhook_deliveries WHERE subscription_id = $1 AND event_id = $2)`
+	err := r.db.QueryRow(query, subscriptionID, eventID).Scan(&exists)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to check webhook delivery of event %s: %w", eventID.String(), err)
+	}
+
+	return exists, nil
+}
+
+func (r *webhookRepositoryImpl) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	query := `UPDATE webhook_deliveries SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7 WHERE id = $1`
+	res, err := r.db.Exec(query, delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode, delivery.LastError, delivery.DeliveredAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery %s: %w", delivery.ID.String(), err)
+	}
+	rowsAffected, err := res.RowsAffected()
+
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected after updating webhook delivery %s: %w", delivery.ID.String(), err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook delivery with ID %s not found for update", delivery.ID)
+	}
+
+	return nil
+}
+
+func (r *webhookRepositoryImpl) GetDeliveriesBySubscriptionID(subscriptionID uuid.UUID, limit int) ([]model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE subscription_id = $1 ORDER BY created_at DESC LIMIT $2`
+	return r.queryDeliveries(query, subscriptionID, limit)
+}
+
+// GetDueDeliveries returns pending deliveries of active subscriptions whose
+// next attempt is due, oldest first. Deliveries of a paused subscription wait
+// until it is active again.
+func (r *webhookRepositoryImpl) GetDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries
+		WHERE status = 'pending' AND next_attempt_at <= $1
+		AND subscription_id IN (SELECT id FROM webhook_subscriptions WHERE active)
+		ORDER BY next_attempt_at LIMIT $2`
+	return r.queryDeliveries(query, now, limit)
+}
+
+func (r *webhookRepositoryImpl) queryDeliveries(query string, args ...any) ([]model.WebhookDelivery, error) {
+	rows, err := r.db.Query(query, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close rows after querying webhook deliveries: %v", closeErr)
+		}
+	}()
+
+	deliveries := make([]model.WebhookDelivery, 0)
+
+	for rows.Next() {
+		delivery := model.WebhookDelivery{}
+		if err := scanWebhookDelivery(rows, &delivery); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery row: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during webhook delivery rows iteration: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// redactedDeliveryData is the event data of a delivery once stripped of its
+// patron, as redactedEventData does for the outbox.
+const redactedDeliveryData = `CASE WHEN event_type LIKE 'user.%' THEN jsonb_build_object('id', payload->'data'->'id')
+	ELSE (payload->'data') - 'user_id' END`
+
+// RedactUserDeliveries applies the redaction of RedactUserEvents to the event
+// copies queued or kept as webhook deliveries.
+func (r *webhookRepositoryImpl) RedactUserDeliveries(userID uuid.UUID) (int64, error) {
+	query := `UPDATE webhook_deliveries SET payload = jsonb_set(payload, '{data}', ` + redactedDeliveryData + `)
+		WHERE payload->'data'->>'user_id' = $1 OR (event_type LIKE 'user.%' AND payload->'data'->>'id' = $1)`
+	res, err := r.db.Exec(query, userID.String())
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to redact webhook deliveries of user ID %s: %w", userID.String(), err)
+	}
+
+	return res.RowsAffected()
+}
+
+// RedactDeliveriesBefore applies the redaction of RedactEventsBefore to the
+// deliveries created before the given time, pending ones included.
+func (r *webhookRepositoryImpl) RedactDeliveriesBefore(before time.Time) (int64, error) {
+	query := `UPDATE webhook_deliveries SET payload = jsonb_set(payload, '{data}', ` + redactedDeliveryData + `)
+		WHERE created_at < $1 AND payload->'data' <> ` + redactedDeliveryData
+	res, err := r.db.Exec(query, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to redact webhook deliveries: %w", err)
+	}
+
+	return res.RowsAffected()
+}
+
+// PurgeDeliveriesBefore deletes finished deliveries created before the given
+// time; pending ones are kept until they succeed or give up.
+func (r *webhookRepositoryImpl) PurgeDeliveriesBefore(before time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM webhook_deliveries WHERE created_at < $1 AND status <> 'pending'`, before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+
+	return res.RowsAffected()
+}
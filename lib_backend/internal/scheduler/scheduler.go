@@ -0,0 +1,378 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+
+	"github.com/robfig/cron/v3"
+)
+
+// leaderLockKey is the advisory lock held by the replica that runs scheduled jobs.
+const leaderLockKey = "lib_backend:scheduler:leader"
+
+// leaderCheckInterval is how often followers try to take over leadership and
+// the leader checks it still holds the lock.
+const leaderCheckInterval = 15 * time.Second
+
+// JobFunc runs a job and returns a short summary of what it did.
+type JobFunc func(ctx context.Context) (string, error)
+
+// Job is a named unit of work run on a cron schedule (standard five-field
+// expressions or descriptors like @hourly). Failed attempts are retried up to
+// MaxRetries times, waiting Backoff and doubling it after each failure.
+type Job struct {
+	Name       string
+	Schedule   string
+	MaxRetries int
+	Backoff    time.Duration
+	Run        JobFunc
+
+	schedule cron.Schedule
+}
+
+type JobStatus struct {
+	Name     string        `json:"name"`
+	Schedule string        `json:"schedule"`
+	NextRun  time.Time     `json:"next_run"`
+	LastRun  *model.JobRun `json:"last_run,omitempty"`
+}
+
+// Scheduler runs jobs in process. Every replica keeps the schedule, but only
+// the one holding the leader advisory lock runs scheduled jobs, and each run
+// also takes a per-job advisory lock so manual and scheduled runs never overlap.
+type Scheduler struct {
+	db      *sql.DB
+	runRepo repository.JobRunRepository
+	jobs    []*Job
+
+	mu     sync.Mutex
+	leader *sql.Conn
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(db *sql.DB, runRepo repository.JobRunRepository) *Scheduler {
+	return &Scheduler{db: db, runRepo: runRepo}
+}
+
+func (s *Scheduler) Register(job Job) error {
+	schedule, err := cron.ParseStandard(job.Schedule)
+
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q for job %s: %w", job.Schedule, job.Name, err)
+	}
+
+	if s.job(job.Name) != nil {
+		return fmt.Errorf("job %s is already registered", job.Name)
+	}
+
+	job.schedule = schedule
+	s.jobs = append(s.jobs, &job)
+
+	return nil
+}
+
+func (s *Scheduler) job(name string) *Job {
+	for _, job := range s.jobs {
+		if job.Name == name {
+			return job
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.elect(ctx)
+	}()
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job *Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+
+	log.Printf("Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Stop cancels the schedule and waits for running jobs to finish.
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) IsLeader() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leader != nil
+}
+
+func (s *Scheduler) elect(ctx context.Context) {
+	ticker := time.NewTicker(leaderCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkLeadership(ctx)
+
+		select {
+		case <-ctx.Done():
+			s.resign()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) checkLeadership(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.leader != nil {
+		if err := s.leader.PingContext(ctx); err == nil {
+			return
+		}
+		// the session holding the lock is gone, and the lock with it
+		log.Printf("WARNING: scheduler lost leadership")
+		if err := s.leader.Close(); err != nil {
+			log.Printf("ERROR: failed to close scheduler leader connection: %v", err)
+		}
+		s.leader = nil
+	}
+
+	conn, acquired, err := s.tryLock(ctx, leaderLockKey)
+
+	if err != nil {
+		log.Printf("ERROR: scheduler leader election failed: %v", err)
+		return
+	}
+
+	if acquired {
+		s.leader = conn
+		log.Printf("Scheduler is now the leader")
+	}
+}
+
+func (s *Scheduler) resign() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.leader != nil {
+		s.unlock(s.leader, leaderLockKey)
+		s.leader = nil
+	}
+}
+
+// tryLock takes a session-level advisory lock on a dedicated connection,
+// which must be kept open for as long as the lock is needed.
+func (s *Scheduler) tryLock(ctx context.Context, key string) (*sql.Conn, bool, error) {
+	conn, err := s.db.Conn(ctx)
+
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection for advisory lock %s: %w", key, err)
+	}
+
+	var acquired bool
+	err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, key).Scan(&acquired)
+
+	if err != nil || !acquired {
+		if closeErr := conn.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close advisory lock connection: %v", closeErr)
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to take advisory lock %s: %w", key, err)
+		}
+		return nil, false, nil
+	}
+
+	return conn, true, nil
+}
+
+func (s *Scheduler) unlock(conn *sql.Conn, key string) {
+	if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, key); err != nil {
+		log.Printf("ERROR: failed to release advisory lock %s: %v", key, err)
+	}
+	if err := conn.Close(); err != nil {
+		log.Printf("ERROR: failed to close advisory lock connection: %v", err)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *Job) {
+	for {
+		timer := time.NewTimer(time.Until(job.schedule.Next(time.Now())))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !s.IsLeader() {
+			continue
+		}
+
+		run, lock, err := s.begin(ctx, job, model.JobTriggerSchedule)
+
+		if err != nil {
+			log.Printf("WARNING: skipping scheduled run of job %s: %v", job.Name, err)
+			continue
+		}
+
+		s.execute(ctx, job, run, lock)
+	}
+}
+
+// Trigger starts a job outside its schedule. The run is recorded before this
+// returns and executes in the background.
+func (s *Scheduler) Trigger(name string) (*model.JobRun, error) {
+	job := s.job(name)
+
+	if job == nil {
+		return nil, fmt.Errorf("job %s not found", name)
+	}
+
+	ctx := context.Background()
+	run, lock, err := s.begin(ctx, job, model.JobTriggerManual)
+
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := *run
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(ctx, job, run, lock)
+	}()
+
+	return &snapshot, nil
+}
+
+func jobLockKey(name string) string {
+	return "lib_backend:job:" + name
+}
+
+func (s *Scheduler) begin(ctx context.Context, job *Job, trigger string) (*model.JobRun, *sql.Conn, error) {
+	lock, acquired, err := s.tryLock(ctx, jobLockKey(job.Name))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !acquired {
+		return nil, nil, fmt.Errorf("job %s is already running", job.Name)
+	}
+
+	run := &model.JobRun{JobName: job.Name, Trigger: trigger, Status: model.JobRunStatusRunning}
+
+	if err := s.runRepo.CreateRun(run); err != nil {
+		s.unlock(lock, jobLockKey(job.Name))
+		return nil, nil, err
+	}
+
+	return run, lock, nil
+}
+
+func (s *Scheduler) execute(ctx context.Context, job *Job, run *model.JobRun, lock *sql.Conn) {
+	defer s.unlock(lock, jobLockKey(job.Name))
+
+	backoff := job.Backoff
+	var result string
+	var err error
+
+	for {
+		run.Attempts++
+		result, err = s.attempt(ctx, job)
+
+		if err == nil || run.Attempts > job.MaxRetries {
+			break
+		}
+
+		log.Printf("WARNING: job %s attempt %d failed, retrying in %s: %v", job.Name, run.Attempts, backoff, err)
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			break
+		}
+		backoff *= 2
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+
+	if err != nil {
+		message := err.Error()
+		run.Status = model.JobRunStatusFailed
+		run.Error = &message
+		log.Printf("ERROR: job %s failed after %d attempts: %v", job.Name, run.Attempts, err)
+	} else {
+		run.Status = model.JobRunStatusSucceeded
+		run.Result = &result
+	}
+
+	if err := s.runRepo.UpdateRun(run); err != nil {
+		log.Printf("ERROR: failed to record run of job %s: %v", job.Name, err)
+	}
+}
+
+// attempt runs the job once, turning a panic into an error so a broken job
+// cannot take the server down.
+func (s *Scheduler) attempt(ctx context.Context, job *Job) (result string, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job %s panicked: %v", job.Name, recovered)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+func (s *Scheduler) Jobs() ([]JobStatus, error) {
+	statuses := make([]JobStatus, 0, len(s.jobs))
+
+	for _, job := range s.jobs {
+		lastRun, err := s.runRepo.GetLastRun(job.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, JobStatus{Name: job.Name, Schedule: job.Schedule, NextRun: job.schedule.Next(time.Now()), LastRun: lastRun})
+	}
+
+	return statuses, nil
+}
+
+func (s *Scheduler) Runs(name string, limit int) ([]model.JobRun, error) {
+	if s.job(name) == nil {
+		return nil, fmt.Errorf("job %s not found", name)
+	}
+
+	return s.runRepo.GetRunsByJobName(name, limit)
+}
+
+func (s *Scheduler) PurgeRuns(before time.Time) (int64, error) {
+	return s.runRepo.PurgeRunsBefore(before)
+}
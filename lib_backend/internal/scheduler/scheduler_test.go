@@ -0,0 +1,257 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+)
+
+// lockServer stands in for Postgres session-level advisory locks: a lock
+// belongs to the connection that took it until that connection releases it.
+type lockServer struct {
+	mu    sync.Mutex
+	owner map[string]*lockConn
+}
+
+func newLockDB(t *testing.T, server *lockServer) *sql.DB {
+	db := sql.OpenDB(server)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func (s *lockServer) Connect(ctx context.Context) (driver.Conn, error) {
+	return &lockConn{server: s}, nil
+}
+
+func (s *lockServer) Driver() driver.Driver { return s }
+
+func (s *lockServer) Open(name string) (driver.Conn, error) { return &lockConn{server: s}, nil }
+
+type lockConn struct {
+	server *lockServer
+}
+
+func (c *lockConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepared statements are not supported")
+}
+
+func (c *lockConn) Close() error {
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+	for key, owner := range c.server.owner {
+		if owner == c {
+			delete(c.server.owner, key)
+		}
+	}
+	return nil
+}
+
+func (c *lockConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+func (c *lockConn) Ping(ctx context.Context) error { return nil }
+
+func (c *lockConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if !strings.Contains(query, "pg_try_advisory_lock") {
+		return nil, errors.New("unexpected query: " + query)
+	}
+
+	key := args[0].Value.(string)
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+
+	if c.server.owner == nil {
+		c.server.owner = make(map[string]*lockConn)
+	}
+	owner, held := c.server.owner[key]
+	if !held {
+		c.server.owner[key] = c
+	}
+
+	return &boolRows{value: !held || owner == c}, nil
+}
+
+func (c *lockConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if !strings.Contains(query, "pg_advisory_unlock") {
+		return nil, errors.New("unexpected statement: " + query)
+	}
+
+	key := args[0].Value.(string)
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+
+	if c.server.owner[key] == c {
+		delete(c.server.owner, key)
+	}
+	return driver.RowsAffected(0), nil
+}
+
+type boolRows struct {
+	value bool
+	read  bool
+}
+
+func (r *boolRows) Columns() []string { return []string{"acquired"} }
+
+func (r *boolRows) Close() error { return nil }
+
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+	return nil
+}
+
+// every fires at a sub-second interval, which cron descriptors cannot express.
+type every time.Duration
+
+func (e every) Next(t time.Time) time.Time { return t.Add(time.Duration(e)) }
+
+type fakeRunRepo struct {
+	repository.JobRunRepository
+	mu   sync.Mutex
+	runs []model.JobRun
+}
+
+func (r *fakeRunRepo) CreateRun(run *model.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, *run)
+	return nil
+}
+
+func (r *fakeRunRepo) UpdateRun(run *model.JobRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs[len(r.runs)-1] = *run
+	return nil
+}
+
+func (r *fakeRunRepo) recorded() []model.JobRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]model.JobRun(nil), r.runs...)
+}
+
+func TestOnlyLeaderRunsScheduledJobs(t *testing.T) {
+	server := &lockServer{}
+	var runs [2]atomic.Int32
+	var replicas [2]*Scheduler
+	var repos [2]*fakeRunRepo
+
+	for i := range replicas {
+		repos[i] = &fakeRunRepo{}
+		replicas[i] = New(newLockDB(t, server), repos[i])
+		err := replicas[i].Register(Job{Name: "sweep", Schedule: "@hourly", Run: func(ctx context.Context) (string, error) {
+			runs[i].Add(1)
+			return "done", nil
+		}})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		replicas[i].jobs[0].schedule = every(20 * time.Millisecond)
+	}
+
+	replicas[0].Start()
+	// the first replica takes the leader lock before the second one starts
+	for !replicas[0].IsLeader() {
+		time.Sleep(time.Millisecond)
+	}
+	replicas[1].Start()
+	time.Sleep(200 * time.Millisecond)
+	replicas[1].Stop()
+	replicas[0].Stop()
+
+	if replicas[1].IsLeader() || replicas[0].IsLeader() {
+		t.Fatal("a stopped replica still holds leadership")
+	}
+	if runs[0].Load() == 0 {
+		t.Fatal("leader never ran the scheduled job")
+	}
+	if got := runs[1].Load(); got != 0 || len(repos[1].recorded()) != 0 {
+		t.Fatalf("follower ran the scheduled job %d times", got)
+	}
+	for _, run := range repos[0].recorded() {
+		if run.Trigger != model.JobTriggerSchedule || run.Status != model.JobRunStatusSucceeded {
+			t.Fatalf("leader run = %+v, want a succeeded scheduled run", run)
+		}
+	}
+}
+
+func TestLeadershipPassesToFollowerOnResign(t *testing.T) {
+	server := &lockServer{}
+	leader := New(newLockDB(t, server), &fakeRunRepo{})
+	follower := New(newLockDB(t, server), &fakeRunRepo{})
+	ctx := context.Background()
+
+	leader.checkLeadership(ctx)
+	follower.checkLeadership(ctx)
+
+	if !leader.IsLeader() || follower.IsLeader() {
+		t.Fatalf("leader = %v, follower = %v, want exactly the first replica leading", leader.IsLeader(), follower.IsLeader())
+	}
+
+	leader.resign()
+	follower.checkLeadership(ctx)
+
+	if leader.IsLeader() || !follower.IsLeader() {
+		t.Fatalf("leader = %v, follower = %v, want the follower to take over", leader.IsLeader(), follower.IsLeader())
+	}
+	follower.resign()
+}
+
+func TestTriggerSkipsJobWhileItsLockIsHeld(t *testing.T) {
+	server := &lockServer{}
+	release := make(chan struct{})
+	job := Job{Name: "sweep", Schedule: "@hourly", Run: func(ctx context.Context) (string, error) {
+		<-release
+		return "done", nil
+	}}
+
+	busyRepo, idleRepo := &fakeRunRepo{}, &fakeRunRepo{}
+	busy := New(newLockDB(t, server), busyRepo)
+	idle := New(newLockDB(t, server), idleRepo)
+	for _, s := range []*Scheduler{busy, idle} {
+		if err := s.Register(job); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	if _, err := busy.Trigger("sweep"); err != nil {
+		t.Fatalf("first Trigger() error = %v", err)
+	}
+
+	if _, err := idle.Trigger("sweep"); err == nil || err.Error() != "job sweep is already running" {
+		t.Fatalf("Trigger() on another replica error = %v, want already running", err)
+	}
+	if _, err := busy.Trigger("sweep"); err == nil {
+		t.Fatal("Trigger() on the same replica succeeded while the job was running")
+	}
+	if runs := idleRepo.recorded(); len(runs) != 0 {
+		t.Fatalf("skipped trigger recorded %d runs, want none", len(runs))
+	}
+
+	close(release)
+	busy.Stop()
+
+	if runs := busyRepo.recorded(); len(runs) != 1 || runs[0].Status != model.JobRunStatusSucceeded {
+		t.Fatalf("runs = %+v, want one succeeded run", runs)
+	}
+	if _, err := idle.Trigger("sweep"); err != nil {
+		t.Fatalf("Trigger() after the run finished error = %v", err)
+	}
+	idle.Stop()
+}
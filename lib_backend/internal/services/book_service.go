@@ -1,32 +1,411 @@
 package services
 
 import (
+	"database/sql"
 	"fmt"
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
 	"lib_backend/internal/model"
 	"lib_backend/internal/repository"
 	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type BookService interface {
-	CreateBook(book *model.Book) (*model.Book, error)
+	CreateBook(book *model.Book, enrich bool) (*model.Book, error)
 	GetBookByID(id uuid.UUID) (*model.Book, error)
 	GetBookByISBN(isbn string) (*model.Book, error)
+	GetBookByBarcode(barcode string) (*model.Book, error)
 	UpdateBook(book *model.Book) (*model.Book, error)
 	DeleteBook(id uuid.UUID) error
 	GetAllBooks() ([]model.Book, error)
+	SearchBooks(filter model.BookFilter) ([]model.Book, error)
+	SearchCatalog(filter model.BookFilter, facetLimit int) (*model.CatalogPage, error)
 }
 
 type bookServiceImpl struct {
-	bookRepo repository.BookRepository
+	bookRepo        repository.BookRepository
+	branchRepo      repository.BranchRepository
+	transactor      repository.Transactor
+	outboxRepo      repository.OutboxRepository
+	metadataService MetadataService
+	coverService    CoverService
+	contributorRepo repository.ContributorRepository
+	subjectRepo     repository.SubjectRepository
+	seriesRepo      repository.SeriesRepository
+	barcodeConfig   config.RegistrationConfig
 }
 
-func NewBookService(bookRepo repository.BookRepository) BookService {
-	return &bookServiceImpl{bookRepo: bookRepo}
+func NewBookService(bookRepo repository.BookRepository, branchRepo repository.BranchRepository, contributorRepo repository.ContributorRepository, subjectRepo repository.SubjectRepository, seriesRepo repository.SeriesRepository, transactor repository.Transactor, outboxRepo repository.OutboxRepository, metadataService MetadataService, coverService CoverService, barcodeConfig config.RegistrationConfig) BookService {
+	return &bookServiceImpl{bookRepo: bookRepo, branchRepo: branchRepo, contributorRepo: contributorRepo, subjectRepo: subjectRepo, seriesRepo: seriesRepo, transactor: transactor, outboxRepo: outboxRepo, metadataService: metadataService, coverService: coverService, barcodeConfig: barcodeConfig}
 }
 
-func (s *bookServiceImpl) CreateBook(book *model.Book) (*model.Book, error) {
+func (s *bookServiceImpl) checkBranches(book *model.Book) error {
+	if book.CurrentBranchID == nil {
+		book.CurrentBranchID = book.HomeBranchID
+	}
+
+	for _, branchID := range []*uuid.UUID{book.HomeBranchID, book.CurrentBranchID} {
+		if branchID == nil {
+			continue
+		}
+
+		branch, err := s.branchRepo.GetBranchByID(*branchID)
+		if err != nil {
+			return fmt.Errorf("failed to check branch of book: %w", err)
+		}
+
+		if branch == nil {
+			return fmt.Errorf("branch with ID %s does not exist", branchID.String())
+		}
+	}
+
+	return nil
+}
+
+// enrich fills the fields left blank from the ISBN metadata. A failed lookup
+// only stops the creation when the book would be left without a title.
+func (s *bookServiceImpl) enrich(book *model.Book) error {
+	bookMetadata, err := s.metadataService.LookupISBN(book.Isbn)
+
+	if err != nil {
+		if book.Title == "" {
+			return err
+		}
+		log.Printf("WARNING: could not enrich book with ISBN %s: %v", book.Isbn, err)
+		return nil
+	}
+
+	if book.Title == "" {
+		book.Title = bookMetadata.Title
+	}
+
+	if book.Author == "" && book.Contributors == nil {
+		for _, author := range bookMetadata.Authors {
+			book.Contributors = append(book.Contributors, model.BookContributor{Name: author, Role: model.ContributorRoleAuthor})
+		}
+	}
+
+	if book.Subtitle == "" {
+		book.Subtitle = bookMetadata.Subtitle
+	}
+
+	if book.Publisher == "" {
+		book.Publisher = bookMetadata.Publisher
+	}
+
+	if book.PublicationYear == nil {
+		book.PublicationYear = bookMetadata.Year
+	}
+
+	if book.PageCount == nil {
+		book.PageCount = bookMetadata.PageCount
+	}
+
+	// catalog subjects are only kept as headings when they are already in the
+	// vocabulary; the rest still help finding the book as tags
+	if len(book.Subjects) == 0 {
+		for _, heading := range bookMetadata.Subjects {
+			subject, err := s.subjectRepo.GetSubjectByHeading(heading)
+			if err != nil {
+				return fmt.Errorf("failed to match metadata subject: %w", err)
+			}
+
+			if subject != nil {
+				book.Subjects = append(book.Subjects, subject.Heading)
+			} else {
+				book.Tags = append(book.Tags, heading)
+			}
+		}
+	}
+
+	return nil
+}
+
+var languageCodePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// checkBibliographic trims the descriptive fields and rejects values that
+// cannot be right. Subjects are deduplicated ignoring case and tags are
+// normalized.
+func checkBibliographic(book *model.Book) error {
+	for _, field := range []*string{&book.Title, &book.Subtitle, &book.Author, &book.Publisher, &book.Edition, &book.Language, &book.Description, &book.CallNumber} {
+		*field = strings.TrimSpace(*field)
+	}
+
+	if book.PublicationYear != nil && (*book.PublicationYear <= 0 || *book.PublicationYear > time.Now().Year()+1) {
+		return fmt.Errorf("publication year %d is not valid", *book.PublicationYear)
+	}
+
+	if book.PageCount != nil && *book.PageCount <= 0 {
+		return fmt.Errorf("page count %d is not valid", *book.PageCount)
+	}
+
+	if book.Language != "" && !languageCodePattern.MatchString(book.Language) {
+		return fmt.Errorf("language %s is not a valid language code", book.Language)
+	}
+
+	subjects := make([]string, 0, len(book.Subjects))
+	seen := make(map[string]bool)
+
+	for _, subject := range book.Subjects {
+		subject = strings.TrimSpace(subject)
+		key := strings.ToLower(subject)
+
+		if subject == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		subjects = append(subjects, subject)
+	}
+	book.Subjects = subjects
+
+	tags := make([]string, 0, len(book.Tags))
+	seen = make(map[string]bool)
+
+	for _, tag := range book.Tags {
+		tag = normalizeTag(tag)
+
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	book.Tags = tags
+
+	return nil
+}
+
+// resolveSubjects maps the headings of a book to the controlled vocabulary,
+// replacing each with its stored spelling. Headings must be created through
+// the subject endpoints before books can use them.
+func (s *bookServiceImpl) resolveSubjects(book *model.Book) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(book.Subjects))
+	headings := make([]string, 0, len(book.Subjects))
+
+	for _, heading := range book.Subjects {
+		subject, err := s.subjectRepo.GetSubjectByHeading(heading)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to check subject of book: %w", err)
+		}
+
+		if subject == nil {
+			return nil, fmt.Errorf("subject heading %s is not in the vocabulary", heading)
+		}
+
+		if slices.Contains(ids, subject.ID) {
+			continue
+		}
+		ids = append(ids, subject.ID)
+		headings = append(headings, subject.Heading)
+	}
+
+	book.Subjects = headings
+
+	return ids, nil
+}
+
+// prepareContributors decides the credits saved with a book. Clients that
+// only send the author text get its names as authors; clients that send
+// contributors get the author text rewritten from them. On update, an
+// unchanged author text keeps the current credits.
+func prepareContributors(book *model.Book, existingBook *model.Book) error {
+	if book.Contributors == nil {
+		if existingBook != nil && book.Author == existingBook.Author {
+			book.Contributors = existingBook.Contributors
+			return nil
+		}
+
+		// inverted names are turned around here too, or "Assis, Machado de"
+		// would get a different name key than "Machado de Assis"
+		for _, raw := range splitAuthorNames(book.Author) {
+			name, sortName := parseContributorName(raw)
+			if !hasNameKey(name) {
+				continue
+			}
+			book.Contributors = append(book.Contributors, model.BookContributor{Name: name, SortName: sortName, Role: model.ContributorRoleAuthor})
+		}
+		return nil
+	}
+
+	authors := make([]string, 0)
+
+	for i := range book.Contributors {
+		contributor := &book.Contributors[i]
+
+		if contributor.Role == "" {
+			contributor.Role = model.ContributorRoleAuthor
+		}
+
+		if !contributorRoles[contributor.Role] {
+			return fmt.Errorf("contributor role %s is not valid", contributor.Role)
+		}
+
+		if contributor.ID == uuid.Nil {
+			contributor.Name, contributor.SortName = parseContributorName(contributor.Name)
+			if contributor.Name == "" {
+				return fmt.Errorf("contributor name or ID is required")
+			}
+			if !hasNameKey(contributor.Name) {
+				return fmt.Errorf("contributor name %s has no letters or digits", contributor.Name)
+			}
+		}
+
+		if contributor.Role == model.ContributorRoleAuthor {
+			authors = append(authors, contributor.Name)
+		}
+	}
+
+	if len(authors) > 0 {
+		// ";" keeps names like "Assis, Machado de" in one piece if the text is split again
+		book.Author = strings.Join(authors, "; ")
+	}
+
+	return nil
+}
+
+// saveContributors resolves each credit to a stored contributor, creating
+// unknown names, and replaces the book's credits inside tx.
+func (s *bookServiceImpl) saveContributors(tx *sql.Tx, book *model.Book) error {
+	contributorRepo := s.contributorRepo.WithTx(tx)
+	seen := make(map[model.BookContributor]bool)
+	credits := make([]model.BookContributor, 0, len(book.Contributors))
+
+	for _, credit := range book.Contributors {
+		if credit.ID != uuid.Nil {
+			contributor, err := contributorRepo.GetContributorByID(credit.ID)
+			if err != nil {
+				return err
+			}
+			if contributor == nil {
+				return fmt.Errorf("contributor with ID %s not found", credit.ID.String())
+			}
+			credit.Name, credit.SortName = contributor.Name, contributor.SortName
+		} else {
+			contributor := &model.Contributor{Name: credit.Name, SortName: credit.SortName}
+			if err := contributorRepo.UpsertContributor(contributor); err != nil {
+				return err
+			}
+			credit.ID, credit.Name, credit.SortName = contributor.ID, contributor.Name, contributor.SortName
+		}
+
+		key := model.BookContributor{ID: credit.ID, Role: credit.Role}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		credits = append(credits, credit)
+	}
+
+	book.Contributors = credits
+
+	return contributorRepo.SetBookContributors(book.ID, credits)
+}
+
+// attachContributors loads the credits of the given books in one query.
+func (s *bookServiceImpl) attachContributors(books ...*model.Book) error {
+	ids := make([]uuid.UUID, 0, len(books))
+
+	for _, book := range books {
+		ids = append(ids, book.ID)
+	}
+
+	contributors, err := s.contributorRepo.GetBookContributors(ids)
+
+	if err != nil {
+		return fmt.Errorf("failed to get contributors of books: %w", err)
+	}
+
+	for _, book := range books {
+		book.Contributors = contributors[book.ID]
+	}
+
+	return nil
+}
+
+// attachSeries loads the series, with the next volume, of the given books
+// that belong to one.
+func (s *bookServiceImpl) attachSeries(books ...*model.Book) error {
+	ids := make([]uuid.UUID, 0, len(books))
+
+	for _, book := range books {
+		if book.SeriesID != nil {
+			ids = append(ids, book.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	seriesByBook, err := s.seriesRepo.GetBookSeries(ids)
+
+	if err != nil {
+		return fmt.Errorf("failed to get series of books: %w", err)
+	}
+
+	for _, book := range books {
+		if series, ok := seriesByBook[book.ID]; ok {
+			book.Series = &series
+		}
+	}
+
+	return nil
+}
+
+// attachDetails fills in what book responses show beyond the books table.
+func (s *bookServiceImpl) attachDetails(books ...*model.Book) error {
+	if err := s.attachContributors(books...); err != nil {
+		return err
+	}
+
+	return s.attachSeries(books...)
+}
+
+func (s *bookServiceImpl) attachDetailsToList(books []model.Book) error {
+	pointers := make([]*model.Book, 0, len(books))
+
+	for i := range books {
+		pointers = append(pointers, &books[i])
+	}
+
+	return s.attachDetails(pointers...)
+}
+
+// checkSeries validates the series placement of a book. A volume number
+// only makes sense within a series.
+func (s *bookServiceImpl) checkSeries(book *model.Book) error {
+	if book.VolumeNumber != nil && *book.VolumeNumber <= 0 {
+		return fmt.Errorf("volume number %d is not valid", *book.VolumeNumber)
+	}
+
+	if book.SeriesID == nil {
+		if book.VolumeNumber != nil {
+			return fmt.Errorf("volume number requires a series")
+		}
+		return nil
+	}
+
+	series, err := s.seriesRepo.GetSeriesByID(*book.SeriesID)
+
+	if err != nil {
+		return fmt.Errorf("failed to check series of book: %w", err)
+	}
+
+	if series == nil {
+		return fmt.Errorf("series with ID %s does not exist", book.SeriesID.String())
+	}
+
+	return nil
+}
+
+func (s *bookServiceImpl) CreateBook(book *model.Book, enrich bool) (*model.Book, error) {
 	if book.ID == uuid.Nil {
 		newID := uuid.New()
 		book.ID = newID
@@ -35,18 +414,74 @@ func (s *bookServiceImpl) CreateBook(book *model.Book) (*model.Book, error) {
 		log.Printf("ID do livro recebido (não nulo): %s", book.ID.String())
 	}
 
-	existingBook, err := s.bookRepo.GetBookByISBN(book.Isbn)
+	if enrich {
+		if err := s.enrich(book); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkBibliographic(book); err != nil {
+		return nil, err
+	}
+
+	if err := prepareContributors(book, nil); err != nil {
+		return nil, err
+	}
+
+	subjectIDs, err := s.resolveSubjects(book)
 	if err != nil {
-		return nil, fmt.Errorf("falha ao verificar livro existente por ISBN: %w", err)
+		return nil, err
+	}
+
+	if book.ItemType == "" {
+		book.ItemType = model.DefaultItemType
+	}
+
+	// copies share the ISBN, so a copy without a barcode gets a generated one
+	if book.Barcode == "" {
+		sequence, err := s.bookRepo.NextItemBarcodeSequence()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate item barcode: %w", err)
+		}
+		book.Barcode = formatRegistration(s.barcodeConfig, sequence)
+	}
+
+	if err := s.checkBranches(book); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSeries(book); err != nil {
+		return nil, err
+	}
+
+	// copies of the same title share the ISBN; only the barcode identifies a copy
+	existingBook, err := s.bookRepo.GetBookByBarcode(book.Barcode)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao verificar livro existente por código de barras: %w", err)
 	}
 
 	if existingBook != nil {
-		return nil, fmt.Errorf("livro com ISBN %s já existe", book.Isbn)
+		return nil, fmt.Errorf("livro com código de barras %s já existe", book.Barcode)
 	}
 
-	err = s.bookRepo.CreateBook(book)
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.bookRepo.WithTx(tx).CreateBook(book); err != nil {
+			return fmt.Errorf("falha ao criar livro: %w", err)
+		}
+		if err := s.saveContributors(tx, book); err != nil {
+			return err
+		}
+		if err := s.subjectRepo.WithTx(tx).SetBookSubjects(book.ID, subjectIDs); err != nil {
+			return err
+		}
+		return recordEvent(s.outboxRepo, tx, events.BookCreated, book)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("falha ao criar livro: %w", err)
+		return nil, err
+	}
+
+	if err := s.attachSeries(book); err != nil {
+		log.Printf("WARNING: failed to load series of created book %s: %v", book.ID.String(), err)
 	}
 
 	return book, nil
@@ -63,6 +498,17 @@ func (s *bookServiceImpl) GetBookByID(id uuid.UUID) (*model.Book, error) {
 		return nil, fmt.Errorf("book with ID %s not found", id.String())
 	}
 
+	availability, err := s.bookRepo.GetBranchAvailability(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch availability of book: %w", err)
+	}
+	book.Availability = availability
+
+	if err := s.attachDetails(book); err != nil {
+		return nil, err
+	}
+
 	return book, nil
 }
 
@@ -77,6 +523,28 @@ func (s *bookServiceImpl) GetBookByISBN(isbn string) (*model.Book, error) {
 		return nil, fmt.Errorf("book with ISBN %s not found", isbn)
 	}
 
+	if err := s.attachDetails(book); err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+func (s *bookServiceImpl) GetBookByBarcode(barcode string) (*model.Book, error) {
+	book, err := s.bookRepo.GetBookByBarcode(barcode)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book by barcode: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("book with barcode %s not found", barcode)
+	}
+
+	if err := s.attachDetails(book); err != nil {
+		return nil, err
+	}
+
 	return book, nil
 }
 
@@ -91,20 +559,150 @@ func (s *bookServiceImpl) UpdateBook(book *model.Book) (*model.Book, error) {
 		return nil, fmt.Errorf("book with ID %s not found for update", book.ID.String())
 	}
 
-	err = s.bookRepo.UpdateBook(book)
+	if book.ItemType == "" {
+		book.ItemType = existingBook.ItemType
+	}
+
+	if book.Barcode == "" {
+		book.Barcode = existingBook.Barcode
+	}
+
+	if book.HomeBranchID == nil {
+		book.HomeBranchID = existingBook.HomeBranchID
+	}
+
+	if book.CurrentBranchID == nil {
+		book.CurrentBranchID = existingBook.CurrentBranchID
+	}
+
+	// fields added after the first clients were written are kept when left
+	// out, so those clients do not wipe them on every update
+	if book.Subtitle == "" {
+		book.Subtitle = existingBook.Subtitle
+	}
+
+	if book.Publisher == "" {
+		book.Publisher = existingBook.Publisher
+	}
+
+	if book.PublicationYear == nil {
+		book.PublicationYear = existingBook.PublicationYear
+	}
+
+	if book.Edition == "" {
+		book.Edition = existingBook.Edition
+	}
+
+	if book.Language == "" {
+		book.Language = existingBook.Language
+	}
+
+	if book.PageCount == nil {
+		book.PageCount = existingBook.PageCount
+	}
+
+	if book.Description == "" {
+		book.Description = existingBook.Description
+	}
+
+	if book.CallNumber == "" {
+		book.CallNumber = existingBook.CallNumber
+	}
 
+	if book.Subjects == nil {
+		book.Subjects = existingBook.Subjects
+	}
+
+	if book.Tags == nil {
+		book.Tags = existingBook.Tags
+	}
+
+	if book.SeriesID == nil {
+		book.SeriesID = existingBook.SeriesID
+	}
+
+	// the volume number belongs to the series, so it is dropped when the
+	// book moves to another one
+	if book.VolumeNumber == nil && sameID(book.SeriesID, existingBook.SeriesID) {
+		book.VolumeNumber = existingBook.VolumeNumber
+	}
+
+	if err := checkBibliographic(book); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachContributors(existingBook); err != nil {
+		return nil, err
+	}
+
+	if err := prepareContributors(book, existingBook); err != nil {
+		return nil, err
+	}
+
+	subjectIDs, err := s.resolveSubjects(book)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update book: %w", err)
+		return nil, err
+	}
+
+	// the cover is only changed through its own endpoints
+	book.CoverUpdatedAt = existingBook.CoverUpdatedAt
+	book.Cover = existingBook.Cover
+
+	// withdrawal is only changed through the withdraw and reinstate
+	// endpoints, and a withdrawn book stays off the shelf
+	book.AddedAt = existingBook.AddedAt
+	book.WithdrawnAt = existingBook.WithdrawnAt
+	book.WithdrawalReason = existingBook.WithdrawalReason
+	if book.WithdrawnAt != nil {
+		book.Available = false
+	}
+
+	if err := s.checkBranches(book); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkSeries(book); err != nil {
+		return nil, err
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.bookRepo.WithTx(tx).UpdateBook(book); err != nil {
+			return fmt.Errorf("failed to update book: %w", err)
+		}
+		if err := s.saveContributors(tx, book); err != nil {
+			return err
+		}
+		if err := s.subjectRepo.WithTx(tx).SetBookSubjects(book.ID, subjectIDs); err != nil {
+			return err
+		}
+		return recordEvent(s.outboxRepo, tx, events.BookUpdated, book)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSeries(book); err != nil {
+		log.Printf("WARNING: failed to load series of updated book %s: %v", book.ID.String(), err)
 	}
 
 	return book, nil
 }
 
 func (s *bookServiceImpl) DeleteBook(id uuid.UUID) error {
-	err := s.bookRepo.DeleteBook(id)
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.bookRepo.WithTx(tx).DeleteBook(id); err != nil {
+			return fmt.Errorf("failed to delete book: %w", err)
+		}
+		return recordEvent(s.outboxRepo, tx, events.BookDeleted, map[string]any{"id": id})
+	})
 
 	if err != nil {
-		return fmt.Errorf("failed to delete book: %w", err)
+		return err
+	}
+
+	if err := s.coverService.PurgeCover(id); err != nil {
+		log.Printf("WARNING: failed to remove cover files of deleted book %s: %v", id.String(), err)
 	}
 
 	return nil
@@ -115,5 +713,41 @@ func (s *bookServiceImpl) GetAllBooks() ([]model.Book, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all books: %w", err)
 	}
+	if err := s.attachDetailsToList(books); err != nil {
+		return nil, err
+	}
 	return books, nil
 }
+
+func (s *bookServiceImpl) SearchBooks(filter model.BookFilter) ([]model.Book, error) {
+	books, err := s.bookRepo.SearchBooks(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search books: %w", err)
+	}
+	if err := s.attachDetailsToList(books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// SearchCatalog returns one page of the books matching filter together with
+// facet counts over all of them, each facet holding at most facetLimit values.
+func (s *bookServiceImpl) SearchCatalog(filter model.BookFilter, facetLimit int) (*model.CatalogPage, error) {
+	books, err := s.SearchBooks(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := s.bookRepo.GetFacets(filter, facetLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get facets of books: %w", err)
+	}
+
+	return &model.CatalogPage{
+		Total:  facets.Availability.Available + facets.Availability.Unavailable,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+		Books:  books,
+		Facets: *facets,
+	}, nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/model"
+)
+
+func TestCreateBookGeneratesDistinctBarcodesForCopies(t *testing.T) {
+	f := newCirculationFixture()
+	bookRepo := &fakeBookRepo{store: f.store}
+	service := NewBookService(bookRepo, &fakeBranchRepo{}, &fakeContributorRepo{}, &fakeSubjectRepo{}, nil, f.transactor, &fakeOutboxRepo{store: f.store}, nil, nil,
+		config.RegistrationConfig{Prefix: "EX", SequenceDigits: 8})
+
+	first, err := service.CreateBook(&model.Book{Title: "Dom Casmurro", Isbn: "9788535910667"}, false)
+	if err != nil {
+		t.Fatalf("CreateBook() first copy error = %v", err)
+	}
+
+	second, err := service.CreateBook(&model.Book{Title: "Dom Casmurro", Isbn: "9788535910667"}, false)
+	if err != nil {
+		t.Fatalf("CreateBook() second copy error = %v", err)
+	}
+
+	if first.Barcode == "" || first.Barcode == first.Isbn || first.Barcode == second.Barcode {
+		t.Fatalf("barcodes = %q and %q, want distinct generated barcodes", first.Barcode, second.Barcode)
+	}
+
+	if want := formatRegistration(config.RegistrationConfig{Prefix: "EX", SequenceDigits: 8}, 1); first.Barcode != want {
+		t.Fatalf("first barcode = %q, want %q", first.Barcode, want)
+	}
+}
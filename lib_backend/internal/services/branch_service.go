@@ -0,0 +1,126 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type BranchService interface {
+	CreateBranch(branch *model.Branch) (*model.Branch, error)
+	GetBranchByID(id uuid.UUID) (*model.Branch, error)
+	UpdateBranch(branch *model.Branch) (*model.Branch, error)
+	DeleteBranch(id uuid.UUID) error
+	GetAllBranches() ([]model.Branch, error)
+}
+
+type branchServiceImpl struct {
+	branchRepo repository.BranchRepository
+}
+
+func NewBranchService(branchRepo repository.BranchRepository) BranchService {
+	return &branchServiceImpl{branchRepo: branchRepo}
+}
+
+func (s *branchServiceImpl) CreateBranch(branch *model.Branch) (*model.Branch, error) {
+	branch.Code = strings.TrimSpace(branch.Code)
+
+	if branch.Code == "" || strings.TrimSpace(branch.Name) == "" {
+		return nil, fmt.Errorf("branch code and name are required")
+	}
+
+	err := s.branchRepo.CreateBranch(branch)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("branch with code %s already exists", branch.Code)
+		}
+
+		return nil, fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	return branch, nil
+}
+
+func (s *branchServiceImpl) GetBranchByID(id uuid.UUID) (*model.Branch, error) {
+	branch, err := s.branchRepo.GetBranchByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch by ID: %w", err)
+	}
+
+	if branch == nil {
+		return nil, fmt.Errorf("branch with ID %s not found", id.String())
+	}
+
+	return branch, nil
+}
+
+func (s *branchServiceImpl) UpdateBranch(branch *model.Branch) (*model.Branch, error) {
+	existingBranch, err := s.branchRepo.GetBranchByID(branch.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing branch before update: %w", err)
+	}
+
+	if existingBranch == nil {
+		return nil, fmt.Errorf("branch with ID %s not found", branch.ID.String())
+	}
+
+	if branch.Code == "" {
+		branch.Code = existingBranch.Code
+	}
+
+	if branch.Name == "" {
+		branch.Name = existingBranch.Name
+	}
+
+	err = s.branchRepo.UpdateBranch(branch)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("branch with code %s already exists", branch.Code)
+		}
+
+		return nil, fmt.Errorf("failed to update branch: %w", err)
+	}
+
+	return branch, nil
+}
+
+func (s *branchServiceImpl) DeleteBranch(id uuid.UUID) error {
+	existingBranch, err := s.branchRepo.GetBranchByID(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to check for existing branch before deletion: %w", err)
+	}
+
+	if existingBranch == nil {
+		return fmt.Errorf("branch with ID %s not found", id.String())
+	}
+
+	err = s.branchRepo.DeleteBranch(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete branch: %w", err)
+	}
+
+	return nil
+}
+
+func (s *branchServiceImpl) GetAllBranches() ([]model.Branch, error) {
+	branches, err := s.branchRepo.GetAllBranches()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+
+	return branches, nil
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type policyResolver struct {
+	ruleRepo     repository.CirculationRuleRepository
+	categoryRepo repository.PatronCategoryRepository
+	branchRepo   repository.BranchRepository
+}
+
+// branchCode returns the code used to match branch-specific rules, or an
+// empty string when the branch is unknown so only wildcard rules apply.
+func (p *policyResolver) branchCode(branchID *uuid.UUID) (string, error) {
+	if branchID == nil {
+		return "", nil
+	}
+
+	branch, err := p.branchRepo.GetBranchByID(*branchID)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to get branch for circulation policy: %w", err)
+	}
+
+	if branch == nil {
+		return "", nil
+	}
+
+	return branch.Code, nil
+}
+
+func (p *policyResolver) categoryFor(user *model.User) (*model.PatronCategory, error) {
+	category, err := p.categoryRepo.GetCategoryByCode(user.Category)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patron category: %w", err)
+	}
+
+	if category == nil {
+		return nil, fmt.Errorf("patron category %s of user %s not found", user.Category, user.ID.String())
+	}
+
+	return category, nil
+}
+
+// resolve returns the effective policy for a patron category, item type and
+// branch along with every rule that matched, most specific first.
+func (p *policyResolver) resolve(category *model.PatronCategory, itemType, branchCode string) (*model.CirculationPolicy, []model.CirculationRule, error) {
+	candidates, err := p.ruleRepo.GetMatchingRules(category.Code, itemType, branchCode)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get matching circulation rules: %w", err)
+	}
+
+	sortBySpecificity(candidates)
+
+	if len(candidates) == 0 {
+		return categoryPolicy(category), candidates, nil
+	}
+
+	return rulePolicy(&candidates[0]), candidates, nil
+}
+
+// sortBySpecificity orders rules most specific first, breaking ties by ID so
+// the winning rule never depends on the order the database returned them in.
+func sortBySpecificity(rules []model.CirculationRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Specificity() != rules[j].Specificity() {
+			return rules[i].Specificity() > rules[j].Specificity()
+		}
+		return rules[i].ID.String() < rules[j].ID.String()
+	})
+}
+
+func rulePolicy(rule *model.CirculationRule) *model.CirculationPolicy {
+	return &model.CirculationPolicy{
+		Source:          model.PolicySourceRule,
+		Rule:            rule,
+		LoanPeriodDays:  rule.LoanPeriodDays,
+		MaxRenewals:     rule.MaxRenewals,
+		FinePerDayCents: rule.FinePerDayCents,
+		HoldsAllowed:    rule.HoldsAllowed,
+		NonCirculating:  rule.NonCirculating,
+	}
+}
+
+func categoryPolicy(category *model.PatronCategory) *model.CirculationPolicy {
+	return &model.CirculationPolicy{
+		Source:         model.PolicySourcePatronCategory,
+		LoanPeriodDays: category.LoanPeriodDays,
+		MaxRenewals:    category.MaxRenewals,
+		HoldsAllowed:   category.MaxHolds > 0,
+	}
+}
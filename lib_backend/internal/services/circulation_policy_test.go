@@ -0,0 +1,64 @@
+package services
+
+import (
+	"testing"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestResolvePrefersMostSpecificRule(t *testing.T) {
+	student, book, main := "student", "book", "MAIN"
+	rules := &fakeRuleRepo{rules: []model.CirculationRule{
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000003"), ItemType: &book, LoanPeriodDays: 21},
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), BranchCode: &main, LoanPeriodDays: 3},
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), PatronCategory: &student, LoanPeriodDays: 7},
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000004"), PatronCategory: &student, ItemType: &book, LoanPeriodDays: 10},
+	}}
+	resolver := policyResolver{ruleRepo: rules}
+
+	policy, candidates, err := resolver.resolve(&model.PatronCategory{Code: student, LoanPeriodDays: 30}, book, main)
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+
+	if policy.Source != model.PolicySourceRule || policy.LoanPeriodDays != 10 {
+		t.Fatalf("resolve() picked %+v, want the category and item type rule", policy)
+	}
+
+	want := []int{10, 7, 21, 3}
+	for i, rule := range candidates {
+		if rule.LoanPeriodDays != want[i] {
+			t.Fatalf("candidate %d has loan period %d, want %d", i, rule.LoanPeriodDays, want[i])
+		}
+	}
+}
+
+func TestSortBySpecificityBreaksTiesByID(t *testing.T) {
+	first := uuid.MustParse("00000000-0000-0000-0000-00000000000a")
+	second := uuid.MustParse("00000000-0000-0000-0000-00000000000b")
+
+	for _, rules := range [][]model.CirculationRule{
+		{{ID: second}, {ID: first}},
+		{{ID: first}, {ID: second}},
+	} {
+		sortBySpecificity(rules)
+		if rules[0].ID != first {
+			t.Fatalf("sortBySpecificity() put %s first, want %s", rules[0].ID, first)
+		}
+	}
+}
+
+func TestResolveFallsBackToCategory(t *testing.T) {
+	resolver := policyResolver{ruleRepo: &fakeRuleRepo{}}
+
+	policy, _, err := resolver.resolve(&model.PatronCategory{Code: "staff", LoanPeriodDays: 30, MaxRenewals: 3}, "book", "")
+	if err != nil {
+		t.Fatalf("resolve() error = %v", err)
+	}
+
+	if policy.Source != model.PolicySourcePatronCategory || policy.LoanPeriodDays != 30 {
+		t.Fatalf("resolve() = %+v, want the patron category defaults", policy)
+	}
+}
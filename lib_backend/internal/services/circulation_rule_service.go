@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type CirculationRuleService interface {
+	CreateRule(rule *model.CirculationRule) (*model.CirculationRule, error)
+	GetRuleByID(id uuid.UUID) (*model.CirculationRule, error)
+	UpdateRule(rule *model.CirculationRule) (*model.CirculationRule, error)
+	DeleteRule(id uuid.UUID) error
+	GetAllRules() ([]model.CirculationRule, error)
+}
+
+type circulationRuleServiceImpl struct {
+	ruleRepo repository.CirculationRuleRepository
+}
+
+func NewCirculationRuleService(ruleRepo repository.CirculationRuleRepository) CirculationRuleService {
+	return &circulationRuleServiceImpl{ruleRepo: ruleRepo}
+}
+
+func validateRule(rule *model.CirculationRule) error {
+	if rule.LoanPeriodDays <= 0 || rule.MaxRenewals < 0 || rule.FinePerDayCents < 0 {
+		return fmt.Errorf("invalid circulation rule values")
+	}
+
+	return nil
+}
+
+func ruleConflictError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return fmt.Errorf("a circulation rule for this combination already exists")
+		case "23503":
+			return fmt.Errorf("circulation rule references an unknown patron category")
+		}
+	}
+
+	return nil
+}
+
+func (s *circulationRuleServiceImpl) CreateRule(rule *model.CirculationRule) (*model.CirculationRule, error) {
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	err := s.ruleRepo.CreateRule(rule)
+
+	if err != nil {
+		if conflict := ruleConflictError(err); conflict != nil {
+			return nil, conflict
+		}
+
+		return nil, fmt.Errorf("failed to create circulation rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *circulationRuleServiceImpl) GetRuleByID(id uuid.UUID) (*model.CirculationRule, error) {
+	rule, err := s.ruleRepo.GetRuleByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circulation rule by ID: %w", err)
+	}
+
+	if rule == nil {
+		return nil, fmt.Errorf("circulation rule with ID %s not found", id.String())
+	}
+
+	return rule, nil
+}
+
+func (s *circulationRuleServiceImpl) UpdateRule(rule *model.CirculationRule) (*model.CirculationRule, error) {
+	if err := validateRule(rule); err != nil {
+		return nil, err
+	}
+
+	existingRule, err := s.ruleRepo.GetRuleByID(rule.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing circulation rule before update: %w", err)
+	}
+
+	if existingRule == nil {
+		return nil, fmt.Errorf("circulation rule with ID %s not found", rule.ID.String())
+	}
+
+	err = s.ruleRepo.UpdateRule(rule)
+
+	if err != nil {
+		if conflict := ruleConflictError(err); conflict != nil {
+			return nil, conflict
+		}
+
+		return nil, fmt.Errorf("failed to update circulation rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (s *circulationRuleServiceImpl) DeleteRule(id uuid.UUID) error {
+	existingRule, err := s.ruleRepo.GetRuleByID(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to check for existing circulation rule before deletion: %w", err)
+	}
+
+	if existingRule == nil {
+		return fmt.Errorf("circulation rule with ID %s not found", id.String())
+	}
+
+	err = s.ruleRepo.DeleteRule(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete circulation rule: %w", err)
+	}
+
+	return nil
+}
+
+func (s *circulationRuleServiceImpl) GetAllRules() ([]model.CirculationRule, error) {
+	rules, err := s.ruleRepo.GetAllRules()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circulation rules: %w", err)
+	}
+
+	return rules, nil
+}
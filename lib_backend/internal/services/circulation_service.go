@@ -0,0 +1,200 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CirculationService implements the barcode-driven circulation desk on top of LoanService.
+type CirculationService interface {
+	Checkout(patronBarcode string, itemBarcodes []string, branchCode string) (*model.CheckoutResult, error)
+	Checkin(itemBarcode string, branchCode string) (*model.CheckinResult, error)
+}
+
+type circulationServiceImpl struct {
+	loanService     LoanService
+	transferService TransferService
+	loanRepo        repository.LoanRepository
+	userRepo        repository.UserRepository
+	bookRepo        repository.BookRepository
+	holdRepo        repository.HoldRepository
+	branchRepo      repository.BranchRepository
+	transferRepo    repository.TransferRepository
+}
+
+func NewCirculationService(loanService LoanService, transferService TransferService, loanRepo repository.LoanRepository, userRepo repository.UserRepository, bookRepo repository.BookRepository, holdRepo repository.HoldRepository, branchRepo repository.BranchRepository, transferRepo repository.TransferRepository) CirculationService {
+	return &circulationServiceImpl{loanService: loanService, transferService: transferService, loanRepo: loanRepo, userRepo: userRepo, bookRepo: bookRepo, holdRepo: holdRepo, branchRepo: branchRepo, transferRepo: transferRepo}
+}
+
+// deskBranch resolves the branch code of the circulation desk; an empty code means no branch.
+func (s *circulationServiceImpl) deskBranch(branchCode string) (*model.Branch, error) {
+	if branchCode == "" {
+		return nil, nil
+	}
+
+	branch, err := s.branchRepo.GetBranchByCode(branchCode)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circulation desk branch: %w", err)
+	}
+
+	if branch == nil {
+		return nil, fmt.Errorf("branch with code %s not found", branchCode)
+	}
+
+	return branch, nil
+}
+
+func (s *circulationServiceImpl) Checkout(patronBarcode string, itemBarcodes []string, branchCode string) (*model.CheckoutResult, error) {
+	branch, err := s.deskBranch(branchCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var branchID *uuid.UUID
+	if branch != nil {
+		branchID = &branch.ID
+	}
+
+	patron, err := s.userRepo.GetUserByRegistration(patronBarcode)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patron by barcode: %w", err)
+	}
+
+	if patron == nil {
+		return nil, fmt.Errorf("patron with barcode %s not found", patronBarcode)
+	}
+
+	if patron.ErasedAt != nil {
+		return nil, fmt.Errorf("patron with barcode %s is erased", patronBarcode)
+	}
+
+	result := &model.CheckoutResult{Patron: patron, Items: make([]model.CheckoutItemResult, 0, len(itemBarcodes))}
+
+	// each item is checked out independently so one bad barcode does not block the rest
+	for _, itemBarcode := range itemBarcodes {
+		item := model.CheckoutItemResult{ItemBarcode: itemBarcode}
+
+		book, err := s.bookRepo.GetBookByBarcode(itemBarcode)
+
+		switch {
+		case err != nil:
+			item.Error = fmt.Sprintf("failed to get item: %v", err)
+		case book == nil:
+			item.Error = fmt.Sprintf("item with barcode %s not found", itemBarcode)
+		default:
+			loan, err := s.loanService.CreateLoan(&model.Loan{UserID: patron.ID, BookID: book.ID, LoanedAt: time.Now(), CheckoutBranchID: branchID})
+			if err != nil {
+				item.Error = err.Error()
+			} else {
+				item.Loan = loan
+				result.CheckedOut++
+			}
+		}
+
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
+
+func (s *circulationServiceImpl) Checkin(itemBarcode string, branchCode string) (*model.CheckinResult, error) {
+	branch, err := s.deskBranch(branchCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var branchID *uuid.UUID
+	if branch != nil {
+		branchID = &branch.ID
+	}
+
+	book, err := s.bookRepo.GetBookByBarcode(itemBarcode)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item by barcode: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("item with barcode %s not found", itemBarcode)
+	}
+
+	activeLoan, err := s.loanRepo.GetActiveLoanByBookID(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active loan for item: %w", err)
+	}
+
+	result := &model.CheckinResult{Action: model.CheckinActionReshelve}
+
+	if activeLoan != nil {
+		loan, err := s.loanService.ReturnBook(activeLoan.ID, branchID)
+
+		if err != nil {
+			return nil, err
+		}
+		result.Loan = loan
+	} else {
+		// an item that is not on loan is only expected at the desk when it arrives from a transfer
+		transfer, err := s.transferRepo.GetOpenTransferByBookID(book.ID)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get open transfer for item: %w", err)
+		}
+
+		if transfer == nil {
+			return nil, fmt.Errorf("item with barcode %s is not on loan", itemBarcode)
+		}
+
+		if branchID != nil && *branchID != transfer.ToBranchID {
+			return nil, fmt.Errorf("item with barcode %s is in transit to another branch", itemBarcode)
+		}
+
+		if _, err := s.transferService.ReceiveTransfer(transfer.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	transfer, err := s.transferRepo.GetOpenTransferByBookID(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check transfers for returned item: %w", err)
+	}
+
+	hold, err := s.holdRepo.GetReadyHold(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check holds for returned item: %w", err)
+	}
+
+	switch {
+	case transfer != nil:
+		result.Action = model.CheckinActionTransit
+		result.Transfer = transfer
+	case hold != nil:
+		result.Action = model.CheckinActionHoldShelf
+		result.Hold = hold
+
+		patron, err := s.userRepo.GetUserByID(hold.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get hold patron for returned item: %w", err)
+		}
+		result.Patron = patron
+	}
+
+	book, err = s.bookRepo.GetBookByID(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload returned item: %w", err)
+	}
+	result.Book = book
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+type ContributorService interface {
+	GetContributorByID(id uuid.UUID) (*model.Contributor, error)
+	SearchContributors(query string, limit int) ([]model.Contributor, error)
+}
+
+type contributorServiceImpl struct {
+	contributorRepo repository.ContributorRepository
+}
+
+func NewContributorService(contributorRepo repository.ContributorRepository) ContributorService {
+	return &contributorServiceImpl{contributorRepo: contributorRepo}
+}
+
+var contributorRoles = map[string]bool{
+	model.ContributorRoleAuthor:      true,
+	model.ContributorRoleEditor:      true,
+	model.ContributorRoleTranslator:  true,
+	model.ContributorRoleIllustrator: true,
+}
+
+var (
+	authorSeparatorPattern = regexp.MustCompile(`\s*[;&]\s*`)
+	whitespacePattern      = regexp.MustCompile(`\s+`)
+)
+
+// splitAuthorNames breaks a free-text author field into names. Names are
+// separated by ";" or "&", and by commas unless the comma inverts a single
+// name ("Assis, Machado de"). Migration 000017 applies the same rules to the
+// books that existed before contributors.
+func splitAuthorNames(author string) []string {
+	names := make([]string, 0)
+
+	for _, part := range authorSeparatorPattern.Split(author, -1) {
+		part = strings.TrimSpace(whitespacePattern.ReplaceAllString(part, " "))
+		if part == "" {
+			continue
+		}
+
+		commaParts := strings.Split(part, ",")
+		for i := range commaParts {
+			commaParts[i] = strings.TrimSpace(commaParts[i])
+		}
+
+		if len(commaParts) == 2 && commaParts[0] != "" && commaParts[1] != "" &&
+			!(strings.Contains(commaParts[0], " ") && strings.Contains(commaParts[1], " ")) {
+			names = append(names, part)
+			continue
+		}
+
+		for _, name := range commaParts {
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+// hasNameKey reports whether a name has a letter or digit in any script.
+// contributor_name_key folds everything else away, so names without one
+// could not be told apart.
+func hasNameKey(name string) bool {
+	return strings.IndexFunc(name, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0
+}
+
+// parseContributorName returns a name in reading order and its inverted sort
+// form, accepting either "Machado de Assis" or "Assis, Machado de".
+func parseContributorName(raw string) (name string, sortName string) {
+	raw = strings.TrimSpace(whitespacePattern.ReplaceAllString(raw, " "))
+
+	if last, first, inverted := strings.Cut(raw, ","); inverted {
+		last, first = strings.TrimSpace(last), strings.TrimSpace(first)
+		if last != "" && first != "" {
+			return first + " " + last, last + ", " + first
+		}
+		raw = last + first
+	}
+
+	if i := strings.LastIndex(raw, " "); i > 0 {
+		return raw, raw[i+1:] + ", " + raw[:i]
+	}
+
+	return raw, raw
+}
+
+func (s *contributorServiceImpl) GetContributorByID(id uuid.UUID) (*model.Contributor, error) {
+	contributor, err := s.contributorRepo.GetContributorByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contributor by ID: %w", err)
+	}
+
+	if contributor == nil {
+		return nil, fmt.Errorf("contributor with ID %s not found", id.String())
+	}
+
+	works, err := s.contributorRepo.GetWorks(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get works of contributor: %w", err)
+	}
+	contributor.Works = works
+
+	return contributor, nil
+}
+
+func (s *contributorServiceImpl) SearchContributors(query string, limit int) ([]model.Contributor, error) {
+	contributors, err := s.contributorRepo.SearchContributors(strings.TrimSpace(query), limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search contributors: %w", err)
+	}
+
+	return contributors, nil
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"testing"
+
+	"lib_backend/internal/model"
+)
+
+func TestSplitAuthorNames(t *testing.T) {
+	cases := map[string][]string{
+		"Machado de Assis":                       {"Machado de Assis"},
+		"Assis, Machado de":                      {"Assis, Machado de"},
+		"Neil Gaiman, Terry Pratchett":           {"Neil Gaiman", "Terry Pratchett"},
+		"Gaiman, Neil; Pratchett, Terry":         {"Gaiman, Neil", "Pratchett, Terry"},
+		"  Kernighan  &  Ritchie ":               {"Kernighan", "Ritchie"},
+		"Tolkien, J.R.R. & Tolkien, Christopher": {"Tolkien, J.R.R.", "Tolkien, Christopher"},
+	}
+
+	for author, want := range cases {
+		got := splitAuthorNames(author)
+		if len(got) != len(want) {
+			t.Errorf("splitAuthorNames(%q) = %q, want %q", author, got, want)
+			continue
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("splitAuthorNames(%q) = %q, want %q", author, got, want)
+				break
+			}
+		}
+	}
+}
+
+func TestPrepareContributorsNormalizesAuthorText(t *testing.T) {
+	fromText := &model.Book{Author: "Assis, Machado de; Neil Gaiman"}
+	if err := prepareContributors(fromText, nil); err != nil {
+		t.Fatalf("prepareContributors() error = %v", err)
+	}
+
+	fromCredits := &model.Book{Contributors: []model.BookContributor{{Name: "Machado de Assis"}, {Name: "Gaiman, Neil"}}}
+	if err := prepareContributors(fromCredits, nil); err != nil {
+		t.Fatalf("prepareContributors() error = %v", err)
+	}
+
+	want := []model.BookContributor{
+		{Name: "Machado de Assis", SortName: "Assis, Machado de", Role: model.ContributorRoleAuthor},
+		{Name: "Neil Gaiman", SortName: "Gaiman, Neil", Role: model.ContributorRoleAuthor},
+	}
+
+	for _, book := range []*model.Book{fromText, fromCredits} {
+		if len(book.Contributors) != len(want) {
+			t.Fatalf("contributors = %+v, want %+v", book.Contributors, want)
+		}
+		for i := range want {
+			got := book.Contributors[i]
+			if got.Name != want[i].Name || got.SortName != want[i].SortName || got.Role != want[i].Role {
+				t.Errorf("contributor %d = %+v, want %+v", i, got, want[i])
+			}
+		}
+	}
+}
+
+func TestPrepareContributorsKeepsNamesInAnyScript(t *testing.T) {
+	book := &model.Book{Contributors: []model.BookContributor{{Name: "Лев Толстой"}, {Name: "村上春樹"}, {Name: "Stanisław Lem"}}}
+	if err := prepareContributors(book, nil); err != nil {
+		t.Fatalf("prepareContributors() error = %v", err)
+	}
+	if book.Author != "Лев Толстой; 村上春樹; Stanisław Lem" {
+		t.Fatalf("author = %q, want every name kept", book.Author)
+	}
+
+	fromText := &model.Book{Author: "Лев Толстой; ...; 村上春樹"}
+	if err := prepareContributors(fromText, nil); err != nil {
+		t.Fatalf("prepareContributors() from text error = %v", err)
+	}
+	if len(fromText.Contributors) != 2 || fromText.Contributors[0].SortName != "Толстой, Лев" || fromText.Contributors[1].Name != "村上春樹" {
+		t.Fatalf("contributors = %+v, want the two names without the punctuation-only one", fromText.Contributors)
+	}
+
+	punctuation := &model.Book{Contributors: []model.BookContributor{{Name: "..."}}}
+	if err := prepareContributors(punctuation, nil); err == nil || err.Error() != "contributor name ... has no letters or digits" {
+		t.Fatalf("prepareContributors() error = %v, want the name without letters rejected", err)
+	}
+}
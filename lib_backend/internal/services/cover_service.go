@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"lib_backend/internal/storage"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// coverWidths are the thumbnail widths generated for every upload. Images
+// narrower than a size are stored at their own width rather than upscaled.
+var coverWidths = map[string]int{
+	model.CoverSizeSmall:  96,
+	model.CoverSizeMedium: 240,
+	model.CoverSizeLarge:  480,
+}
+
+// coverFormats are the image formats accepted for upload, as reported by
+// image.DecodeConfig from the file's magic bytes.
+var coverFormats = map[string]bool{
+	"jpeg": true,
+	"png":  true,
+	"webp": true,
+}
+
+const coverJPEGQuality = 85
+
+type CoverService interface {
+	UploadCover(bookID uuid.UUID, r io.Reader) (*model.Book, error)
+	GetCover(bookID uuid.UUID, size string) (*storage.Blob, error)
+	DeleteCover(bookID uuid.UUID) (*model.Book, error)
+	PurgeCover(bookID uuid.UUID) error
+}
+
+type coverServiceImpl struct {
+	bookRepo   repository.BookRepository
+	store      storage.BlobStore
+	transactor repository.Transactor
+	outboxRepo repository.OutboxRepository
+	cfg        config.CoverConfig
+}
+
+func NewCoverService(bookRepo repository.BookRepository, store storage.BlobStore, transactor repository.Transactor, outboxRepo repository.OutboxRepository, cfg config.CoverConfig) CoverService {
+	return &coverServiceImpl{bookRepo: bookRepo, store: store, transactor: transactor, outboxRepo: outboxRepo, cfg: cfg}
+}
+
+func coverKey(bookID uuid.UUID, size string) string {
+	return fmt.Sprintf("covers/%s/%s.jpg", bookID.String(), size)
+}
+
+func (s *coverServiceImpl) getBook(bookID uuid.UUID) (*model.Book, error) {
+	book, err := s.bookRepo.GetBookByID(bookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book for cover: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("book with ID %s not found", bookID.String())
+	}
+
+	return book, nil
+}
+
+// decodeCover checks the upload's real format, whatever the client claimed,
+// and its dimensions before decoding it.
+func (s *coverServiceImpl) decodeCover(data []byte) (image.Image, error) {
+	imageConfig, format, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if errors.Is(err, image.ErrFormat) {
+		return nil, fmt.Errorf("unsupported cover image type %s", http.DetectContentType(data))
+	} else if err != nil {
+		return nil, fmt.Errorf("invalid cover image: %w", err)
+	}
+
+	if !coverFormats[format] {
+		return nil, fmt.Errorf("unsupported cover image type image/%s", format)
+	}
+
+	if imageConfig.Width > s.cfg.MaxDimension || imageConfig.Height > s.cfg.MaxDimension {
+		return nil, fmt.Errorf("cover image of %dx%d pixels exceeds %d pixels per side", imageConfig.Width, imageConfig.Height, s.cfg.MaxDimension)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid cover image: %w", err)
+	}
+
+	return img, nil
+}
+
+// thumbnail scales img to width, keeping its aspect ratio, and encodes it as
+// JPEG. Transparent areas are flattened onto white.
+func thumbnail(img image.Image, width int) ([]byte, error) {
+	bounds := img.Bounds()
+
+	if bounds.Dx() < width {
+		width = bounds.Dx()
+	}
+
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: coverJPEGQuality}); err != nil {
+		return nil, fmt.Errorf("failed to encode cover thumbnail: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *coverServiceImpl) UploadCover(bookID uuid.UUID, r io.Reader) (*model.Book, error) {
+	book, err := s.getBook(bookID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.cfg.MaxBytes+1))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cover image: %w", err)
+	}
+
+	if int64(len(data)) > s.cfg.MaxBytes {
+		return nil, fmt.Errorf("cover image exceeds %d bytes", s.cfg.MaxBytes)
+	}
+
+	img, err := s.decodeCover(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	for size, width := range coverWidths {
+		thumb, err := thumbnail(img, width)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.store.Put(ctx, coverKey(book.ID, size), thumb, "image/jpeg"); err != nil {
+			return nil, fmt.Errorf("failed to store %s cover of book %s: %w", size, book.ID.String(), err)
+		}
+	}
+
+	updatedAt := time.Now()
+	book.CoverUpdatedAt = &updatedAt
+	book.Cover = model.NewBookCover(book.ID, updatedAt)
+
+	if err := s.saveCover(book); err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+func (s *coverServiceImpl) saveCover(book *model.Book) error {
+	return s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.bookRepo.WithTx(tx).SetCoverUpdatedAt(book.ID, book.CoverUpdatedAt); err != nil {
+			return err
+		}
+		return recordEvent(s.outboxRepo, tx, events.BookUpdated, book)
+	})
+}
+
+func (s *coverServiceImpl) GetCover(bookID uuid.UUID, size string) (*storage.Blob, error) {
+	if _, ok := coverWidths[size]; !ok {
+		return nil, fmt.Errorf("invalid cover size %s", size)
+	}
+
+	book, err := s.getBook(bookID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if book.CoverUpdatedAt == nil {
+		return nil, fmt.Errorf("book with ID %s has no cover", bookID.String())
+	}
+
+	blob, err := s.store.Get(context.Background(), coverKey(book.ID, size))
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s cover of book %s: %w", size, bookID.String(), err)
+	}
+
+	if blob == nil {
+		return nil, fmt.Errorf("book with ID %s has no cover", bookID.String())
+	}
+
+	return blob, nil
+}
+
+func (s *coverServiceImpl) DeleteCover(bookID uuid.UUID) (*model.Book, error) {
+	book, err := s.getBook(bookID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if book.CoverUpdatedAt == nil {
+		return nil, fmt.Errorf("book with ID %s has no cover", bookID.String())
+	}
+
+	book.CoverUpdatedAt = nil
+	book.Cover = nil
+
+	if err := s.saveCover(book); err != nil {
+		return nil, err
+	}
+
+	if err := s.PurgeCover(bookID); err != nil {
+		log.Printf("WARNING: failed to remove cover files of book %s: %v", bookID.String(), err)
+	}
+
+	return book, nil
+}
+
+// PurgeCover removes the stored cover images of a book without touching the
+// book itself, e.g. after the book was deleted.
+func (s *coverServiceImpl) PurgeCover(bookID uuid.UUID) error {
+	ctx := context.Background()
+
+	for size := range coverWidths {
+		if err := s.store.Delete(ctx, coverKey(bookID, size)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
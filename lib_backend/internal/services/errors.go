@@ -0,0 +1,16 @@
+package services
+
+import "fmt"
+
+// PatronLimitError is returned when a patron has reached one of the limits
+// of their category (concurrent loans, renewals, holds).
+type PatronLimitError struct {
+	Category string
+	Limit    string
+	Max      int
+	Current  int
+}
+
+func (e *PatronLimitError) Error() string {
+	return fmt.Sprintf("patron category %s allows at most %d %s, patron currently has %d", e.Category, e.Max, e.Limit, e.Current)
+}
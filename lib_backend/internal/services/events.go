@@ -0,0 +1,29 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// recordEvent appends a domain event to the outbox inside tx, so the event is
+// only published if the change it describes commits.
+func recordEvent(outboxRepo repository.OutboxRepository, tx *sql.Tx, eventType string, data any) error {
+	payload, err := json.Marshal(data)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return outboxRepo.WithTx(tx).Append(&model.OutboxEvent{ID: uuid.New(), Type: eventType, Data: payload, OccurredAt: time.Now()})
+}
+
+func recordAvailability(outboxRepo repository.OutboxRepository, tx *sql.Tx, book *model.Book) error {
+	return recordEvent(outboxRepo, tx, events.BookAvailability, map[string]any{"book_id": book.ID, "available": book.Available, "current_branch_id": book.CurrentBranchID})
+}
@@ -0,0 +1,571 @@
+package services
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
+	"lib_backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// fakeStore keeps the rows the fake repositories share. The embedded
+// interfaces leave every method a test does not need unimplemented, so an
+// unexpected call panics instead of passing silently.
+type fakeStore struct {
+	mu        sync.Mutex
+	users     map[uuid.UUID]*model.User
+	books     map[uuid.UUID]*model.Book
+	loans     map[uuid.UUID]*model.Loan
+	holds     map[uuid.UUID]*model.Hold
+	transfers []model.Transfer
+	fines     []model.Fine
+	events    []model.OutboxEvent
+	locks     int
+	sequence  int64
+	// onLock runs once a user lock is granted, standing in for a concurrent
+	// transaction that committed while the caller waited for it
+	onLock func(id uuid.UUID)
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		users: make(map[uuid.UUID]*model.User),
+		books: make(map[uuid.UUID]*model.Book),
+		loans: make(map[uuid.UUID]*model.Loan),
+		holds: make(map[uuid.UUID]*model.Hold),
+	}
+}
+
+func (s *fakeStore) addUser(category string) *model.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := &model.User{ID: uuid.New(), Name: "Patron", Registration: uuid.NewString(), Category: category}
+	s.users[user.ID] = user
+	return user
+}
+
+func (s *fakeStore) addBook(isbn string, available bool) *model.Book {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	book := &model.Book{ID: uuid.New(), Title: "Book", Isbn: isbn, Barcode: uuid.NewString(), Available: available, ItemType: model.DefaultItemType}
+	s.books[book.ID] = book
+	return book
+}
+
+// shipping reports whether an open transfer is moving a copy to the hold.
+// The caller holds s.mu.
+func (s *fakeStore) shipping(holdID uuid.UUID) bool {
+	for _, transfer := range s.transfers {
+		if transfer.HoldID != nil && *transfer.HoldID == holdID &&
+			(transfer.Status == model.TransferStatusRequested || transfer.Status == model.TransferStatusInTransit) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *fakeStore) eventTypes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	types := make([]string, 0, len(s.events))
+	for _, event := range s.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// fakeTransactor runs one transaction at a time, like a serializable
+// database, and counts the transactions that committed.
+type fakeTransactor struct {
+	mu        sync.Mutex
+	committed int
+}
+
+func (t *fakeTransactor) WithinTx(fn func(tx *sql.Tx) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := fn(nil); err != nil {
+		return err
+	}
+	t.committed++
+	return nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	store *fakeStore
+}
+
+func (r *fakeUserRepo) WithTx(tx *sql.Tx) repository.UserRepository { return r }
+
+func (r *fakeUserRepo) GetUserByID(id uuid.UUID) (*model.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if user, ok := r.store.users[id]; ok {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetUserByRegistration(registration string) (*model.User, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, user := range r.store.users {
+		if user.Registration == registration {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) LockUser(id uuid.UUID) error {
+	r.store.mu.Lock()
+	r.store.locks++
+	onLock := r.store.onLock
+	r.store.mu.Unlock()
+	if onLock != nil {
+		onLock(id)
+	}
+	return nil
+}
+
+// eraseOnLock marks the user erased the first time their lock is granted.
+func (s *fakeStore) eraseOnLock() {
+	s.onLock = func(id uuid.UUID) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		erasedAt := time.Now()
+		s.users[id].ErasedAt = &erasedAt
+		s.onLock = nil
+	}
+}
+
+type fakeBookRepo struct {
+	repository.BookRepository
+	store *fakeStore
+}
+
+func (r *fakeBookRepo) WithTx(tx *sql.Tx) repository.BookRepository { return r }
+
+func (r *fakeBookRepo) GetBookByID(id uuid.UUID) (*model.Book, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if book, ok := r.store.books[id]; ok {
+		copied := *book
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeBookRepo) LockBook(id uuid.UUID) (*model.Book, error) {
+	return r.GetBookByID(id)
+}
+
+func (r *fakeBookRepo) UpdateBook(book *model.Book) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	copied := *book
+	r.store.books[book.ID] = &copied
+	return nil
+}
+
+func (r *fakeBookRepo) GetBookByBarcode(barcode string) (*model.Book, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, book := range r.store.books {
+		if book.Barcode == barcode {
+			copied := *book
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeBookRepo) CreateBook(book *model.Book) error {
+	return r.UpdateBook(book)
+}
+
+func (r *fakeBookRepo) SetWithdrawn(book *model.Book) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	stored := r.store.books[book.ID]
+	stored.WithdrawnAt, stored.WithdrawalReason, stored.Available = book.WithdrawnAt, book.WithdrawalReason, book.Available
+	return nil
+}
+
+func (r *fakeBookRepo) NextItemBarcodeSequence() (int64, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.sequence++
+	return r.store.sequence, nil
+}
+
+func (r *fakeBookRepo) GetBranchAvailability(bookID uuid.UUID) ([]model.BranchAvailability, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	book, ok := r.store.books[bookID]
+	if !ok {
+		return []model.BranchAvailability{}, nil
+	}
+	entry := model.BranchAvailability{}
+	for _, item := range r.store.books {
+		if item.Isbn != book.Isbn || item.WithdrawnAt != nil {
+			continue
+		}
+		entry.Total++
+		if item.Available {
+			entry.Available++
+		}
+	}
+	return []model.BranchAvailability{entry}, nil
+}
+
+type fakeLoanRepo struct {
+	repository.LoanRepository
+	store *fakeStore
+}
+
+func (r *fakeLoanRepo) WithTx(tx *sql.Tx) repository.LoanRepository { return r }
+
+func (r *fakeLoanRepo) CreateLoan(loan *model.Loan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	loan.ID = uuid.New()
+	copied := *loan
+	r.store.loans[loan.ID] = &copied
+	return nil
+}
+
+func (r *fakeLoanRepo) GetLoanByID(id uuid.UUID) (*model.Loan, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if loan, ok := r.store.loans[id]; ok {
+		copied := *loan
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeLoanRepo) UpdateLoan(loan *model.Loan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	copied := *loan
+	r.store.loans[loan.ID] = &copied
+	return nil
+}
+
+func (r *fakeLoanRepo) GetLoansByBookID(bookID uuid.UUID) ([]model.Loan, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	loans := make([]model.Loan, 0)
+	for _, loan := range r.store.loans {
+		if loan.BookID == bookID {
+			loans = append(loans, *loan)
+		}
+	}
+	return loans, nil
+}
+
+func (r *fakeLoanRepo) CountActiveLoansByUserID(userID uuid.UUID) (int, error) {
+	// give concurrent checkouts the chance to interleave with this check
+	time.Sleep(time.Millisecond)
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	count := 0
+	for _, loan := range r.store.loans {
+		if loan.UserID == userID && !loan.Returned {
+			count++
+		}
+	}
+	return count, nil
+}
+
+type fakeHoldRepo struct {
+	repository.HoldRepository
+	store *fakeStore
+}
+
+func (r *fakeHoldRepo) WithTx(tx *sql.Tx) repository.HoldRepository { return r }
+
+func (r *fakeHoldRepo) CreateHold(hold *model.Hold) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	hold.ID = uuid.New()
+	hold.CreatedAt = time.Now()
+	copied := *hold
+	r.store.holds[hold.ID] = &copied
+	return nil
+}
+
+func (r *fakeHoldRepo) GetHoldByID(id uuid.UUID) (*model.Hold, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if hold, ok := r.store.holds[id]; ok {
+		copied := *hold
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeHoldRepo) UpdateHold(hold *model.Hold) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	copied := *hold
+	r.store.holds[hold.ID] = &copied
+	return nil
+}
+
+func (r *fakeHoldRepo) GetHoldsByBookID(bookID uuid.UUID) ([]model.Hold, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	holds := make([]model.Hold, 0)
+	for _, hold := range r.store.holds {
+		if hold.BookID == bookID {
+			holds = append(holds, *hold)
+		}
+	}
+	return holds, nil
+}
+
+func (r *fakeHoldRepo) CountActiveHoldsByUserID(userID uuid.UUID) (int, error) {
+	time.Sleep(time.Millisecond)
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	count := 0
+	for _, hold := range r.store.holds {
+		if hold.UserID == userID && (hold.Status == model.HoldStatusWaiting || hold.Status == model.HoldStatusReady) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeHoldRepo) GetReadyHold(bookID uuid.UUID) (*model.Hold, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, hold := range r.store.holds {
+		if hold.BookID == bookID && hold.Status == model.HoldStatusReady {
+			copied := *hold
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeHoldRepo) GetHoldsForTitle(bookID uuid.UUID) ([]model.Hold, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	holds := make([]model.Hold, 0)
+	book, ok := r.store.books[bookID]
+	if !ok {
+		return holds, nil
+	}
+	for _, hold := range r.store.holds {
+		if held, ok := r.store.books[hold.BookID]; ok && held.Isbn == book.Isbn {
+			holds = append(holds, *hold)
+		}
+	}
+	return holds, nil
+}
+
+// GetNextWaitingHoldForTitle matches holds on any copy of the title, oldest
+// first, skipping those a copy is already being shipped to.
+func (r *fakeHoldRepo) GetNextWaitingHoldForTitle(bookID uuid.UUID) (*model.Hold, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	book, ok := r.store.books[bookID]
+	if !ok {
+		return nil, nil
+	}
+	var next *model.Hold
+	for _, hold := range r.store.holds {
+		held, ok := r.store.books[hold.BookID]
+		if !ok || held.Isbn != book.Isbn || hold.Status != model.HoldStatusWaiting || r.store.shipping(hold.ID) {
+			continue
+		}
+		if next == nil || hold.CreatedAt.Before(next.CreatedAt) {
+			next = hold
+		}
+	}
+	if next == nil {
+		return nil, nil
+	}
+	copied := *next
+	return &copied, nil
+}
+
+type fakeFineRepo struct {
+	repository.FineRepository
+	store *fakeStore
+}
+
+func (r *fakeFineRepo) WithTx(tx *sql.Tx) repository.FineRepository { return r }
+
+func (r *fakeFineRepo) CreateFine(fine *model.Fine) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	fine.ID = uuid.New()
+	r.store.fines = append(r.store.fines, *fine)
+	return nil
+}
+
+type fakeTransferRepo struct {
+	repository.TransferRepository
+	store *fakeStore
+}
+
+func (r *fakeTransferRepo) WithTx(tx *sql.Tx) repository.TransferRepository { return r }
+
+func (r *fakeTransferRepo) CreateTransfer(transfer *model.Transfer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	transfer.ID = uuid.New()
+	if transfer.Status == "" {
+		transfer.Status = model.TransferStatusRequested
+	}
+	r.store.transfers = append(r.store.transfers, *transfer)
+	return nil
+}
+
+func (r *fakeTransferRepo) GetTransferByID(id uuid.UUID) (*model.Transfer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, transfer := range r.store.transfers {
+		if transfer.ID == id {
+			return &transfer, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeTransferRepo) UpdateTransfer(transfer *model.Transfer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for i := range r.store.transfers {
+		if r.store.transfers[i].ID == transfer.ID {
+			r.store.transfers[i] = *transfer
+		}
+	}
+	return nil
+}
+
+type fakeContributorRepo struct {
+	repository.ContributorRepository
+}
+
+func (r *fakeContributorRepo) WithTx(tx *sql.Tx) repository.ContributorRepository { return r }
+
+func (r *fakeContributorRepo) SetBookContributors(bookID uuid.UUID, credits []model.BookContributor) error {
+	return nil
+}
+
+type fakeSubjectRepo struct {
+	repository.SubjectRepository
+}
+
+func (r *fakeSubjectRepo) WithTx(tx *sql.Tx) repository.SubjectRepository { return r }
+
+func (r *fakeSubjectRepo) SetBookSubjects(bookID uuid.UUID, subjectIDs []uuid.UUID) error {
+	return nil
+}
+
+type fakeOutboxRepo struct {
+	repository.OutboxRepository
+	store *fakeStore
+}
+
+func (r *fakeOutboxRepo) WithTx(tx *sql.Tx) repository.OutboxRepository { return r }
+
+func (r *fakeOutboxRepo) Append(event *model.OutboxEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.events = append(r.store.events, *event)
+	return nil
+}
+
+type fakeCategoryRepo struct {
+	repository.PatronCategoryRepository
+	categories map[string]*model.PatronCategory
+}
+
+func (r *fakeCategoryRepo) GetCategoryByCode(code string) (*model.PatronCategory, error) {
+	return r.categories[code], nil
+}
+
+type fakeRuleRepo struct {
+	repository.CirculationRuleRepository
+	rules []model.CirculationRule
+}
+
+func (r *fakeRuleRepo) GetMatchingRules(category, itemType, branchCode string) ([]model.CirculationRule, error) {
+	return append([]model.CirculationRule(nil), r.rules...), nil
+}
+
+func (r *fakeRuleRepo) GetRuleByID(id uuid.UUID) (*model.CirculationRule, error) {
+	for i := range r.rules {
+		if r.rules[i].ID == id {
+			return &r.rules[i], nil
+		}
+	}
+	return nil, nil
+}
+
+type fakeBranchRepo struct {
+	repository.BranchRepository
+}
+
+func (r *fakeBranchRepo) GetBranchByID(id uuid.UUID) (*model.Branch, error) {
+	return &model.Branch{ID: id, Code: "B-" + id.String()[:4]}, nil
+}
+
+// circulationFixture wires the loan and hold services to one fake store.
+type circulationFixture struct {
+	store       *fakeStore
+	transactor  *fakeTransactor
+	categories  *fakeCategoryRepo
+	rules       *fakeRuleRepo
+	loans       LoanService
+	holds       HoldService
+	circulation CirculationService
+	router      *itemRouter
+}
+
+func newCirculationFixture() *circulationFixture {
+	store := newFakeStore()
+	transactor := &fakeTransactor{}
+	categories := &fakeCategoryRepo{categories: map[string]*model.PatronCategory{
+		"student": {Code: "student", MaxLoans: 2, LoanPeriodDays: 14, MaxRenewals: 1, MaxHolds: 2},
+	}}
+	rules := &fakeRuleRepo{}
+
+	userRepo := &fakeUserRepo{store: store}
+	bookRepo := &fakeBookRepo{store: store}
+	loanRepo := &fakeLoanRepo{store: store}
+	holdRepo := &fakeHoldRepo{store: store}
+	fineRepo := &fakeFineRepo{store: store}
+	transferRepo := &fakeTransferRepo{store: store}
+	outboxRepo := &fakeOutboxRepo{store: store}
+	branchRepo := &fakeBranchRepo{}
+	// without templates every notification fails to render and is only logged
+	notifier := notifications.NewNotifier(notifications.NewTemplates(""), userRepo, nil)
+	loans := NewLoanService(loanRepo, userRepo, bookRepo, categories, rules, holdRepo, fineRepo, branchRepo, transferRepo, notifier, transactor, outboxRepo)
+
+	return &circulationFixture{
+		store:       store,
+		transactor:  transactor,
+		categories:  categories,
+		rules:       rules,
+		loans:       loans,
+		holds:       NewHoldService(holdRepo, userRepo, bookRepo, loanRepo, categories, rules, branchRepo, transferRepo, notifier, transactor, outboxRepo),
+		circulation: NewCirculationService(loans, nil, loanRepo, userRepo, bookRepo, holdRepo, branchRepo, transferRepo),
+		router:      &itemRouter{bookRepo: bookRepo, holdRepo: holdRepo, transferRepo: transferRepo, branchRepo: branchRepo, notifier: notifier, transactor: transactor, outboxRepo: outboxRepo},
+	}
+}
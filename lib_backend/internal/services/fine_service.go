@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FineService interface {
+	GetFinesByUserID(userID uuid.UUID) ([]model.Fine, error)
+	PayFine(id uuid.UUID) (*model.Fine, error)
+}
+
+type fineServiceImpl struct {
+	fineRepo repository.FineRepository
+}
+
+func NewFineService(fineRepo repository.FineRepository) FineService {
+	return &fineServiceImpl{fineRepo: fineRepo}
+}
+
+func (s *fineServiceImpl) GetFinesByUserID(userID uuid.UUID) ([]model.Fine, error) {
+	fines, err := s.fineRepo.GetFinesByUserID(userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fines by user ID: %w", err)
+	}
+
+	return fines, nil
+}
+
+func (s *fineServiceImpl) PayFine(id uuid.UUID) (*model.Fine, error) {
+	fine, err := s.fineRepo.GetFineByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fine for payment: %w", err)
+	}
+
+	if fine == nil {
+		return nil, fmt.Errorf("fine with ID %s not found", id.String())
+	}
+
+	if fine.Paid {
+		return nil, fmt.Errorf("fine with ID %s has already been paid", id.String())
+	}
+
+	paidAt := time.Now()
+	fine.Paid = true
+	fine.PaidAt = &paidAt
+	err = s.fineRepo.UpdateFine(fine)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to pay fine: %w", err)
+	}
+
+	return fine, nil
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
+	"lib_backend/internal/repository"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HoldService interface {
+	PlaceHold(hold *model.Hold) (*model.Hold, error)
+	GetHoldByID(id uuid.UUID) (*model.Hold, error)
+	CancelHold(id uuid.UUID) (*model.Hold, error)
+	GetHoldsByUserID(userID uuid.UUID) ([]model.Hold, error)
+	GetHoldsByBookID(bookID uuid.UUID) ([]model.Hold, error)
+	ExpireHolds() (int, error)
+}
+
+type holdServiceImpl struct {
+	holdRepo repository.HoldRepository
+	userRepo repository.UserRepository
+	bookRepo repository.BookRepository
+	loanRepo repository.LoanRepository
+	policy   *policyResolver
+	router   *itemRouter
+
+	transactor repository.Transactor
+}
+
+func NewHoldService(holdRepo repository.HoldRepository, userRepo repository.UserRepository, bookRepo repository.BookRepository, loanRepo repository.LoanRepository, categoryRepo repository.PatronCategoryRepository, ruleRepo repository.CirculationRuleRepository, branchRepo repository.BranchRepository, transferRepo repository.TransferRepository, notifier *notifications.Notifier, transactor repository.Transactor, outboxRepo repository.OutboxRepository) HoldService {
+	return &holdServiceImpl{
+		holdRepo: holdRepo,
+		userRepo: userRepo,
+		bookRepo: bookRepo,
+		loanRepo: loanRepo,
+		policy:   &policyResolver{ruleRepo: ruleRepo, categoryRepo: categoryRepo, branchRepo: branchRepo},
+		router:   &itemRouter{bookRepo: bookRepo, holdRepo: holdRepo, transferRepo: transferRepo, branchRepo: branchRepo, notifier: notifier, transactor: transactor, outboxRepo: outboxRepo},
+
+		transactor: transactor,
+	}
+}
+
+func (s *holdServiceImpl) PlaceHold(hold *model.Hold) (*model.Hold, error) {
+	user, err := s.userRepo.GetUserByID(hold.UserID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence for hold: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with ID %s not found for hold", hold.UserID.String())
+	}
+
+	if user.ErasedAt != nil {
+		return nil, fmt.Errorf("user with ID %s is erased", hold.UserID.String())
+	}
+
+	book, err := s.bookRepo.GetBookByID(hold.BookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check book existence for hold: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("book with ID %s not found for hold", hold.BookID.String())
+	}
+
+	if book.WithdrawnAt != nil {
+		return nil, fmt.Errorf("holds are not allowed on book with ID %s", book.ID.String())
+	}
+
+	category, err := s.policy.categoryFor(user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	branchCode, err := s.policy.branchCode(book.CurrentBranchID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	policy, _, err := s.policy.resolve(category, book.ItemType, branchCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.NonCirculating || !policy.HoldsAllowed {
+		return nil, fmt.Errorf("holds are not allowed on book with ID %s", book.ID.String())
+	}
+
+	// holds are placed on the title, so any copy on a shelf makes one pointless
+	availability, err := s.bookRepo.GetBranchAvailability(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check availability for hold: %w", err)
+	}
+
+	for _, entry := range availability {
+		if entry.Available > 0 {
+			return nil, fmt.Errorf("book with ID %s is available and cannot be held", book.ID.String())
+		}
+	}
+
+	if hold.PickupBranchID != nil {
+		pickupBranch, err := s.policy.branchRepo.GetBranchByID(*hold.PickupBranchID)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to check pickup branch for hold: %w", err)
+		}
+
+		if pickupBranch == nil {
+			return nil, fmt.Errorf("pickup branch with ID %s not found for hold", hold.PickupBranchID.String())
+		}
+	}
+
+	existingHolds, err := s.holdRepo.GetHoldsForTitle(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing holds for book: %w", err)
+	}
+
+	for _, existing := range existingHolds {
+		if existing.UserID == user.ID && (existing.Status == model.HoldStatusWaiting || existing.Status == model.HoldStatusReady) {
+			return nil, fmt.Errorf("user %s already has a hold on book %s", user.ID.String(), book.ID.String())
+		}
+	}
+
+	loans, err := s.loanRepo.GetLoansByBookID(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check current loan for book: %w", err)
+	}
+
+	for _, loan := range loans {
+		if !loan.Returned && loan.UserID == user.ID {
+			return nil, fmt.Errorf("user %s already has book %s on loan", user.ID.String(), book.ID.String())
+		}
+	}
+
+	hold.Status = model.HoldStatusWaiting
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		// counting under the user lock keeps concurrent holds of the same
+		// patron from all passing the limit
+		userRepo := s.userRepo.WithTx(tx)
+		if err := userRepo.LockUser(user.ID); err != nil {
+			return err
+		}
+
+		// the patron may have been erased since they were read
+		locked, err := userRepo.GetUserByID(user.ID)
+
+		if err != nil {
+			return fmt.Errorf("failed to check user existence for hold: %w", err)
+		}
+
+		if locked == nil {
+			return fmt.Errorf("user with ID %s not found for hold", user.ID.String())
+		}
+
+		if locked.ErasedAt != nil {
+			return fmt.Errorf("user with ID %s is erased", user.ID.String())
+		}
+
+		activeHolds, err := s.holdRepo.WithTx(tx).CountActiveHoldsByUserID(user.ID)
+
+		if err != nil {
+			return fmt.Errorf("failed to count active holds for user: %w", err)
+		}
+
+		if activeHolds >= category.MaxHolds {
+			return &PatronLimitError{Category: category.Code, Limit: "holds", Max: category.MaxHolds, Current: activeHolds}
+		}
+
+		if err := s.holdRepo.WithTx(tx).CreateHold(hold); err != nil {
+			return fmt.Errorf("failed to create hold: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+func (s *holdServiceImpl) GetHoldByID(id uuid.UUID) (*model.Hold, error) {
+	hold, err := s.holdRepo.GetHoldByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold by ID: %w", err)
+	}
+
+	if hold == nil {
+		return nil, fmt.Errorf("hold with ID %s not found", id.String())
+	}
+
+	return hold, nil
+}
+
+func (s *holdServiceImpl) CancelHold(id uuid.UUID) (*model.Hold, error) {
+	hold, err := s.holdRepo.GetHoldByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hold for cancellation: %w", err)
+	}
+
+	if hold == nil {
+		return nil, fmt.Errorf("hold with ID %s not found", id.String())
+	}
+
+	if hold.Status != model.HoldStatusWaiting && hold.Status != model.HoldStatusReady {
+		return nil, fmt.Errorf("hold with ID %s is no longer active", id.String())
+	}
+
+	if err := s.closeHold(hold, model.HoldStatusCancelled); err != nil {
+		return nil, fmt.Errorf("failed to cancel hold: %w", err)
+	}
+
+	return hold, nil
+}
+
+// closeHold moves a hold to a final status. A copy waiting on the hold shelf
+// for it is routed in the same transaction, to the next waiting patron, home
+// or back on the shelf, so it never stays held for nobody.
+func (s *holdServiceImpl) closeHold(hold *model.Hold, status string) error {
+	wasReady := hold.Status == model.HoldStatusReady
+	hold.Status = status
+
+	var book *model.Book
+	var result *routed
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.holdRepo.WithTx(tx).UpdateHold(hold); err != nil {
+			return err
+		}
+
+		if !wasReady {
+			return nil
+		}
+
+		var err error
+		book, err = s.bookRepo.WithTx(tx).LockBook(hold.BookID)
+
+		if err != nil {
+			return err
+		}
+
+		if book == nil {
+			return fmt.Errorf("book with ID %s not found to release from hold shelf", hold.BookID.String())
+		}
+
+		result, err = s.router.routeTx(tx, book, true)
+		return err
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if result != nil {
+		s.router.notifyReady(result.ready, book)
+	}
+
+	return nil
+}
+
+func (s *holdServiceImpl) GetHoldsByUserID(userID uuid.UUID) ([]model.Hold, error) {
+	holds, err := s.holdRepo.GetHoldsByUserID(userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holds by user ID: %w", err)
+	}
+
+	return holds, nil
+}
+
+func (s *holdServiceImpl) GetHoldsByBookID(bookID uuid.UUID) ([]model.Hold, error) {
+	holds, err := s.holdRepo.GetHoldsByBookID(bookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get holds by book ID: %w", err)
+	}
+
+	return holds, nil
+}
+
+// ExpireHolds expires the holds nobody picked up in time and passes their
+// copies on to the next patron in line.
+func (s *holdServiceImpl) ExpireHolds() (int, error) {
+	holds, err := s.holdRepo.GetExpiredHolds(time.Now())
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get expired holds: %w", err)
+	}
+
+	expired, failed := 0, 0
+
+	for i := range holds {
+		hold := &holds[i]
+
+		if err := s.closeHold(hold, model.HoldStatusExpired); err != nil {
+			log.Printf("WARNING: failed to expire hold %s: %v", hold.ID.String(), err)
+			failed++
+			continue
+		}
+
+		expired++
+	}
+
+	if failed > 0 {
+		return expired, fmt.Errorf("failed to expire %d of %d holds", failed, len(holds))
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestPlaceHoldRejectsTitleWithAvailableCopy(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	onLoan := f.store.addBook("9788535914849", false)
+	f.store.addBook("9788535914849", true)
+
+	_, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: onLoan.ID})
+
+	if err == nil || !strings.HasSuffix(err.Error(), "is available and cannot be held") {
+		t.Fatalf("PlaceHold() error = %v, want available rejection", err)
+	}
+}
+
+func TestPlaceHoldRejectsSecondHoldOnSameTitle(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	first := f.store.addBook("9788535914849", false)
+	second := f.store.addBook("9788535914849", false)
+
+	if _, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: first.ID}); err != nil {
+		t.Fatalf("first PlaceHold() error = %v", err)
+	}
+
+	_, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: second.ID})
+
+	if err == nil || !strings.Contains(err.Error(), "already has a hold") {
+		t.Fatalf("second PlaceHold() error = %v, want duplicate hold rejection", err)
+	}
+}
+
+func TestPlaceHoldRejectsErasedUser(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	erasedAt := time.Now()
+	user.ErasedAt = &erasedAt
+	book := f.store.addBook("9788535914849", false)
+
+	_, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: book.ID})
+
+	if err == nil || err.Error() != "user with ID "+user.ID.String()+" is erased" {
+		t.Fatalf("PlaceHold() error = %v, want erased user rejection", err)
+	}
+
+	if len(f.store.holds) != 0 {
+		t.Fatalf("%d holds placed, want none", len(f.store.holds))
+	}
+}
+
+func TestPlaceHoldRejectsUserErasedWhileWaitingForLock(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	book := f.store.addBook("9788535914849", false)
+	f.store.eraseOnLock()
+
+	_, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: book.ID})
+
+	if err == nil || err.Error() != "user with ID "+user.ID.String()+" is erased" {
+		t.Fatalf("PlaceHold() error = %v, want erased user rejection", err)
+	}
+
+	if len(f.store.holds) != 0 {
+		t.Fatalf("%d holds placed, want none", len(f.store.holds))
+	}
+}
+
+func TestRouteTrapsReturnedCopyForTitleHold(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	requested := f.store.addBook("9788535914849", false)
+	returned := f.store.addBook("9788535914849", false)
+
+	hold, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: requested.ID})
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+
+	if _, err := f.router.route(returned, true); err != nil {
+		t.Fatalf("route() error = %v", err)
+	}
+
+	trapped := f.store.holds[hold.ID]
+	if trapped.BookID != returned.ID || trapped.Status != model.HoldStatusReady {
+		t.Fatalf("hold = %+v, want it ready on the returned copy %s", trapped, returned.ID)
+	}
+
+	if f.store.books[returned.ID].Available {
+		t.Fatal("trapped copy is still available")
+	}
+}
+
+func TestRouteShipsTrappedCopyToPickupBranch(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	requested := f.store.addBook("9788535914849", false)
+	returned := f.store.addBook("9788535914849", false)
+
+	here, pickup := uuid.New(), uuid.New()
+	returned.CurrentBranchID = &here
+
+	hold, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: requested.ID, PickupBranchID: &pickup})
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+
+	transfer, err := f.router.route(returned, true)
+	if err != nil {
+		t.Fatalf("route() error = %v", err)
+	}
+
+	if transfer == nil || transfer.BookID != returned.ID || transfer.ToBranchID != pickup || *transfer.HoldID != hold.ID {
+		t.Fatalf("route() transfer = %+v, want the returned copy sent to the pickup branch", transfer)
+	}
+
+	waiting := f.store.holds[hold.ID]
+	if waiting.BookID != returned.ID || waiting.Status != model.HoldStatusWaiting {
+		t.Fatalf("hold = %+v, want it waiting on the copy in transit", waiting)
+	}
+}
+
+func TestCancelReadyHoldPassesCopyToNextHoldInOneTransaction(t *testing.T) {
+	f := newCirculationFixture()
+	first, second := f.store.addUser("student"), f.store.addUser("student")
+	book := f.store.addBook("9788535914849", false)
+
+	ready, err := f.holds.PlaceHold(&model.Hold{UserID: first.ID, BookID: book.ID})
+	if err != nil {
+		t.Fatalf("first PlaceHold() error = %v", err)
+	}
+	if _, err := f.router.route(f.store.books[book.ID], true); err != nil {
+		t.Fatalf("route() error = %v", err)
+	}
+	waiting, err := f.holds.PlaceHold(&model.Hold{UserID: second.ID, BookID: book.ID})
+	if err != nil {
+		t.Fatalf("second PlaceHold() error = %v", err)
+	}
+
+	committed := f.transactor.committed
+	if _, err := f.holds.CancelHold(ready.ID); err != nil {
+		t.Fatalf("CancelHold() error = %v", err)
+	}
+
+	if f.transactor.committed != committed+1 {
+		t.Fatalf("cancellation committed %d transactions, want one", f.transactor.committed-committed)
+	}
+	if status := f.store.holds[ready.ID].Status; status != model.HoldStatusCancelled {
+		t.Fatalf("cancelled hold status = %s", status)
+	}
+	if next := f.store.holds[waiting.ID]; next.Status != model.HoldStatusReady || next.BookID != book.ID {
+		t.Fatalf("next hold = %+v, want it ready on the released copy", next)
+	}
+	if f.store.books[book.ID].Available {
+		t.Fatal("released copy is available while a hold is ready on it")
+	}
+}
+
+func TestCancelReadyHoldReturnsRoutingError(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	book := f.store.addBook("9788535914849", false)
+
+	hold, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: book.ID})
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+	if _, err := f.router.route(f.store.books[book.ID], true); err != nil {
+		t.Fatalf("route() error = %v", err)
+	}
+	delete(f.store.books, book.ID)
+
+	if _, err := f.holds.CancelHold(hold.ID); err == nil || !strings.Contains(err.Error(), "not found to release from hold shelf") {
+		t.Fatalf("CancelHold() error = %v, want the routing failure", err)
+	}
+}
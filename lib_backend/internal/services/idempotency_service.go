@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/config"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"time"
+)
+
+type IdempotencyService interface {
+	Begin(scope string, key string, requestHash string) (*model.IdempotencyRecord, error)
+	Complete(scope string, key string, statusCode int, contentType string, body []byte) error
+	Release(scope string, key string) error
+	PurgeExpired() (int64, error)
+}
+
+type idempotencyServiceImpl struct {
+	idempotencyRepo repository.IdempotencyRepository
+	cfg             config.IdempotencyConfig
+}
+
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepository, cfg config.IdempotencyConfig) IdempotencyService {
+	return &idempotencyServiceImpl{idempotencyRepo: idempotencyRepo, cfg: cfg}
+}
+
+// Begin claims key within the scope of a client for a request. It returns nil when the request should
+// run, or the stored record when its response should be replayed.
+func (s *idempotencyServiceImpl) Begin(scope string, key string, requestHash string) (*model.IdempotencyRecord, error) {
+	now := time.Now()
+
+	reserved, err := s.idempotencyRepo.Reserve(scope, key, requestHash, now.Add(-s.cfg.Retention), now.Add(-s.cfg.LockTimeout))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	record, err := s.idempotencyRepo.GetRecord(scope, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the key was released between both queries, so its request is running again
+	if record == nil {
+		return nil, fmt.Errorf("idempotency key %s is still in progress", key)
+	}
+
+	if record.RequestHash != requestHash {
+		return nil, fmt.Errorf("idempotency key %s was used for a different request", key)
+	}
+
+	if record.StatusCode == nil {
+		return nil, fmt.Errorf("idempotency key %s is still in progress", key)
+	}
+
+	return record, nil
+}
+
+func (s *idempotencyServiceImpl) Complete(scope string, key string, statusCode int, contentType string, body []byte) error {
+	return s.idempotencyRepo.Complete(scope, key, statusCode, contentType, body)
+}
+
+func (s *idempotencyServiceImpl) Release(scope string, key string) error {
+	return s.idempotencyRepo.Release(scope, key)
+}
+
+func (s *idempotencyServiceImpl) PurgeExpired() (int64, error) {
+	purged, err := s.idempotencyRepo.PurgeBefore(time.Now().Add(-s.cfg.Retention))
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge idempotency keys: %w", err)
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
+	"lib_backend/internal/repository"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// itemRouter decides where a copy goes once nobody holds it: to the next
+// waiting hold, back to its home branch, or onto the shelf where it is.
+type itemRouter struct {
+	bookRepo     repository.BookRepository
+	holdRepo     repository.HoldRepository
+	transferRepo repository.TransferRepository
+	branchRepo   repository.BranchRepository
+	notifier     *notifications.Notifier
+	transactor   repository.Transactor
+	outboxRepo   repository.OutboxRepository
+}
+
+// holdPickupDays is how long a ready hold waits on the hold shelf.
+const holdPickupDays = 7
+
+// routed is what routing decided for a copy: the transfer requested to move
+// it and the hold it was put on the hold shelf for, either of which may be nil.
+type routed struct {
+	transfer *model.Transfer
+	ready    *model.Hold
+}
+
+// route saves the book with its new availability and returns the transfer
+// created to move it, if any. sendHome is false when the copy should stay at
+// its current branch, e.g. after a manual transfer.
+func (r *itemRouter) route(book *model.Book, sendHome bool) (*model.Transfer, error) {
+	var result *routed
+
+	err := r.transactor.WithinTx(func(tx *sql.Tx) error {
+		var err error
+		result, err = r.routeTx(tx, book, sendHome)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.notifyReady(result.ready, book)
+	return result.transfer, nil
+}
+
+// routeTx does the work of route inside the caller's transaction, so a return
+// and the routing of the copy commit together. Holds are queued per title, so
+// the copy is trapped for the oldest waiting hold on any copy. The patron is
+// not notified; call notifyReady with the ready hold once tx commits.
+func (r *itemRouter) routeTx(tx *sql.Tx, book *model.Book, sendHome bool) (*routed, error) {
+	hold, err := r.holdRepo.WithTx(tx).GetNextWaitingHoldForTitle(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check waiting holds for book %s: %w", book.ID.String(), err)
+	}
+
+	var transfer *model.Transfer
+
+	switch {
+	case hold != nil && hold.PickupBranchID != nil && !sameID(book.CurrentBranchID, hold.PickupBranchID):
+		transfer = &model.Transfer{BookID: book.ID, ToBranchID: *hold.PickupBranchID, HoldID: &hold.ID, Reason: model.TransferReasonHold}
+		book.Available = false
+	case hold != nil:
+		// the book goes to the hold shelf and stays unavailable for everyone else
+		book.Available = false
+	case sendHome && book.HomeBranchID != nil && !sameID(book.CurrentBranchID, book.HomeBranchID):
+		transfer = &model.Transfer{BookID: book.ID, ToBranchID: *book.HomeBranchID, Reason: model.TransferReasonReturn}
+		book.Available = false
+	default:
+		book.Available = true
+	}
+
+	if transfer != nil && book.CurrentBranchID == nil {
+		// a copy without a known location cannot be shipped, so it is simply shelved
+		log.Printf("WARNING: book %s has no current branch, skipping %s transfer", book.ID.String(), transfer.Reason)
+		transfer = nil
+		book.Available = hold == nil
+	}
+
+	result := &routed{transfer: transfer}
+
+	if hold != nil {
+		hold.BookID = book.ID
+
+		if transfer == nil {
+			if err := r.markReady(tx, hold); err != nil {
+				return nil, err
+			}
+			result.ready = hold
+		} else if err := r.holdRepo.WithTx(tx).UpdateHold(hold); err != nil {
+			return nil, fmt.Errorf("failed to trap book %s for hold %s: %w", book.ID.String(), hold.ID.String(), err)
+		}
+	}
+
+	if transfer != nil {
+		transfer.FromBranchID = *book.CurrentBranchID
+		if err := r.transferRepo.WithTx(tx).CreateTransfer(transfer); err != nil {
+			return nil, fmt.Errorf("failed to request transfer for book %s: %w", book.ID.String(), err)
+		}
+	}
+
+	if err := r.bookRepo.WithTx(tx).UpdateBook(book); err != nil {
+		return nil, fmt.Errorf("failed to update book %s after routing: %w", book.ID.String(), err)
+	}
+
+	if err := recordAvailability(r.outboxRepo, tx, book); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// markReady starts the pickup period of a hold.
+func (r *itemRouter) markReady(tx *sql.Tx, hold *model.Hold) error {
+	readyAt := time.Now()
+	expiresAt := readyAt.AddDate(0, 0, holdPickupDays)
+	hold.Status = model.HoldStatusReady
+	hold.ReadyAt = &readyAt
+	hold.ExpiresAt = &expiresAt
+
+	if err := r.holdRepo.WithTx(tx).UpdateHold(hold); err != nil {
+		return fmt.Errorf("failed to mark hold %s as ready: %w", hold.ID.String(), err)
+	}
+
+	return recordEvent(r.outboxRepo, tx, events.HoldReady, hold)
+}
+
+// notifyReady tells the patron that a hold is ready for pickup; a nil hold is
+// ignored. A failed notification is only logged, as the hold stays ready.
+func (r *itemRouter) notifyReady(hold *model.Hold, book *model.Book) {
+	if hold == nil {
+		return
+	}
+
+	data := map[string]any{"Book": book, "Hold": hold}
+
+	if book.CurrentBranchID != nil {
+		branch, err := r.branchRepo.GetBranchByID(*book.CurrentBranchID)
+		if err != nil {
+			log.Printf("WARNING: failed to get pickup branch for hold %s: %v", hold.ID.String(), err)
+		} else if branch != nil {
+			data["Branch"] = branch
+		}
+	}
+
+	if err := r.notifier.Notify(hold.UserID, model.NotificationHoldReady, data, &hold.ID); err != nil {
+		log.Printf("WARNING: failed to send hold ready notification for hold %s: %v", hold.ID.String(), err)
+	}
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
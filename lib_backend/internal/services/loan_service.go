@@ -1,10 +1,15 @@
 package services
 
 import (
+	"database/sql"
 	"fmt"
+	"lib_backend/internal/events"
 	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
 	"lib_backend/internal/repository"
 	"log"
+	"math"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,19 +19,38 @@ type LoanService interface {
 	GetLoanByID(id uuid.UUID) (*model.Loan, error)
 	GetLoansByUserID(userID uuid.UUID) ([]model.Loan, error)
 	GetLoansByBookID(bookID uuid.UUID) ([]model.Loan, error)
-	ReturnBook(loanID uuid.UUID) (*model.Loan, error)
+	ReturnBook(loanID uuid.UUID, returnBranchID *uuid.UUID) (*model.Loan, error)
+	RenewLoan(loanID uuid.UUID) (*model.Loan, error)
+	ExplainLoanPolicy(loanID uuid.UUID) (*model.PolicyExplanation, error)
+	FlagOverdueLoans() (int, error)
 	DeleteLoan(id uuid.UUID) error
 	GetAllLoans() ([]model.Loan, error)
 }
 
 type loanServiceImpl struct {
-	loanRepo repository.LoanRepository
-	userRepo repository.UserRepository
-	bookRepo repository.BookRepository
+	loanRepo   repository.LoanRepository
+	userRepo   repository.UserRepository
+	bookRepo   repository.BookRepository
+	holdRepo   repository.HoldRepository
+	fineRepo   repository.FineRepository
+	policy     *policyResolver
+	router     *itemRouter
+	transactor repository.Transactor
+	outboxRepo repository.OutboxRepository
 }
 
-func NewLoanService(loanRepo repository.LoanRepository, userRepo repository.UserRepository, bookRepo repository.BookRepository) LoanService {
-	return &loanServiceImpl{loanRepo: loanRepo, userRepo: userRepo, bookRepo: bookRepo}
+func NewLoanService(loanRepo repository.LoanRepository, userRepo repository.UserRepository, bookRepo repository.BookRepository, categoryRepo repository.PatronCategoryRepository, ruleRepo repository.CirculationRuleRepository, holdRepo repository.HoldRepository, fineRepo repository.FineRepository, branchRepo repository.BranchRepository, transferRepo repository.TransferRepository, notifier *notifications.Notifier, transactor repository.Transactor, outboxRepo repository.OutboxRepository) LoanService {
+	return &loanServiceImpl{
+		loanRepo:   loanRepo,
+		userRepo:   userRepo,
+		bookRepo:   bookRepo,
+		holdRepo:   holdRepo,
+		fineRepo:   fineRepo,
+		policy:     &policyResolver{ruleRepo: ruleRepo, categoryRepo: categoryRepo, branchRepo: branchRepo},
+		router:     &itemRouter{bookRepo: bookRepo, holdRepo: holdRepo, transferRepo: transferRepo, branchRepo: branchRepo, notifier: notifier, transactor: transactor, outboxRepo: outboxRepo},
+		transactor: transactor,
+		outboxRepo: outboxRepo,
+	}
 }
 
 func (s *loanServiceImpl) CreateLoan(loan *model.Loan) (*model.Loan, error) {
@@ -40,6 +64,16 @@ func (s *loanServiceImpl) CreateLoan(loan *model.Loan) (*model.Loan, error) {
 		return nil, fmt.Errorf("user with ID %s not found for loan", loan.UserID.String())
 	}
 
+	if user.ErasedAt != nil {
+		return nil, fmt.Errorf("user with ID %s is erased", loan.UserID.String())
+	}
+
+	category, err := s.policy.categoryFor(user)
+
+	if err != nil {
+		return nil, err
+	}
+
 	book, err := s.bookRepo.GetBookByID(loan.BookID)
 
 	if err != nil {
@@ -50,21 +84,120 @@ func (s *loanServiceImpl) CreateLoan(loan *model.Loan) (*model.Loan, error) {
 		return nil, fmt.Errorf("book with ID %s not found for loan", loan.BookID.String())
 	}
 
-	if !book.Available {
-		return nil, fmt.Errorf("book with ID %s is not available for loan", loan.BookID.String())
+	if loan.CheckoutBranchID == nil {
+		loan.CheckoutBranchID = book.CurrentBranchID
 	}
 
-	book.Available = false
-	err = s.bookRepo.UpdateBook(book)
+	branchCode, err := s.policy.branchCode(loan.CheckoutBranchID)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to update book availability after loan creation: %w", err)
+		return nil, err
 	}
 
-	err = s.loanRepo.CreateLoan(loan)
+	policy, _, err := s.policy.resolve(category, book.ItemType, branchCode)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to create loan: %w", err)
+		return nil, err
+	}
+
+	if policy.NonCirculating {
+		return nil, fmt.Errorf("book with ID %s is non-circulating", loan.BookID.String())
+	}
+
+	if loan.LoanedAt.IsZero() {
+		loan.LoanedAt = time.Now()
+	}
+	loan.DueAt = loan.LoanedAt.AddDate(0, 0, policy.LoanPeriodDays)
+
+	if policy.Rule != nil {
+		loan.RuleID = &policy.Rule.ID
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		// counting under the user lock keeps concurrent checkouts of the
+		// same patron from all passing the limit
+		userRepo := s.userRepo.WithTx(tx)
+		if err := userRepo.LockUser(user.ID); err != nil {
+			return err
+		}
+
+		// the patron may have been erased since they were read
+		locked, err := userRepo.GetUserByID(user.ID)
+
+		if err != nil {
+			return fmt.Errorf("failed to check user existence for loan: %w", err)
+		}
+
+		if locked == nil {
+			return fmt.Errorf("user with ID %s not found for loan", user.ID.String())
+		}
+
+		if locked.ErasedAt != nil {
+			return fmt.Errorf("user with ID %s is erased", user.ID.String())
+		}
+
+		activeLoans, err := s.loanRepo.WithTx(tx).CountActiveLoansByUserID(user.ID)
+
+		if err != nil {
+			return fmt.Errorf("failed to count active loans for user: %w", err)
+		}
+
+		if activeLoans >= category.MaxLoans {
+			return &PatronLimitError{Category: category.Code, Limit: "concurrent loans", Max: category.MaxLoans, Current: activeLoans}
+		}
+
+		// availability is checked on the locked copy, so two desks checking
+		// out the same copy cannot both see it available
+		book, err := s.bookRepo.WithTx(tx).LockBook(loan.BookID)
+
+		if err != nil {
+			return err
+		}
+
+		if book == nil {
+			return fmt.Errorf("book with ID %s not found for loan", loan.BookID.String())
+		}
+
+		var hold *model.Hold
+
+		if !book.Available {
+			hold, err = s.holdRepo.WithTx(tx).GetReadyHold(book.ID)
+
+			if err != nil {
+				return fmt.Errorf("failed to check ready hold for loan: %w", err)
+			}
+
+			// a book waiting on the hold shelf can only be borrowed by the patron who placed the hold
+			if hold == nil || hold.UserID != user.ID {
+				return fmt.Errorf("book with ID %s is not available for loan", loan.BookID.String())
+			}
+		}
+
+		book.Available = false
+		if err := s.bookRepo.WithTx(tx).UpdateBook(book); err != nil {
+			return fmt.Errorf("failed to update book availability after loan creation: %w", err)
+		}
+
+		if err := recordAvailability(s.outboxRepo, tx, book); err != nil {
+			return err
+		}
+
+		if err := s.loanRepo.WithTx(tx).CreateLoan(loan); err != nil {
+			return fmt.Errorf("failed to create loan: %w", err)
+		}
+
+		if hold != nil {
+			hold.Status = model.HoldStatusFulfilled
+			if err := s.holdRepo.WithTx(tx).UpdateHold(hold); err != nil {
+				return fmt.Errorf("failed to mark hold %s as fulfilled: %w", hold.ID.String(), err)
+			}
+		}
+
+		return recordEvent(s.outboxRepo, tx, events.LoanCreated, loan)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return loan, nil
@@ -104,7 +237,7 @@ func (s *loanServiceImpl) GetLoansByBookID(bookID uuid.UUID) ([]model.Loan, erro
 	return loans, nil
 }
 
-func (s *loanServiceImpl) ReturnBook(loanID uuid.UUID) (*model.Loan, error) {
+func (s *loanServiceImpl) ReturnBook(loanID uuid.UUID, returnBranchID *uuid.UUID) (*model.Loan, error) {
 	loan, err := s.loanRepo.GetLoanByID(loanID)
 
 	if err != nil {
@@ -119,35 +252,319 @@ func (s *loanServiceImpl) ReturnBook(loanID uuid.UUID) (*model.Loan, error) {
 		return nil, fmt.Errorf("loan with ID %s has already been returned", loanID.String())
 	}
 
+	book, err := s.bookRepo.GetBookByID(loan.BookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book for return: %w", err)
+	}
+
+	returnedAt := time.Now()
 	loan.Returned = true
-	err = s.loanRepo.UpdateLoan(loan)
+	loan.ReturnedAt = &returnedAt
+	loan.ReturnBranchID = returnBranchID
+
+	fine, err := s.overdueFine(loan)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var result *routed
+
+	// the return, its fine and the routing of the copy commit together, so a
+	// returned loan never leaves the book unavailable or the fine uncharged
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.loanRepo.WithTx(tx).UpdateLoan(loan); err != nil {
+			return fmt.Errorf("failed to update loan status to returned: %w", err)
+		}
+
+		if fine != nil {
+			if err := s.fineRepo.WithTx(tx).CreateFine(fine); err != nil {
+				return fmt.Errorf("failed to charge overdue fine: %w", err)
+			}
+		}
+
+		if err := recordEvent(s.outboxRepo, tx, events.LoanReturned, loan); err != nil {
+			return err
+		}
+
+		if book == nil {
+			log.Printf("WARNING: book %s associated with returned loan %s not found cannot update availability", loan.BookID.String(), loanID.String())
+			return nil
+		}
+
+		if returnBranchID != nil {
+			book.CurrentBranchID = returnBranchID
+		}
+
+		var err error
+		result, err = s.router.routeTx(tx, book, true)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if result != nil {
+		s.router.notifyReady(result.ready, book)
+	}
+
+	return loan, nil
+}
+
+// overdueFine returns the fine owed for a loan returned late under its
+// applied policy, or nil when nothing is owed.
+func (s *loanServiceImpl) overdueFine(loan *model.Loan) (*model.Fine, error) {
+	if loan.ReturnedAt == nil || !loan.ReturnedAt.After(loan.DueAt) {
+		return nil, nil
+	}
+
+	policy, err := s.appliedPolicy(loan)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy for overdue fine: %w", err)
+	}
+
+	if policy.FinePerDayCents == 0 {
+		return nil, nil
+	}
+
+	daysLate := int(math.Ceil(loan.ReturnedAt.Sub(loan.DueAt).Hours() / 24))
+
+	return &model.Fine{
+		LoanID:      &loan.ID,
+		UserID:      loan.UserID,
+		DaysLate:    daysLate,
+		AmountCents: daysLate * policy.FinePerDayCents,
+	}, nil
+}
+
+// appliedPolicy returns the policy recorded on the loan when it was created,
+// falling back to the patron category defaults when no rule was applied.
+func (s *loanServiceImpl) appliedPolicy(loan *model.Loan) (*model.CirculationPolicy, error) {
+	if loan.RuleID != nil {
+		rule, err := s.policy.ruleRepo.GetRuleByID(*loan.RuleID)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get circulation rule applied to loan: %w", err)
+		}
+
+		if rule != nil {
+			return rulePolicy(rule), nil
+		}
+	}
+
+	user, err := s.userRepo.GetUserByID(loan.UserID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user of loan: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with ID %s of loan %s not found", loan.UserID.String(), loan.ID.String())
+	}
+
+	category, err := s.policy.categoryFor(user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return categoryPolicy(category), nil
+}
+
+func (s *loanServiceImpl) RenewLoan(loanID uuid.UUID) (*model.Loan, error) {
+	loan, err := s.loanRepo.GetLoanByID(loanID)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to update loan status to returned: %w", err)
+		return nil, fmt.Errorf("failed to get loan for renewal: %w", err)
+	}
+
+	if loan == nil {
+		return nil, fmt.Errorf("loan with ID %s not found for renewal", loanID.String())
+	}
+
+	if loan.Returned {
+		return nil, fmt.Errorf("loan with ID %s has already been returned", loanID.String())
+	}
+
+	user, err := s.userRepo.GetUserByID(loan.UserID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for renewal: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with ID %s of loan %s not found", loan.UserID.String(), loanID.String())
+	}
+
+	category, err := s.policy.categoryFor(user)
+
+	if err != nil {
+		return nil, err
 	}
 
 	book, err := s.bookRepo.GetBookByID(loan.BookID)
 
 	if err != nil {
-		log.Printf("WARNING: failed to get book %s to update availability after return loan %s: %v", loan.BookID.String(), loanID.String(), err)
-		return loan, nil
+		return nil, fmt.Errorf("failed to get book for renewal: %w", err)
 	}
 
 	if book == nil {
-		log.Printf("WARNING: book %s associated with returned loan %s not found cannot update availability", loan.BookID.String(), loanID.String())
-		return loan, nil
+		return nil, fmt.Errorf("book with ID %s of loan %s not found", loan.BookID.String(), loanID.String())
 	}
-	book.Available = true
-	err = s.bookRepo.UpdateBook(book)
+
+	branchCode, err := s.policy.branchCode(loan.CheckoutBranchID)
 
 	if err != nil {
-		log.Printf("WARNING: failed to update book %s availability to true after return loan %s: %v", loan.BookID.String(), loanID.String(), err)
-		return loan, nil
+		return nil, err
+	}
+
+	policy, _, err := s.policy.resolve(category, book.ItemType, branchCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if loan.Renewals >= policy.MaxRenewals {
+		return nil, &PatronLimitError{Category: category.Code, Limit: "renewals", Max: policy.MaxRenewals, Current: loan.Renewals}
+	}
+
+	hold, err := s.holdRepo.GetNextWaitingHoldForTitle(book.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check waiting holds for renewal: %w", err)
+	}
+
+	if hold != nil {
+		return nil, fmt.Errorf("loan with ID %s cannot be renewed because the book has holds", loanID.String())
+	}
+
+	loan.Renewals++
+	loan.DueAt = time.Now().AddDate(0, 0, policy.LoanPeriodDays)
+	loan.OverdueAt = nil
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.loanRepo.WithTx(tx).UpdateLoan(loan); err != nil {
+			return fmt.Errorf("failed to renew loan: %w", err)
+		}
+		return recordEvent(s.outboxRepo, tx, events.LoanRenewed, loan)
+	})
+
+	if err != nil {
+		return nil, err
 	}
 
 	return loan, nil
 }
 
+func (s *loanServiceImpl) ExplainLoanPolicy(loanID uuid.UUID) (*model.PolicyExplanation, error) {
+	loan, err := s.loanRepo.GetLoanByID(loanID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan for policy explanation: %w", err)
+	}
+
+	if loan == nil {
+		return nil, fmt.Errorf("loan with ID %s not found", loanID.String())
+	}
+
+	if loan.AnonymizedAt != nil {
+		return nil, fmt.Errorf("loan with ID %s is anonymized", loanID.String())
+	}
+
+	user, err := s.userRepo.GetUserByID(loan.UserID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for policy explanation: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with ID %s of loan %s not found", loan.UserID.String(), loanID.String())
+	}
+
+	category, err := s.policy.categoryFor(user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.bookRepo.GetBookByID(loan.BookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book for policy explanation: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("book with ID %s of loan %s not found", loan.BookID.String(), loanID.String())
+	}
+
+	branchCode, err := s.policy.branchCode(loan.CheckoutBranchID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	current, candidates, err := s.policy.resolve(category, book.ItemType, branchCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := s.appliedPolicy(loan)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PolicyExplanation{
+		LoanID:         loan.ID,
+		PatronCategory: category.Code,
+		ItemType:       book.ItemType,
+		BranchCode:     branchCode,
+		Applied:        applied,
+		Current:        current,
+		Candidates:     candidates,
+	}, nil
+}
+
+// FlagOverdueLoans marks the active loans past their due date and emits a
+// loan.overdue event for each, once per due date.
+func (s *loanServiceImpl) FlagOverdueLoans() (int, error) {
+	now := time.Now()
+	loans, err := s.loanRepo.GetActiveLoansDueBetween(time.Time{}, now)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get overdue loans: %w", err)
+	}
+
+	flagged := 0
+
+	for i := range loans {
+		loan := &loans[i]
+		if loan.OverdueAt != nil {
+			continue
+		}
+
+		loan.OverdueAt = &now
+		err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+			if err := s.loanRepo.WithTx(tx).UpdateLoan(loan); err != nil {
+				return err
+			}
+			return recordEvent(s.outboxRepo, tx, events.LoanOverdue, loan)
+		})
+
+		if err != nil {
+			log.Printf("WARNING: failed to flag loan %s as overdue: %v", loan.ID.String(), err)
+			continue
+		}
+		flagged++
+	}
+
+	return flagged, nil
+}
+
 func (s *loanServiceImpl) DeleteLoan(id uuid.UUID) error {
 	err := s.loanRepo.DeleteLoan(id)
 
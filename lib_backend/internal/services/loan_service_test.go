@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateLoanEnforcesLimitUnderConcurrency(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+
+	const attempts = 10
+	books := make([]*model.Book, attempts)
+	for i := range books {
+		books[i] = f.store.addBook("978000000000"+string(rune('0'+i)), true)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]error, attempts)
+
+	for i := range books {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i] = f.loans.CreateLoan(&model.Loan{UserID: user.ID, BookID: books[i].ID})
+		}(i)
+	}
+	wg.Wait()
+
+	created, limited := 0, 0
+	for _, err := range results {
+		var limitErr *PatronLimitError
+		switch {
+		case err == nil:
+			created++
+		case errors.As(err, &limitErr):
+			limited++
+			if limitErr.Max != 2 {
+				t.Errorf("limit error reports max %d, want 2", limitErr.Max)
+			}
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if created != 2 || limited != attempts-2 {
+		t.Fatalf("created %d loans and limited %d, want 2 and %d", created, limited, attempts-2)
+	}
+
+	if f.store.locks != attempts {
+		t.Errorf("user was locked %d times, want once per checkout (%d)", f.store.locks, attempts)
+	}
+}
+
+func TestCreateLoanRejectsUnavailableBook(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	book := f.store.addBook("9780000000001", false)
+
+	_, err := f.loans.CreateLoan(&model.Loan{UserID: user.ID, BookID: book.ID})
+
+	if err == nil || err.Error() != "book with ID "+book.ID.String()+" is not available for loan" {
+		t.Fatalf("CreateLoan() error = %v, want book not available", err)
+	}
+
+	if len(f.store.loans) != 0 {
+		t.Fatalf("%d loans created, want none", len(f.store.loans))
+	}
+}
+
+func TestCreateLoanLendsCopyOnceUnderConcurrency(t *testing.T) {
+	f := newCirculationFixture()
+	book := f.store.addBook("9780000000001", true)
+
+	const desks = 8
+	users := make([]*model.User, desks)
+	for i := range users {
+		users[i] = f.store.addUser("student")
+	}
+
+	var wg sync.WaitGroup
+	results := make([]error, desks)
+
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i] = f.loans.CreateLoan(&model.Loan{UserID: users[i].ID, BookID: book.ID})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range results {
+		switch {
+		case err == nil:
+			created++
+		case err.Error() != "book with ID "+book.ID.String()+" is not available for loan":
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if created != 1 || len(f.store.loans) != 1 {
+		t.Fatalf("created %d loans (%d stored), want one", created, len(f.store.loans))
+	}
+}
+
+func TestCreateLoanRejectsErasedUser(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	erasedAt := time.Now()
+	user.ErasedAt = &erasedAt
+	book := f.store.addBook("9780000000001", true)
+
+	_, err := f.loans.CreateLoan(&model.Loan{UserID: user.ID, BookID: book.ID})
+
+	if err == nil || err.Error() != "user with ID "+user.ID.String()+" is erased" {
+		t.Fatalf("CreateLoan() error = %v, want erased user rejection", err)
+	}
+
+	if len(f.store.loans) != 0 {
+		t.Fatalf("%d loans created, want none", len(f.store.loans))
+	}
+}
+
+func TestCreateLoanRejectsUserErasedWhileWaitingForLock(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	book := f.store.addBook("9780000000001", true)
+	f.store.eraseOnLock()
+
+	_, err := f.loans.CreateLoan(&model.Loan{UserID: user.ID, BookID: book.ID})
+
+	if err == nil || err.Error() != "user with ID "+user.ID.String()+" is erased" {
+		t.Fatalf("CreateLoan() error = %v, want erased user rejection", err)
+	}
+
+	if len(f.store.loans) != 0 || !f.store.books[book.ID].Available {
+		t.Fatalf("%d loans created, want none and the book still available", len(f.store.loans))
+	}
+}
+
+func TestCheckoutRejectsErasedPatron(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	erasedAt := time.Now()
+	user.ErasedAt = &erasedAt
+	book := f.store.addBook("9780000000001", true)
+
+	_, err := f.circulation.Checkout(user.Registration, []string{book.Barcode}, "")
+
+	if err == nil || err.Error() != "patron with barcode "+user.Registration+" is erased" {
+		t.Fatalf("Checkout() error = %v, want erased patron rejection", err)
+	}
+
+	if len(f.store.loans) != 0 {
+		t.Fatalf("%d loans created, want none", len(f.store.loans))
+	}
+}
+
+func TestPlaceHoldEnforcesLimitUnderConcurrency(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+
+	const attempts = 8
+	var wg sync.WaitGroup
+	results := make([]error, attempts)
+
+	for i := 0; i < attempts; i++ {
+		book := f.store.addBook("97800000001"+string(rune('0'+i))+"0", false)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, results[i] = f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: book.ID})
+		}(i)
+	}
+	wg.Wait()
+
+	placed := 0
+	for _, err := range results {
+		var limitErr *PatronLimitError
+		if err == nil {
+			placed++
+		} else if !errors.As(err, &limitErr) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if placed != 2 {
+		t.Fatalf("placed %d holds, want the category limit of 2", placed)
+	}
+}
+
+func TestReturnBookChargesFineAndRoutesInOneTransaction(t *testing.T) {
+	f := newCirculationFixture()
+	borrower := f.store.addUser("student")
+	waiting := f.store.addUser("student")
+	book := f.store.addBook("9788535914849", false)
+	other := f.store.addBook("9788535914849", false)
+
+	ruleID := uuid.New()
+	f.rules.rules = []model.CirculationRule{{ID: ruleID, LoanPeriodDays: 14, FinePerDayCents: 50, HoldsAllowed: true}}
+
+	loan := &model.Loan{UserID: borrower.ID, BookID: book.ID, DueAt: time.Now().AddDate(0, 0, -3).Add(time.Hour), RuleID: &ruleID}
+	if err := (&fakeLoanRepo{store: f.store}).CreateLoan(loan); err != nil {
+		t.Fatalf("failed to seed loan: %v", err)
+	}
+
+	hold, err := f.holds.PlaceHold(&model.Hold{UserID: waiting.ID, BookID: other.ID})
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+	before := f.transactor.committed
+
+	if _, err := f.loans.ReturnBook(loan.ID, nil); err != nil {
+		t.Fatalf("ReturnBook() error = %v", err)
+	}
+
+	if committed := f.transactor.committed - before; committed != 1 {
+		t.Errorf("ReturnBook committed %d transactions, want 1", committed)
+	}
+
+	if len(f.store.fines) != 1 || f.store.fines[0].AmountCents != 150 {
+		t.Errorf("fines = %+v, want one fine of 3 days at 50 cents", f.store.fines)
+	}
+
+	if ready := f.store.holds[hold.ID]; ready.Status != model.HoldStatusReady || ready.BookID != book.ID {
+		t.Errorf("hold = %+v, want it ready on the returned copy", ready)
+	}
+
+	if f.store.books[book.ID].Available {
+		t.Error("returned copy is available although it was trapped for a hold")
+	}
+
+	types := f.store.eventTypes()
+	want := []string{events.LoanReturned, events.HoldReady, events.BookAvailability}
+	if len(types) < len(want) || !slices.Equal(types[len(types)-len(want):], want) {
+		t.Errorf("events = %v, want to end with %v", types, want)
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"lib_backend/internal/config"
+	"lib_backend/internal/metadata"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"log"
+	"strings"
+	"time"
+)
+
+type MetadataService interface {
+	LookupISBN(isbn string) (*model.BookMetadata, error)
+}
+
+type metadataServiceImpl struct {
+	cacheRepo repository.MetadataCacheRepository
+	provider  metadata.Provider
+	cfg       config.MetadataConfig
+}
+
+func NewMetadataService(cacheRepo repository.MetadataCacheRepository, provider metadata.Provider, cfg config.MetadataConfig) MetadataService {
+	return &metadataServiceImpl{cacheRepo: cacheRepo, provider: provider, cfg: cfg}
+}
+
+// normalizeISBN strips hyphens and spaces and checks the ISBN-10 or ISBN-13 check digit.
+func normalizeISBN(isbn string) (string, error) {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+	invalid := fmt.Errorf("ISBN %s is not valid", isbn)
+
+	switch len(normalized) {
+	case 10:
+		sum := 0
+		for i, r := range normalized {
+			var digit int
+			switch {
+			case r >= '0' && r <= '9':
+				digit = int(r - '0')
+			case r == 'X' && i == 9:
+				digit = 10
+			default:
+				return "", invalid
+			}
+			sum += digit * (10 - i)
+		}
+		if sum%11 != 0 {
+			return "", invalid
+		}
+	case 13:
+		sum := 0
+		for i, r := range normalized {
+			if r < '0' || r > '9' {
+				return "", invalid
+			}
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += int(r-'0') * weight
+		}
+		if sum%10 != 0 {
+			return "", invalid
+		}
+	default:
+		return "", invalid
+	}
+
+	return normalized, nil
+}
+
+// LookupISBN answers from the cache while it is fresh and asks the external
+// catalogs otherwise. If the catalogs fail, a stale cached match is still used.
+func (s *metadataServiceImpl) LookupISBN(isbn string) (*model.BookMetadata, error) {
+	normalized, err := normalizeISBN(isbn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.cacheRepo.GetEntry(normalized)
+
+	if err != nil {
+		log.Printf("WARNING: failed to read metadata cache for ISBN %s: %v", normalized, err)
+	}
+
+	if entry != nil {
+		ttl := s.cfg.NotFoundTTL
+		if entry.Found {
+			ttl = s.cfg.CacheTTL
+		}
+
+		if time.Since(entry.FetchedAt) < ttl {
+			if !entry.Found {
+				return nil, fmt.Errorf("no metadata found for ISBN %s", normalized)
+			}
+			return entry.Metadata, nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	bookMetadata, err := s.provider.LookupISBN(ctx, normalized)
+
+	if err != nil {
+		if entry != nil && entry.Found {
+			log.Printf("WARNING: metadata lookup for ISBN %s failed, using stale cache: %v", normalized, err)
+			return entry.Metadata, nil
+		}
+		return nil, fmt.Errorf("failed to look up ISBN %s: %w", normalized, err)
+	}
+
+	if err := s.cacheRepo.PutEntry(&model.MetadataCacheEntry{ISBN: normalized, Found: bookMetadata != nil, Metadata: bookMetadata}); err != nil {
+		log.Printf("WARNING: failed to cache metadata for ISBN %s: %v", normalized, err)
+	}
+
+	if bookMetadata == nil {
+		return nil, fmt.Errorf("no metadata found for ISBN %s", normalized)
+	}
+
+	return bookMetadata, nil
+}
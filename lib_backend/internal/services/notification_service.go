@@ -0,0 +1,150 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
+	"lib_backend/internal/repository"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationService interface {
+	GetNotificationsByUserID(userID uuid.UUID) ([]model.Notification, error)
+	SendDueSoonReminders() (int, error)
+	SendOverdueNotices() (int, error)
+	PurgeNotifications(before time.Time) (int64, error)
+}
+
+type notificationServiceImpl struct {
+	notificationRepo repository.NotificationRepository
+	userRepo         repository.UserRepository
+	loanRepo         repository.LoanRepository
+	bookRepo         repository.BookRepository
+	notifier         *notifications.Notifier
+	dueSoonDays      int
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository, userRepo repository.UserRepository, loanRepo repository.LoanRepository, bookRepo repository.BookRepository, notifier *notifications.Notifier, dueSoonDays int) NotificationService {
+	return &notificationServiceImpl{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		loanRepo:         loanRepo,
+		bookRepo:         bookRepo,
+		notifier:         notifier,
+		dueSoonDays:      dueSoonDays,
+	}
+}
+
+func (s *notificationServiceImpl) GetNotificationsByUserID(userID uuid.UUID) ([]model.Notification, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence for notifications: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with ID %s not found", userID.String())
+	}
+
+	notificationList, err := s.notificationRepo.GetNotificationsByUserID(userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications by user ID: %w", err)
+	}
+
+	return notificationList, nil
+}
+
+// SendDueSoonReminders reminds patrons of loans due within the configured
+// window. A loan is reminded once per due date, so renewed loans are reminded again.
+func (s *notificationServiceImpl) SendDueSoonReminders() (int, error) {
+	now := time.Now()
+	loans, err := s.loanRepo.GetActiveLoansDueBetween(now, now.AddDate(0, 0, s.dueSoonDays))
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get loans due soon: %w", err)
+	}
+
+	sent := 0
+
+	for i := range loans {
+		loan := &loans[i]
+		if s.sendLoanNotification(loan, model.NotificationDueSoon, loan.DueAt.AddDate(0, 0, -s.dueSoonDays), nil) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// SendOverdueNotices tells patrons about loans past their due date, once per due date.
+func (s *notificationServiceImpl) SendOverdueNotices() (int, error) {
+	now := time.Now()
+	loans, err := s.loanRepo.GetActiveLoansDueBetween(time.Time{}, now)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to get overdue loans: %w", err)
+	}
+
+	sent := 0
+
+	for i := range loans {
+		loan := &loans[i]
+		daysLate := int(math.Ceil(now.Sub(loan.DueAt).Hours() / 24))
+		if s.sendLoanNotification(loan, model.NotificationOverdue, loan.DueAt, map[string]any{"DaysLate": daysLate}) {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// sendLoanNotification sends a loan notification unless one of the same kind
+// was already sent since the given time. Failures are logged so one bad loan
+// does not stop the batch.
+func (s *notificationServiceImpl) sendLoanNotification(loan *model.Loan, kind string, since time.Time, data map[string]any) bool {
+	alreadySent, err := s.notificationRepo.HasNotification(kind, loan.ID, since)
+
+	if err != nil {
+		log.Printf("WARNING: failed to check %s notifications for loan %s: %v", kind, loan.ID.String(), err)
+		return false
+	}
+
+	if alreadySent {
+		return false
+	}
+
+	book, err := s.bookRepo.GetBookByID(loan.BookID)
+
+	if err != nil || book == nil {
+		log.Printf("WARNING: failed to get book %s for %s notification of loan %s: %v", loan.BookID.String(), kind, loan.ID.String(), err)
+		return false
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["Loan"] = loan
+	data["Book"] = book
+
+	if err := s.notifier.Notify(loan.UserID, kind, data, &loan.ID); err != nil {
+		log.Printf("WARNING: failed to send %s notification for loan %s: %v", kind, loan.ID.String(), err)
+		return false
+	}
+
+	return true
+}
+
+func (s *notificationServiceImpl) PurgeNotifications(before time.Time) (int64, error) {
+	purged, err := s.notificationRepo.PurgeNotificationsBefore(before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge notifications: %w", err)
+	}
+
+	return purged, nil
+}
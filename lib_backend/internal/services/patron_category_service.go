@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+)
+
+type PatronCategoryService interface {
+	GetCategoryByCode(code string) (*model.PatronCategory, error)
+	UpdateCategory(category *model.PatronCategory) (*model.PatronCategory, error)
+	GetAllCategories() ([]model.PatronCategory, error)
+}
+
+type patronCategoryServiceImpl struct {
+	categoryRepo repository.PatronCategoryRepository
+}
+
+func NewPatronCategoryService(categoryRepo repository.PatronCategoryRepository) PatronCategoryService {
+	return &patronCategoryServiceImpl{categoryRepo: categoryRepo}
+}
+
+func (s *patronCategoryServiceImpl) GetCategoryByCode(code string) (*model.PatronCategory, error) {
+	category, err := s.categoryRepo.GetCategoryByCode(code)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patron category: %w", err)
+	}
+
+	if category == nil {
+		return nil, fmt.Errorf("patron category %s not found", code)
+	}
+
+	return category, nil
+}
+
+func (s *patronCategoryServiceImpl) UpdateCategory(category *model.PatronCategory) (*model.PatronCategory, error) {
+	if category.MaxLoans < 0 || category.MaxRenewals < 0 || category.MaxHolds < 0 || category.LoanPeriodDays <= 0 {
+		return nil, fmt.Errorf("invalid limits for patron category %s", category.Code)
+	}
+
+	existing, err := s.categoryRepo.GetCategoryByCode(category.Code)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing patron category before update: %w", err)
+	}
+
+	if existing == nil {
+		return nil, fmt.Errorf("patron category %s not found for update", category.Code)
+	}
+
+	err = s.categoryRepo.UpdateCategory(category)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update patron category: %w", err)
+	}
+
+	return category, nil
+}
+
+func (s *patronCategoryServiceImpl) GetAllCategories() ([]model.PatronCategory, error) {
+	categories, err := s.categoryRepo.GetAllCategories()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patron categories: %w", err)
+	}
+
+	return categories, nil
+}
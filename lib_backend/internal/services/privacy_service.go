@@ -0,0 +1,209 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PrivacyService interface {
+	AnonymizeReturnedLoans() (*model.AnonymizationResult, error)
+	ExportUserData(userID uuid.UUID) (*model.UserDataExport, error)
+	EraseUser(userID uuid.UUID) (*model.ErasureResult, error)
+}
+
+type privacyServiceImpl struct {
+	userRepo         repository.UserRepository
+	loanRepo         repository.LoanRepository
+	holdRepo         repository.HoldRepository
+	fineRepo         repository.FineRepository
+	notificationRepo repository.NotificationRepository
+	webhookRepo      repository.WebhookRepository
+	transactor       repository.Transactor
+	outboxRepo       repository.OutboxRepository
+	cfg              config.PrivacyConfig
+}
+
+func NewPrivacyService(userRepo repository.UserRepository, loanRepo repository.LoanRepository, holdRepo repository.HoldRepository, fineRepo repository.FineRepository, notificationRepo repository.NotificationRepository, webhookRepo repository.WebhookRepository, transactor repository.Transactor, outboxRepo repository.OutboxRepository, cfg config.PrivacyConfig) PrivacyService {
+	return &privacyServiceImpl{
+		userRepo:         userRepo,
+		loanRepo:         loanRepo,
+		holdRepo:         holdRepo,
+		fineRepo:         fineRepo,
+		notificationRepo: notificationRepo,
+		webhookRepo:      webhookRepo,
+		transactor:       transactor,
+		outboxRepo:       outboxRepo,
+		cfg:              cfg,
+	}
+}
+
+// AnonymizeReturnedLoans drops the patron from the loans returned longer
+// than the retention period ago, along with the traces that would link them
+// back: the loan of their fines and the patron in older event payloads.
+func (s *privacyServiceImpl) AnonymizeReturnedLoans() (*model.AnonymizationResult, error) {
+	before := time.Now().AddDate(0, 0, -s.cfg.LoanRetentionDays)
+	result := &model.AnonymizationResult{}
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		var err error
+
+		if result.AnonymizedLoans, err = s.loanRepo.WithTx(tx).AnonymizeReturnedLoans(before); err != nil {
+			return err
+		}
+		if result.DetachedFines, err = s.fineRepo.WithTx(tx).DetachAnonymizedLoans(); err != nil {
+			return err
+		}
+		if result.RedactedEvents, err = s.outboxRepo.WithTx(tx).RedactEventsBefore(before); err != nil {
+			return err
+		}
+		result.RedactedDeliveries, err = s.webhookRepo.WithTx(tx).RedactDeliveriesBefore(before)
+		return err
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize returned loans: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *privacyServiceImpl) ExportUserData(userID uuid.UUID) (*model.UserDataExport, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user for data export: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with ID %s not found", userID.String())
+	}
+
+	export := &model.UserDataExport{ExportedAt: time.Now(), Profile: user}
+
+	if export.Loans, err = s.loanRepo.GetLoansByUserID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get loans for data export: %w", err)
+	}
+
+	if export.Holds, err = s.holdRepo.GetHoldsByUserID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get holds for data export: %w", err)
+	}
+
+	if export.Fines, err = s.fineRepo.GetFinesByUserID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get fines for data export: %w", err)
+	}
+
+	if export.Notifications, err = s.notificationRepo.GetNotificationsByUserID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get notifications for data export: %w", err)
+	}
+
+	if export.Events, err = s.outboxRepo.GetEventsByUserID(userID); err != nil {
+		return nil, fmt.Errorf("failed to get events for data export: %w", err)
+	}
+
+	return export, nil
+}
+
+// EraseUser removes the personal data of a patron while keeping the user row
+// and its anonymized loans, so statistics and paid fines stay intact. A
+// patron still holding books, holds or unpaid fines must settle them first.
+// The checks run under the user lock that checkouts and holds also take, so
+// none of them can commit between the checks and the erasure.
+func (s *privacyServiceImpl) EraseUser(userID uuid.UUID) (*model.ErasureResult, error) {
+	result := &model.ErasureResult{UserID: userID, ErasedAt: time.Now()}
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		userRepo := s.userRepo.WithTx(tx)
+		user, err := userRepo.GetUserByID(userID)
+
+		if err != nil {
+			return fmt.Errorf("failed to get user for erasure: %w", err)
+		}
+
+		if user == nil {
+			return fmt.Errorf("user with ID %s not found", userID.String())
+		}
+
+		if err := userRepo.LockUser(userID); err != nil {
+			return err
+		}
+
+		// read again under the lock, as a concurrent erasure may have committed
+		if user, err = userRepo.GetUserByID(userID); err != nil {
+			return fmt.Errorf("failed to get user for erasure: %w", err)
+		}
+
+		if user.ErasedAt != nil {
+			return fmt.Errorf("user with ID %s is already erased", userID.String())
+		}
+
+		if err := s.checkErasable(tx, userID); err != nil {
+			return err
+		}
+
+		if result.AnonymizedLoans, err = s.loanRepo.WithTx(tx).AnonymizeLoansByUserID(userID); err != nil {
+			return err
+		}
+		if _, err = s.fineRepo.WithTx(tx).DetachAnonymizedLoans(); err != nil {
+			return err
+		}
+		if result.DeletedHolds, err = s.holdRepo.WithTx(tx).DeleteHoldsByUserID(userID); err != nil {
+			return err
+		}
+		if result.DeletedNotifications, err = s.notificationRepo.WithTx(tx).DeleteNotificationsByUserID(userID); err != nil {
+			return err
+		}
+		if result.RedactedEvents, err = s.outboxRepo.WithTx(tx).RedactUserEvents(userID); err != nil {
+			return err
+		}
+		if _, err = s.webhookRepo.WithTx(tx).RedactUserDeliveries(userID); err != nil {
+			return err
+		}
+		if err := userRepo.EraseUser(userID, result.ErasedAt); err != nil {
+			return err
+		}
+		return recordEvent(s.outboxRepo, tx, events.UserErased, map[string]any{"id": userID})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *privacyServiceImpl) checkErasable(tx *sql.Tx, userID uuid.UUID) error {
+	activeLoans, err := s.loanRepo.WithTx(tx).CountActiveLoansByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to count active loans for erasure: %w", err)
+	}
+	if activeLoans > 0 {
+		return fmt.Errorf("user with ID %s has active loans", userID.String())
+	}
+
+	activeHolds, err := s.holdRepo.WithTx(tx).CountActiveHoldsByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to count active holds for erasure: %w", err)
+	}
+	if activeHolds > 0 {
+		return fmt.Errorf("user with ID %s has active holds", userID.String())
+	}
+
+	fines, err := s.fineRepo.WithTx(tx).GetFinesByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to get fines for erasure: %w", err)
+	}
+	for _, fine := range fines {
+		if !fine.Paid {
+			return fmt.Errorf("user with ID %s has unpaid fines", userID.String())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestEraseUserChecksLoansUnderUserLock(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	book := f.store.addBook("9780000000001", false)
+	// a checkout commits while the erasure waits for the user lock
+	f.store.onLock = func(id uuid.UUID) {
+		f.store.mu.Lock()
+		defer f.store.mu.Unlock()
+		loan := &model.Loan{ID: uuid.New(), UserID: id, BookID: book.ID}
+		f.store.loans[loan.ID] = loan
+	}
+
+	privacy := NewPrivacyService(&fakeUserRepo{store: f.store}, &fakeLoanRepo{store: f.store}, f.router.holdRepo, &fakeFineRepo{store: f.store}, nil, nil, f.transactor, f.router.outboxRepo, config.PrivacyConfig{})
+	_, err := privacy.EraseUser(user.ID)
+
+	if err == nil || err.Error() != "user with ID "+user.ID.String()+" has active loans" {
+		t.Fatalf("EraseUser() error = %v, want the loan taken before the lock to block erasure", err)
+	}
+
+	if f.store.locks != 1 || f.store.users[user.ID].ErasedAt != nil {
+		t.Fatalf("user locked %d times and erased at %v, want one lock and no erasure", f.store.locks, f.store.users[user.ID].ErasedAt)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"lib_backend/internal/config"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type RecommendationService interface {
+	RefreshSimilarities() (int, error)
+	GetRelatedBooks(bookID uuid.UUID, limit int) ([]model.Recommendation, error)
+	GetRecommendations(userID uuid.UUID, limit int) ([]model.Recommendation, error)
+}
+
+type recommendationServiceImpl struct {
+	recommendationRepo repository.RecommendationRepository
+	bookRepo           repository.BookRepository
+	userRepo           repository.UserRepository
+	contributorRepo    repository.ContributorRepository
+	transactor         repository.Transactor
+	cfg                config.RecommendationConfig
+}
+
+func NewRecommendationService(recommendationRepo repository.RecommendationRepository, bookRepo repository.BookRepository, userRepo repository.UserRepository, contributorRepo repository.ContributorRepository, transactor repository.Transactor, cfg config.RecommendationConfig) RecommendationService {
+	return &recommendationServiceImpl{recommendationRepo: recommendationRepo, bookRepo: bookRepo, userRepo: userRepo, contributorRepo: contributorRepo, transactor: transactor, cfg: cfg}
+}
+
+// RefreshSimilarities recomputes the related books from the whole loan
+// history. Readers keep seeing the previous results until it commits.
+func (s *recommendationServiceImpl) RefreshSimilarities() (int, error) {
+	var pairs int
+
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		var err error
+		pairs, err = s.recommendationRepo.WithTx(tx).RefreshSimilarities(s.cfg.MinSupport, s.cfg.MaxRelated)
+		return err
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	return pairs, nil
+}
+
+func (s *recommendationServiceImpl) GetRelatedBooks(bookID uuid.UUID, limit int) ([]model.Recommendation, error) {
+	book, err := s.bookRepo.GetBookByID(bookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book by ID: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("book with ID %s not found", bookID.String())
+	}
+
+	recommendations, err := s.recommendationRepo.GetRelatedBooks(bookID, s.cfg.MinSupport, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get related books: %w", err)
+	}
+
+	if err := s.attachContributors(recommendations); err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
+
+func (s *recommendationServiceImpl) GetRecommendations(userID uuid.UUID, limit int) ([]model.Recommendation, error) {
+	user, err := s.userRepo.GetUserByID(userID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by ID: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with ID %s not found", userID.String())
+	}
+
+	recommendations, err := s.recommendationRepo.GetRecommendations(userID, s.cfg.MinSupport, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recommendations for user: %w", err)
+	}
+
+	if err := s.attachContributors(recommendations); err != nil {
+		return nil, err
+	}
+
+	return recommendations, nil
+}
+
+func (s *recommendationServiceImpl) attachContributors(recommendations []model.Recommendation) error {
+	ids := make([]uuid.UUID, 0, len(recommendations))
+
+	for _, recommendation := range recommendations {
+		ids = append(ids, recommendation.Book.ID)
+	}
+
+	contributors, err := s.contributorRepo.GetBookContributors(ids)
+
+	if err != nil {
+		return fmt.Errorf("failed to get contributors of recommended books: %w", err)
+	}
+
+	for i := range recommendations {
+		recommendations[i].Book.Contributors = contributors[recommendations[i].Book.ID]
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/config"
+	"strconv"
+)
+
+// formatRegistration builds prefix + zero-padded sequence + Luhn check digit.
+func formatRegistration(cfg config.RegistrationConfig, sequence int64) string {
+	digits := fmt.Sprintf("%0*d", cfg.SequenceDigits, sequence)
+	return cfg.Prefix + digits + strconv.Itoa(luhnCheckDigit(digits))
+}
+
+func luhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return (10 - sum%10) % 10
+}
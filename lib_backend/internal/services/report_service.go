@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"time"
+)
+
+type ReportService interface {
+	GetLoanVolume(filter model.ReportFilter) ([]model.LoanVolume, error)
+	GetTopBooks(filter model.ReportFilter) ([]model.TopBook, error)
+	GetActivePatrons(filter model.ReportFilter) ([]model.ActivePatrons, error)
+	GetLoanDuration(filter model.ReportFilter) (*model.LoanDuration, error)
+	GetOverdueRate(filter model.ReportFilter) (*model.OverdueRate, error)
+	GetCollectionTurnover(filter model.ReportFilter) (*model.CollectionTurnover, error)
+	GetWeedingCandidates(filter model.WeedingFilter) ([]model.WeedingCandidate, error)
+}
+
+type reportServiceImpl struct {
+	reportRepo repository.ReportRepository
+}
+
+func NewReportService(reportRepo repository.ReportRepository) ReportService {
+	return &reportServiceImpl{reportRepo: reportRepo}
+}
+
+// defaultReportDays is the period covered when a report has no start date.
+const defaultReportDays = 30
+
+// maxReportPeriods bounds the rows of a loan volume report, so a daily
+// report over decades cannot be requested by accident.
+const maxReportPeriods = 1000
+
+var reportIntervals = map[string]time.Duration{
+	model.ReportIntervalDay:   24 * time.Hour,
+	model.ReportIntervalWeek:  7 * 24 * time.Hour,
+	model.ReportIntervalMonth: 28 * 24 * time.Hour,
+}
+
+// checkReportFilter fills in the default period, which ends now, and
+// rejects ranges that are empty or reversed.
+func checkReportFilter(filter *model.ReportFilter) error {
+	if filter.To.IsZero() {
+		filter.To = time.Now()
+	}
+
+	if filter.From.IsZero() {
+		filter.From = filter.To.AddDate(0, 0, -defaultReportDays)
+	}
+
+	if !filter.From.Before(filter.To) {
+		return fmt.Errorf("report start must be before its end")
+	}
+
+	return nil
+}
+
+func (s *reportServiceImpl) GetLoanVolume(filter model.ReportFilter) ([]model.LoanVolume, error) {
+	if filter.Interval == "" {
+		filter.Interval = model.ReportIntervalDay
+	}
+
+	step, ok := reportIntervals[filter.Interval]
+
+	if !ok {
+		return nil, fmt.Errorf("report interval %s is not valid", filter.Interval)
+	}
+
+	if err := checkReportFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	if filter.To.Sub(filter.From) > step*maxReportPeriods {
+		return nil, fmt.Errorf("report period is too long for interval %s", filter.Interval)
+	}
+
+	volume, err := s.reportRepo.GetLoanVolume(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan volume report: %w", err)
+	}
+
+	return volume, nil
+}
+
+func (s *reportServiceImpl) GetTopBooks(filter model.ReportFilter) ([]model.TopBook, error) {
+	if err := checkReportFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	books, err := s.reportRepo.GetTopBooks(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get most borrowed books report: %w", err)
+	}
+
+	return books, nil
+}
+
+func (s *reportServiceImpl) GetActivePatrons(filter model.ReportFilter) ([]model.ActivePatrons, error) {
+	if err := checkReportFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	patrons, err := s.reportRepo.GetActivePatrons(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active patrons report: %w", err)
+	}
+
+	return patrons, nil
+}
+
+func (s *reportServiceImpl) GetLoanDuration(filter model.ReportFilter) (*model.LoanDuration, error) {
+	if err := checkReportFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	duration, err := s.reportRepo.GetLoanDuration(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan duration report: %w", err)
+	}
+
+	return duration, nil
+}
+
+func (s *reportServiceImpl) GetOverdueRate(filter model.ReportFilter) (*model.OverdueRate, error) {
+	if err := checkReportFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	rate, err := s.reportRepo.GetOverdueRate(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue rate report: %w", err)
+	}
+
+	return rate, nil
+}
+
+func (s *reportServiceImpl) GetCollectionTurnover(filter model.ReportFilter) (*model.CollectionTurnover, error) {
+	if err := checkReportFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	turnover, err := s.reportRepo.GetCollectionTurnover(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection turnover report: %w", err)
+	}
+
+	return turnover, nil
+}
+
+func (s *reportServiceImpl) GetWeedingCandidates(filter model.WeedingFilter) ([]model.WeedingCandidate, error) {
+	if filter.Years != nil && *filter.Years <= 0 {
+		return nil, fmt.Errorf("weeding period of %d years is not valid", *filter.Years)
+	}
+
+	candidates, err := s.reportRepo.GetWeedingCandidates(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weeding report: %w", err)
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type SeriesService interface {
+	CreateSeries(series *model.Series) (*model.Series, error)
+	GetSeriesByID(id uuid.UUID) (*model.Series, error)
+	SearchSeries(query string, limit int) ([]model.Series, error)
+	UpdateSeries(series *model.Series) (*model.Series, error)
+	DeleteSeries(id uuid.UUID) error
+	SetVolume(seriesID uuid.UUID, bookID uuid.UUID, volumeNumber *int) (*model.Series, error)
+	RemoveVolume(seriesID uuid.UUID, bookID uuid.UUID) error
+}
+
+type seriesServiceImpl struct {
+	seriesRepo      repository.SeriesRepository
+	bookRepo        repository.BookRepository
+	contributorRepo repository.ContributorRepository
+	transactor      repository.Transactor
+	outboxRepo      repository.OutboxRepository
+}
+
+func NewSeriesService(seriesRepo repository.SeriesRepository, bookRepo repository.BookRepository, contributorRepo repository.ContributorRepository, transactor repository.Transactor, outboxRepo repository.OutboxRepository) SeriesService {
+	return &seriesServiceImpl{seriesRepo: seriesRepo, bookRepo: bookRepo, contributorRepo: contributorRepo, transactor: transactor, outboxRepo: outboxRepo}
+}
+
+func (s *seriesServiceImpl) CreateSeries(series *model.Series) (*model.Series, error) {
+	series.Title = strings.TrimSpace(series.Title)
+	series.Description = strings.TrimSpace(series.Description)
+
+	if series.Title == "" {
+		return nil, fmt.Errorf("series title is required")
+	}
+
+	if err := s.seriesRepo.CreateSeries(series); err != nil {
+		return nil, fmt.Errorf("failed to create series: %w", err)
+	}
+
+	return series, nil
+}
+
+// GetSeriesByID returns a series with its volumes in reading order, each
+// with its availability per branch.
+func (s *seriesServiceImpl) GetSeriesByID(id uuid.UUID) (*model.Series, error) {
+	series, err := s.seriesRepo.GetSeriesByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series by ID: %w", err)
+	}
+
+	if series == nil {
+		return nil, fmt.Errorf("series with ID %s not found", id.String())
+	}
+
+	volumes, err := s.seriesRepo.GetVolumes(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get volumes of series: %w", err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(volumes))
+
+	for i := range volumes {
+		availability, err := s.bookRepo.GetBranchAvailability(volumes[i].ID)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get branch availability of volume: %w", err)
+		}
+		volumes[i].Availability = availability
+		ids = append(ids, volumes[i].ID)
+	}
+
+	contributors, err := s.contributorRepo.GetBookContributors(ids)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contributors of volumes: %w", err)
+	}
+
+	for i := range volumes {
+		volumes[i].Contributors = contributors[volumes[i].ID]
+	}
+	series.Volumes = volumes
+
+	return series, nil
+}
+
+func (s *seriesServiceImpl) SearchSeries(query string, limit int) ([]model.Series, error) {
+	seriesList, err := s.seriesRepo.SearchSeries(strings.TrimSpace(query), limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search series: %w", err)
+	}
+
+	return seriesList, nil
+}
+
+func (s *seriesServiceImpl) UpdateSeries(series *model.Series) (*model.Series, error) {
+	existingSeries, err := s.seriesRepo.GetSeriesByID(series.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing series before update: %w", err)
+	}
+
+	if existingSeries == nil {
+		return nil, fmt.Errorf("series with ID %s not found", series.ID.String())
+	}
+
+	series.Title = strings.TrimSpace(series.Title)
+	series.Description = strings.TrimSpace(series.Description)
+
+	if series.Title == "" {
+		series.Title = existingSeries.Title
+	}
+
+	if err := s.seriesRepo.UpdateSeries(series); err != nil {
+		return nil, fmt.Errorf("failed to update series: %w", err)
+	}
+
+	series.VolumeCount = existingSeries.VolumeCount
+	series.CreatedAt = existingSeries.CreatedAt
+
+	return series, nil
+}
+
+func (s *seriesServiceImpl) DeleteSeries(id uuid.UUID) error {
+	existingSeries, err := s.seriesRepo.GetSeriesByID(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to check for existing series before deletion: %w", err)
+	}
+
+	if existingSeries == nil {
+		return fmt.Errorf("series with ID %s not found", id.String())
+	}
+
+	if err := s.seriesRepo.DeleteSeries(id); err != nil {
+		return fmt.Errorf("failed to delete series: %w", err)
+	}
+
+	return nil
+}
+
+// SetVolume adds a book to a series, or moves it within or into it, with
+// the given volume number.
+func (s *seriesServiceImpl) SetVolume(seriesID uuid.UUID, bookID uuid.UUID, volumeNumber *int) (*model.Series, error) {
+	if volumeNumber != nil && *volumeNumber <= 0 {
+		return nil, fmt.Errorf("volume number %d is not valid", *volumeNumber)
+	}
+
+	series, err := s.seriesRepo.GetSeriesByID(seriesID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get series by ID: %w", err)
+	}
+
+	if series == nil {
+		return nil, fmt.Errorf("series with ID %s not found", seriesID.String())
+	}
+
+	book, err := s.bookRepo.GetBookByID(bookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book for series: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("book with ID %s not found", bookID.String())
+	}
+
+	book.SeriesID = &seriesID
+	book.VolumeNumber = volumeNumber
+
+	if err := s.saveBook(book); err != nil {
+		return nil, err
+	}
+
+	return s.GetSeriesByID(seriesID)
+}
+
+func (s *seriesServiceImpl) RemoveVolume(seriesID uuid.UUID, bookID uuid.UUID) error {
+	book, err := s.bookRepo.GetBookByID(bookID)
+
+	if err != nil {
+		return fmt.Errorf("failed to get book for series: %w", err)
+	}
+
+	if book == nil || book.SeriesID == nil || *book.SeriesID != seriesID {
+		return fmt.Errorf("book with ID %s is not in series %s", bookID.String(), seriesID.String())
+	}
+
+	book.SeriesID = nil
+	book.VolumeNumber = nil
+
+	return s.saveBook(book)
+}
+
+func (s *seriesServiceImpl) saveBook(book *model.Book) error {
+	return s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.bookRepo.WithTx(tx).UpdateBook(book); err != nil {
+			return fmt.Errorf("failed to update series of book: %w", err)
+		}
+		return recordEvent(s.outboxRepo, tx, events.BookUpdated, book)
+	})
+}
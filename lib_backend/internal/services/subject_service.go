@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type SubjectService interface {
+	CreateSubject(subject *model.Subject) (*model.Subject, error)
+	GetSubjectByID(id uuid.UUID) (*model.Subject, error)
+	SearchSubjects(filter model.SubjectFilter) ([]model.Subject, error)
+	UpdateSubject(subject *model.Subject) (*model.Subject, error)
+	DeleteSubject(id uuid.UUID) error
+	GetTags(query string, limit int) ([]model.TagCount, error)
+}
+
+type subjectServiceImpl struct {
+	subjectRepo repository.SubjectRepository
+}
+
+func NewSubjectService(subjectRepo repository.SubjectRepository) SubjectService {
+	return &subjectServiceImpl{subjectRepo: subjectRepo}
+}
+
+// checkParent rejects a parent that does not exist or that would turn the
+// hierarchy into a cycle.
+func (s *subjectServiceImpl) checkParent(subject *model.Subject) error {
+	if subject.ParentID == nil {
+		return nil
+	}
+
+	parent, err := s.subjectRepo.GetSubjectByID(*subject.ParentID)
+
+	if err != nil {
+		return fmt.Errorf("failed to check parent subject: %w", err)
+	}
+
+	if parent == nil {
+		return fmt.Errorf("parent subject with ID %s not found", subject.ParentID.String())
+	}
+
+	if subject.ID == uuid.Nil {
+		return nil
+	}
+
+	cycle, err := s.subjectRepo.IsDescendant(*subject.ParentID, subject.ID)
+
+	if err != nil {
+		return err
+	}
+
+	if cycle {
+		return fmt.Errorf("subject cannot be placed under itself or a narrower subject")
+	}
+
+	return nil
+}
+
+func (s *subjectServiceImpl) CreateSubject(subject *model.Subject) (*model.Subject, error) {
+	subject.Heading = strings.TrimSpace(whitespacePattern.ReplaceAllString(subject.Heading, " "))
+
+	if subject.Heading == "" {
+		return nil, fmt.Errorf("subject heading is required")
+	}
+
+	if err := s.checkParent(subject); err != nil {
+		return nil, err
+	}
+
+	err := s.subjectRepo.CreateSubject(subject)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("subject %s already exists", subject.Heading)
+		}
+
+		return nil, fmt.Errorf("failed to create subject: %w", err)
+	}
+
+	return subject, nil
+}
+
+// GetSubjectByID returns a subject with its broader subject and the
+// headings directly under it.
+func (s *subjectServiceImpl) GetSubjectByID(id uuid.UUID) (*model.Subject, error) {
+	subject, err := s.subjectRepo.GetSubjectByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subject by ID: %w", err)
+	}
+
+	if subject == nil {
+		return nil, fmt.Errorf("subject with ID %s not found", id.String())
+	}
+
+	if subject.ParentID != nil {
+		subject.Parent, err = s.subjectRepo.GetSubjectByID(*subject.ParentID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent subject: %w", err)
+		}
+	}
+
+	subject.Narrower, err = s.subjectRepo.SearchSubjects(model.SubjectFilter{ParentID: &id, Limit: maxNarrowerSubjects})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get narrower subjects: %w", err)
+	}
+
+	return subject, nil
+}
+
+// maxNarrowerSubjects bounds the children listed with a subject; larger
+// branches are paged through GET /api/subjects?parent_id=.
+const maxNarrowerSubjects = 200
+
+func (s *subjectServiceImpl) SearchSubjects(filter model.SubjectFilter) ([]model.Subject, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	subjects, err := s.subjectRepo.SearchSubjects(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search subjects: %w", err)
+	}
+
+	return subjects, nil
+}
+
+func (s *subjectServiceImpl) UpdateSubject(subject *model.Subject) (*model.Subject, error) {
+	existingSubject, err := s.subjectRepo.GetSubjectByID(subject.ID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing subject before update: %w", err)
+	}
+
+	if existingSubject == nil {
+		return nil, fmt.Errorf("subject with ID %s not found", subject.ID.String())
+	}
+
+	subject.Heading = strings.TrimSpace(whitespacePattern.ReplaceAllString(subject.Heading, " "))
+
+	if subject.Heading == "" {
+		subject.Heading = existingSubject.Heading
+	}
+
+	if err := s.checkParent(subject); err != nil {
+		return nil, err
+	}
+
+	err = s.subjectRepo.UpdateSubject(subject)
+
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, fmt.Errorf("subject %s already exists", subject.Heading)
+		}
+
+		return nil, fmt.Errorf("failed to update subject: %w", err)
+	}
+
+	subject.CreatedAt = existingSubject.CreatedAt
+	subject.BookCount = existingSubject.BookCount
+
+	return subject, nil
+}
+
+func (s *subjectServiceImpl) DeleteSubject(id uuid.UUID) error {
+	existingSubject, err := s.subjectRepo.GetSubjectByID(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to check for existing subject before deletion: %w", err)
+	}
+
+	if existingSubject == nil {
+		return fmt.Errorf("subject with ID %s not found", id.String())
+	}
+
+	err = s.subjectRepo.DeleteSubject(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete subject: %w", err)
+	}
+
+	return nil
+}
+
+func (s *subjectServiceImpl) GetTags(query string, limit int) ([]model.TagCount, error) {
+	tags, err := s.subjectRepo.GetTags(normalizeTag(query), limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// normalizeTag gives free tags one spelling: trimmed, lowercase and with
+// single spaces.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(whitespacePattern.ReplaceAllString(tag, " ")))
+}
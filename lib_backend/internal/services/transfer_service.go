@@ -0,0 +1,254 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
+	"lib_backend/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransferService interface {
+	RequestTransfer(bookID uuid.UUID, toBranchID uuid.UUID) (*model.Transfer, error)
+	GetTransferByID(id uuid.UUID) (*model.Transfer, error)
+	ShipTransfer(id uuid.UUID) (*model.Transfer, error)
+	ReceiveTransfer(id uuid.UUID) (*model.Transfer, error)
+	SearchTransfers(filter model.TransferFilter) ([]model.Transfer, error)
+	GetPullList(branchID uuid.UUID) ([]model.Transfer, error)
+}
+
+type transferServiceImpl struct {
+	transferRepo repository.TransferRepository
+	bookRepo     repository.BookRepository
+	holdRepo     repository.HoldRepository
+	branchRepo   repository.BranchRepository
+	router       *itemRouter
+	transactor   repository.Transactor
+	outboxRepo   repository.OutboxRepository
+}
+
+func NewTransferService(transferRepo repository.TransferRepository, bookRepo repository.BookRepository, holdRepo repository.HoldRepository, branchRepo repository.BranchRepository, notifier *notifications.Notifier, transactor repository.Transactor, outboxRepo repository.OutboxRepository) TransferService {
+	return &transferServiceImpl{
+		transferRepo: transferRepo,
+		bookRepo:     bookRepo,
+		holdRepo:     holdRepo,
+		branchRepo:   branchRepo,
+		transactor:   transactor,
+		outboxRepo:   outboxRepo,
+		router:       &itemRouter{bookRepo: bookRepo, holdRepo: holdRepo, transferRepo: transferRepo, branchRepo: branchRepo, notifier: notifier, transactor: transactor, outboxRepo: outboxRepo},
+	}
+}
+
+func (s *transferServiceImpl) RequestTransfer(bookID uuid.UUID, toBranchID uuid.UUID) (*model.Transfer, error) {
+	book, err := s.bookRepo.GetBookByID(bookID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check book existence for transfer: %w", err)
+	}
+
+	if book == nil {
+		return nil, fmt.Errorf("book with ID %s not found for transfer", bookID.String())
+	}
+
+	branch, err := s.branchRepo.GetBranchByID(toBranchID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check destination branch for transfer: %w", err)
+	}
+
+	if branch == nil {
+		return nil, fmt.Errorf("branch with ID %s not found for transfer", toBranchID.String())
+	}
+
+	if !book.Available {
+		return nil, fmt.Errorf("book with ID %s is not available for transfer", bookID.String())
+	}
+
+	if book.CurrentBranchID == nil {
+		return nil, fmt.Errorf("book with ID %s has no current branch", bookID.String())
+	}
+
+	if *book.CurrentBranchID == toBranchID {
+		return nil, fmt.Errorf("book with ID %s is already at branch %s", bookID.String(), toBranchID.String())
+	}
+
+	transfer := &model.Transfer{BookID: book.ID, FromBranchID: *book.CurrentBranchID, ToBranchID: toBranchID, Reason: model.TransferReasonManual}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.transferRepo.WithTx(tx).CreateTransfer(transfer); err != nil {
+			return fmt.Errorf("failed to create transfer: %w", err)
+		}
+
+		book.Available = false
+		if err := s.bookRepo.WithTx(tx).UpdateBook(book); err != nil {
+			return fmt.Errorf("failed to update book availability after transfer request: %w", err)
+		}
+
+		return recordAvailability(s.outboxRepo, tx, book)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (s *transferServiceImpl) GetTransferByID(id uuid.UUID) (*model.Transfer, error) {
+	transfer, err := s.transferRepo.GetTransferByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer by ID: %w", err)
+	}
+
+	if transfer == nil {
+		return nil, fmt.Errorf("transfer with ID %s not found", id.String())
+	}
+
+	return transfer, nil
+}
+
+func (s *transferServiceImpl) ShipTransfer(id uuid.UUID) (*model.Transfer, error) {
+	transfer, err := s.GetTransferByID(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.Status != model.TransferStatusRequested {
+		return nil, fmt.Errorf("transfer with ID %s cannot be shipped from status %s", id.String(), transfer.Status)
+	}
+
+	shippedAt := time.Now()
+	transfer.Status = model.TransferStatusInTransit
+	transfer.ShippedAt = &shippedAt
+	err = s.transferRepo.UpdateTransfer(transfer)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to ship transfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// ReceiveTransfer completes a transfer at its destination. Items that were
+// never marked as shipped are received directly.
+func (s *transferServiceImpl) ReceiveTransfer(id uuid.UUID) (*model.Transfer, error) {
+	transfer, err := s.GetTransferByID(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if transfer.Status == model.TransferStatusReceived {
+		return nil, fmt.Errorf("transfer with ID %s has already been received", id.String())
+	}
+
+	receivedAt := time.Now()
+	if transfer.ShippedAt == nil {
+		transfer.ShippedAt = &receivedAt
+	}
+	transfer.Status = model.TransferStatusReceived
+	transfer.ReceivedAt = &receivedAt
+
+	var book *model.Book
+	var result *routed
+
+	// receiving the copy, moving it and promoting its hold commit together
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.transferRepo.WithTx(tx).UpdateTransfer(transfer); err != nil {
+			return fmt.Errorf("failed to receive transfer: %w", err)
+		}
+
+		var err error
+		book, err = s.bookRepo.WithTx(tx).LockBook(transfer.BookID)
+
+		if err != nil {
+			return fmt.Errorf("failed to get book of received transfer: %w", err)
+		}
+
+		if book == nil {
+			return fmt.Errorf("book with ID %s of transfer %s not found", transfer.BookID.String(), id.String())
+		}
+
+		book.CurrentBranchID = &transfer.ToBranchID
+
+		if transfer.HoldID != nil {
+			hold, err := s.holdRepo.WithTx(tx).GetHoldByID(*transfer.HoldID)
+
+			if err != nil {
+				return fmt.Errorf("failed to get hold of received transfer: %w", err)
+			}
+
+			if hold != nil && hold.Status == model.HoldStatusWaiting {
+				book.Available = false
+				if err := s.bookRepo.WithTx(tx).UpdateBook(book); err != nil {
+					return fmt.Errorf("failed to update book after receiving transfer: %w", err)
+				}
+				if err := recordAvailability(s.outboxRepo, tx, book); err != nil {
+					return err
+				}
+				if err := s.router.markReady(tx, hold); err != nil {
+					return err
+				}
+				result = &routed{ready: hold}
+				return nil
+			}
+		}
+
+		// a manually transferred copy stays where it was sent instead of going home
+		result, err = s.router.routeTx(tx, book, transfer.Reason != model.TransferReasonManual)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.router.notifyReady(result.ready, book)
+
+	return transfer, nil
+}
+
+func (s *transferServiceImpl) SearchTransfers(filter model.TransferFilter) ([]model.Transfer, error) {
+	transfers, err := s.transferRepo.SearchTransfers(filter)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// GetPullList returns the items a branch still has to pull from its shelves and ship.
+func (s *transferServiceImpl) GetPullList(branchID uuid.UUID) ([]model.Transfer, error) {
+	branch, err := s.branchRepo.GetBranchByID(branchID)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch for pull list: %w", err)
+	}
+
+	if branch == nil {
+		return nil, fmt.Errorf("branch with ID %s not found", branchID.String())
+	}
+
+	transfers, err := s.transferRepo.SearchTransfers(model.TransferFilter{FromBranchID: &branchID, Status: model.TransferStatusRequested})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull list: %w", err)
+	}
+
+	for i := range transfers {
+		book, err := s.bookRepo.GetBookByID(transfers[i].BookID)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to get book for pull list: %w", err)
+		}
+		transfers[i].Book = book
+	}
+
+	return transfers, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"testing"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func newTransferService(f *circulationFixture) TransferService {
+	return NewTransferService(f.router.transferRepo, f.router.bookRepo, f.router.holdRepo, f.router.branchRepo, f.router.notifier, f.transactor, f.router.outboxRepo)
+}
+
+func TestRouteSkipsHoldWithCopyInTransit(t *testing.T) {
+	f := newCirculationFixture()
+	first, second := f.store.addUser("student"), f.store.addUser("student")
+	requested := f.store.addBook("9788535914849", false)
+	shipped := f.store.addBook("9788535914849", false)
+	shelved := f.store.addBook("9788535914849", false)
+
+	here, pickup := uuid.New(), uuid.New()
+	shipped.CurrentBranchID = &here
+	shelved.CurrentBranchID = &pickup
+
+	remote, err := f.holds.PlaceHold(&model.Hold{UserID: first.ID, BookID: requested.ID, PickupBranchID: &pickup})
+	if err != nil {
+		t.Fatalf("first PlaceHold() error = %v", err)
+	}
+	local, err := f.holds.PlaceHold(&model.Hold{UserID: second.ID, BookID: requested.ID})
+	if err != nil {
+		t.Fatalf("second PlaceHold() error = %v", err)
+	}
+
+	if transfer, err := f.router.route(shipped, true); err != nil || transfer == nil || *transfer.HoldID != remote.ID {
+		t.Fatalf("route() of first copy = %+v, %v, want it shipped to the oldest hold", transfer, err)
+	}
+
+	if _, err := f.router.route(shelved, true); err != nil {
+		t.Fatalf("route() of second copy error = %v", err)
+	}
+
+	if hold := f.store.holds[remote.ID]; hold.Status != model.HoldStatusWaiting || hold.BookID != shipped.ID {
+		t.Fatalf("hold in transit = %+v, want it still waiting on the shipped copy", hold)
+	}
+	if hold := f.store.holds[local.ID]; hold.Status != model.HoldStatusReady || hold.BookID != shelved.ID {
+		t.Fatalf("next hold = %+v, want it ready on the second copy", hold)
+	}
+	if len(f.store.transfers) != 1 {
+		t.Fatalf("%d transfers requested, want 1", len(f.store.transfers))
+	}
+}
+
+func TestReceiveTransferPromotesHoldInOneTransaction(t *testing.T) {
+	f := newCirculationFixture()
+	transfers := newTransferService(f)
+	user := f.store.addUser("student")
+	book := f.store.addBook("9788535914849", false)
+
+	here, pickup := uuid.New(), uuid.New()
+	book.CurrentBranchID = &here
+
+	hold, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: book.ID, PickupBranchID: &pickup})
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+	transfer, err := f.router.route(book, true)
+	if err != nil || transfer == nil {
+		t.Fatalf("route() = %+v, %v, want a transfer to the pickup branch", transfer, err)
+	}
+
+	committed := f.transactor.committed
+	received, err := transfers.ReceiveTransfer(transfer.ID)
+	if err != nil {
+		t.Fatalf("ReceiveTransfer() error = %v", err)
+	}
+
+	if f.transactor.committed != committed+1 {
+		t.Fatalf("receipt committed %d transactions, want one", f.transactor.committed-committed)
+	}
+	if received.Status != model.TransferStatusReceived || f.store.transfers[0].Status != model.TransferStatusReceived {
+		t.Fatalf("transfer status = %s, want received", f.store.transfers[0].Status)
+	}
+	if ready := f.store.holds[hold.ID]; ready.Status != model.HoldStatusReady || ready.ExpiresAt == nil {
+		t.Fatalf("hold = %+v, want it ready for pickup", ready)
+	}
+	if moved := f.store.books[book.ID]; moved.Available || !sameID(moved.CurrentBranchID, &pickup) {
+		t.Fatalf("book = %+v, want it unavailable at the pickup branch", moved)
+	}
+}
@@ -1,10 +1,17 @@
 package services
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
 	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
 	"lib_backend/internal/repository"
+	"log"
+	"slices"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -14,20 +21,69 @@ type UserService interface {
 	CreateUser(user *model.User) (*model.User, error)
 	GetUserByID(id uuid.UUID) (*model.User, error)
 	GetUserByEmail(email string) (*model.User, error)
+	GetUserByRegistration(registration string) (*model.User, error)
 	UpdateUser(user *model.User) (*model.User, error)
 	DeleteUser(id uuid.UUID) error
 	GetAllUsers() ([]model.User, error)
 }
 
 type userServiceImpl struct {
-	userRepo repository.UserRepository
+	userRepo           repository.UserRepository
+	categoryRepo       repository.PatronCategoryRepository
+	registrationConfig config.RegistrationConfig
+	notifier           *notifications.Notifier
+	transactor         repository.Transactor
+	outboxRepo         repository.OutboxRepository
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userServiceImpl{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, categoryRepo repository.PatronCategoryRepository, registrationConfig config.RegistrationConfig, notifier *notifications.Notifier, transactor repository.Transactor, outboxRepo repository.OutboxRepository) UserService {
+	return &userServiceImpl{userRepo: userRepo, categoryRepo: categoryRepo, registrationConfig: registrationConfig, notifier: notifier, transactor: transactor, outboxRepo: outboxRepo}
+}
+
+func checkLanguage(language string) error {
+	if !slices.Contains(model.SupportedLanguages, language) {
+		return fmt.Errorf("language %s is not supported", language)
+	}
+
+	return nil
+}
+
+func (s *userServiceImpl) checkCategory(code string) error {
+	category, err := s.categoryRepo.GetCategoryByCode(code)
+
+	if err != nil {
+		return fmt.Errorf("failed to check patron category: %w", err)
+	}
+
+	if category == nil {
+		return fmt.Errorf("patron category %s does not exist", code)
+	}
+
+	return nil
 }
 
 func (s *userServiceImpl) CreateUser(user *model.User) (*model.User, error) {
+	if user.Category == "" {
+		user.Category = model.DefaultPatronCategory
+	}
+
+	if err := s.checkCategory(user.Category); err != nil {
+		return nil, err
+	}
+
+	if user.Language == "" {
+		user.Language = model.DefaultLanguage
+	}
+
+	if err := checkLanguage(user.Language); err != nil {
+		return nil, err
+	}
+
+	if user.KeepLoanHistory == nil {
+		keepLoanHistory := false
+		user.KeepLoanHistory = &keepLoanHistory
+	}
+
 	existingUser, err := s.userRepo.GetUserByEmail(user.Email)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check for existing user by email: %w", err)
@@ -37,7 +93,22 @@ func (s *userServiceImpl) CreateUser(user *model.User) (*model.User, error) {
 		return nil, fmt.Errorf("user with email %s already exists", user.Email)
 	}
 
-	err = s.userRepo.CreateUser(user)
+	user.Registration = strings.TrimSpace(user.Registration)
+
+	if user.Registration == "" {
+		sequence, err := s.userRepo.NextRegistrationSequence()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate registration: %w", err)
+		}
+		user.Registration = formatRegistration(s.registrationConfig, sequence)
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.userRepo.WithTx(tx).CreateUser(user); err != nil {
+			return err
+		}
+		return recordEvent(s.outboxRepo, tx, events.UserCreated, user)
+	})
 
 	if err != nil {
 		// postgres error
@@ -83,6 +154,20 @@ func (s *userServiceImpl) GetUserByEmail(email string) (*model.User, error) {
 	return user, nil
 }
 
+func (s *userServiceImpl) GetUserByRegistration(registration string) (*model.User, error) {
+	user, err := s.userRepo.GetUserByRegistration(registration)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user by registration: %w", err)
+	}
+
+	if user == nil {
+		return nil, fmt.Errorf("user with registration %s not found", registration)
+	}
+
+	return user, nil
+}
+
 func (s *userServiceImpl) UpdateUser(user *model.User) (*model.User, error) {
 	existingUser, err := s.userRepo.GetUserByID(user.ID) // verifica se o user existe antes do update
 
@@ -94,23 +179,91 @@ func (s *userServiceImpl) UpdateUser(user *model.User) (*model.User, error) {
 		return nil, fmt.Errorf("user with ID %s not found for update", user.ID.String())
 	}
 
-	err = s.userRepo.UpdateUser(user)
+	if existingUser.ErasedAt != nil {
+		return nil, fmt.Errorf("user with ID %s is erased", user.ID.String())
+	}
+
+	if user.Registration == "" {
+		user.Registration = existingUser.Registration
+	}
+
+	if user.Category == "" {
+		user.Category = existingUser.Category
+	} else if err := s.checkCategory(user.Category); err != nil {
+		return nil, err
+	}
+
+	if user.Language == "" {
+		user.Language = existingUser.Language
+	} else if err := checkLanguage(user.Language); err != nil {
+		return nil, err
+	}
+
+	if user.KeepLoanHistory == nil {
+		user.KeepLoanHistory = existingUser.KeepLoanHistory
+	}
+
+	err = s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.userRepo.WithTx(tx).UpdateUser(user); err != nil {
+			return fmt.Errorf("failed to update user %w", err)
+		}
+		return recordEvent(s.outboxRepo, tx, events.UserUpdated, user)
+	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to update user %w", err)
+		return nil, err
+	}
+
+	if changes := changedFields(existingUser, user); len(changes) > 0 {
+		if err := s.notifier.Notify(user.ID, model.NotificationAccountChange, map[string]any{"Changes": changes}, &user.ID); err != nil {
+			log.Printf("WARNING: failed to send account change notification for user %s: %v", user.ID.String(), err)
+		}
 	}
 
 	return user, nil
 }
 
+func changedFields(before, after *model.User) []string {
+	changes := make([]string, 0)
+
+	if before.Name != after.Name {
+		changes = append(changes, "name")
+	}
+	if before.Email != after.Email {
+		changes = append(changes, "email")
+	}
+	if before.Registration != after.Registration {
+		changes = append(changes, "registration")
+	}
+	if before.Category != after.Category {
+		changes = append(changes, "category")
+	}
+	if before.Language != after.Language {
+		changes = append(changes, "language")
+	}
+	if *before.KeepLoanHistory != *after.KeepLoanHistory {
+		changes = append(changes, "keep_loan_history")
+	}
+
+	return changes
+}
+
+// DeleteUser removes a user that never borrowed a book. Loans and fines
+// restrict the deletion, so a patron with history has to be erased instead.
 func (s *userServiceImpl) DeleteUser(id uuid.UUID) error {
-	err := s.userRepo.DeleteUser(id)
+	err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+		if err := s.userRepo.WithTx(tx).DeleteUser(id); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return recordEvent(s.outboxRepo, tx, events.UserDeleted, map[string]any{"id": id})
+	})
 
-	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return fmt.Errorf("user with ID %s has loan history", id.String())
 	}
 
-	return nil
+	return err
 }
 
 func (s *userServiceImpl) GetAllUsers() ([]model.User, error) {
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// webhookMaxAttempts is how many times a delivery is tried before it is marked failed.
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second
+	webhookMaxBackoff  = 6 * time.Hour
+	webhookBatchSize   = 100
+	webhookTimeout     = 10 * time.Second
+)
+
+type WebhookService interface {
+	CreateSubscription(url string, eventTypes []string, secret string) (*model.WebhookSubscription, error)
+	GetSubscriptionByID(id uuid.UUID) (*model.WebhookSubscription, error)
+	GetAllSubscriptions() ([]model.WebhookSubscription, error)
+	UpdateSubscription(id uuid.UUID, url string, eventTypes []string, active bool) (*model.WebhookSubscription, error)
+	DeleteSubscription(id uuid.UUID) error
+	GetDeliveries(subscriptionID uuid.UUID, limit int) ([]model.WebhookDelivery, error)
+	ReplayDelivery(id uuid.UUID) (*model.WebhookDelivery, error)
+	Enqueue(ctx context.Context, event events.Event) error
+	DeliverDue(ctx context.Context) (int, int, error)
+	PurgeDeliveries(before time.Time) (int64, error)
+}
+
+type webhookServiceImpl struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository) WebhookService {
+	return &webhookServiceImpl{webhookRepo: webhookRepo, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func checkWebhook(rawURL string, eventTypes []string) error {
+	parsed, err := url.Parse(rawURL)
+
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("webhook URL %s is not a valid http or https URL", rawURL)
+	}
+
+	for _, eventType := range eventTypes {
+		if !events.IsKnownType(eventType) {
+			return fmt.Errorf("event type %s is not supported", eventType)
+		}
+	}
+
+	return nil
+}
+
+func (s *webhookServiceImpl) CreateSubscription(url string, eventTypes []string, secret string) (*model.WebhookSubscription, error) {
+	if err := checkWebhook(url, eventTypes); err != nil {
+		return nil, err
+	}
+
+	if secret == "" {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(key)
+	}
+
+	subscription := &model.WebhookSubscription{URL: url, EventTypes: eventTypes, Secret: secret, Active: true}
+	err := s.webhookRepo.CreateSubscription(subscription)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// GetSubscriptionByID returns the subscription without its secret.
+func (s *webhookServiceImpl) GetSubscriptionByID(id uuid.UUID) (*model.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.GetSubscriptionByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription by ID: %w", err)
+	}
+
+	if subscription == nil {
+		return nil, fmt.Errorf("webhook subscription with ID %s not found", id.String())
+	}
+
+	subscription.Secret = ""
+
+	return subscription, nil
+}
+
+func (s *webhookServiceImpl) GetAllSubscriptions() ([]model.WebhookSubscription, error) {
+	subscriptions, err := s.webhookRepo.GetAllSubscriptions()
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscriptions: %w", err)
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+func (s *webhookServiceImpl) UpdateSubscription(id uuid.UUID, url string, eventTypes []string, active bool) (*model.WebhookSubscription, error) {
+	subscription, err := s.webhookRepo.GetSubscriptionByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook subscription for update: %w", err)
+	}
+
+	if subscription == nil {
+		return nil, fmt.Errorf("webhook subscription with ID %s not found", id.String())
+	}
+
+	if err := checkWebhook(url, eventTypes); err != nil {
+		return nil, err
+	}
+
+	subscription.URL = url
+	subscription.EventTypes = eventTypes
+	subscription.Active = active
+	err = s.webhookRepo.UpdateSubscription(subscription)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	subscription.Secret = ""
+
+	return subscription, nil
+}
+
+func (s *webhookServiceImpl) DeleteSubscription(id uuid.UUID) error {
+	subscription, err := s.webhookRepo.GetSubscriptionByID(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to check webhook subscription existence for deletion: %w", err)
+	}
+
+	if subscription == nil {
+		return fmt.Errorf("webhook subscription with ID %s not found", id.String())
+	}
+
+	err = s.webhookRepo.DeleteSubscription(id)
+
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (s *webhookServiceImpl) GetDeliveries(subscriptionID uuid.UUID, limit int) ([]model.WebhookDelivery, error) {
+	if _, err := s.GetSubscriptionByID(subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.GetDeliveriesBySubscriptionID(subscriptionID, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// ReplayDelivery queues a fresh copy of a past delivery, keeping the original
+// event ID so receivers can recognise it.
+func (s *webhookServiceImpl) ReplayDelivery(id uuid.UUID) (*model.WebhookDelivery, error) {
+	original, err := s.webhookRepo.GetDeliveryByID(id)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery for replay: %w", err)
+	}
+
+	if original == nil {
+		return nil, fmt.Errorf("webhook delivery with ID %s not found", id.String())
+	}
+
+	delivery := &model.WebhookDelivery{SubscriptionID: original.SubscriptionID, EventID: original.EventID, EventType: original.EventType, Payload: original.Payload}
+	err = s.webhookRepo.CreateDelivery(delivery)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// Enqueue records a pending delivery of the event for every active
+// subscription listening to it. It is the webhook sink of the outbox
+// dispatcher, so an event seen again after a dispatch failure is skipped for
+// subscriptions that already have it queued.
+func (s *webhookServiceImpl) Enqueue(ctx context.Context, event events.Event) error {
+	subscriptions, err := s.webhookRepo.GetActiveSubscriptionsForEvent(event.Type)
+
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscriptions for event %s: %w", event.Type, err)
+	}
+
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		return fmt.Errorf("failed to encode event %s %s: %w", event.Type, event.ID.String(), err)
+	}
+
+	for _, subscription := range subscriptions {
+		queued, err := s.webhookRepo.HasDelivery(subscription.ID, event.ID)
+
+		if err != nil {
+			return fmt.Errorf("failed to check webhook deliveries of event %s: %w", event.ID.String(), err)
+		}
+
+		if queued {
+			continue
+		}
+
+		delivery := &model.WebhookDelivery{SubscriptionID: subscription.ID, EventID: event.ID, EventType: event.Type, Payload: payload}
+		if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+			return fmt.Errorf("failed to queue webhook delivery of event %s to %s: %w", event.ID.String(), subscription.URL, err)
+		}
+	}
+
+	return nil
+}
+
+// DeliverDue sends the pending deliveries whose next attempt is due and
+// returns how many succeeded and how many failed.
+func (s *webhookServiceImpl) DeliverDue(ctx context.Context) (int, int, error) {
+	deliveries, err := s.webhookRepo.GetDueDeliveries(time.Now(), webhookBatchSize)
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get due webhook deliveries: %w", err)
+	}
+
+	subscriptions := make(map[uuid.UUID]*model.WebhookSubscription)
+	succeeded, failed := 0, 0
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return succeeded, failed, ctx.Err()
+		}
+
+		delivery := &deliveries[i]
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+
+		if !ok {
+			subscription, err = s.webhookRepo.GetSubscriptionByID(delivery.SubscriptionID)
+			if err != nil {
+				return succeeded, failed, fmt.Errorf("failed to get webhook subscription for delivery: %w", err)
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		// the subscription may have been paused since the batch was read
+		if subscription == nil || !subscription.Active {
+			continue
+		}
+
+		statusCode, sendErr := s.send(ctx, subscription, delivery)
+		delivery.Attempts++
+		if statusCode != 0 {
+			delivery.LastStatusCode = &statusCode
+		}
+
+		if sendErr == nil {
+			deliveredAt := time.Now()
+			delivery.Status = model.WebhookDeliverySucceeded
+			delivery.DeliveredAt = &deliveredAt
+			delivery.LastError = nil
+			succeeded++
+		} else {
+			message := sendErr.Error()
+			delivery.LastError = &message
+			if delivery.Attempts >= webhookMaxAttempts {
+				delivery.Status = model.WebhookDeliveryFailed
+				failed++
+			} else {
+				delivery.NextAttemptAt = time.Now().Add(webhookBackoff(delivery.Attempts))
+			}
+		}
+
+		if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+			log.Printf("ERROR: failed to record webhook delivery %s: %v", delivery.ID.String(), err)
+		}
+	}
+
+	return succeeded, failed, nil
+}
+
+func (s *webhookServiceImpl) PurgeDeliveries(before time.Time) (int64, error) {
+	purged, err := s.webhookRepo.PurgeDeliveriesBefore(before)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+
+	return purged, nil
+}
+
+// webhookBackoff doubles the wait after every failed attempt, up to webhookMaxBackoff.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+// signWebhook returns the hex HMAC-SHA256 of "<timestamp>.<body>". Signing the
+// timestamp lets receivers reject replays of an old, validly signed request.
+func signWebhook(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// send posts the payload signed with the subscription secret. Receivers verify
+// X-Webhook-Signature against X-Webhook-Timestamp and the raw body.
+func (s *webhookServiceImpl) send(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) (int, error) {
+	timestamp := time.Now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "lib_backend-webhooks")
+	request.Header.Set("X-Webhook-ID", delivery.ID.String())
+	request.Header.Set("X-Webhook-Event", delivery.EventType)
+	request.Header.Set("X-Webhook-Event-ID", delivery.EventID.String())
+	request.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	request.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(subscription.Secret, timestamp, delivery.Payload))
+
+	response, err := s.client.Do(request)
+
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer func() {
+		if closeErr := response.Body.Close(); closeErr != nil {
+			log.Printf("ERROR: failed to close webhook response body: %v", closeErr)
+		}
+	}()
+
+	if _, err := io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10)); err != nil {
+		log.Printf("WARNING: failed to read webhook response body: %v", err)
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+
+	return response.StatusCode, nil
+}
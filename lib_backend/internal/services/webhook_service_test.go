@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"lib_backend/internal/model"
+	"lib_backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]*model.WebhookSubscription
+	deliveries    []model.WebhookDelivery
+	updated       []model.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) GetSubscriptionByID(id uuid.UUID) (*model.WebhookSubscription, error) {
+	return r.subscriptions[id], nil
+}
+
+func (r *fakeWebhookRepo) GetDueDeliveries(now time.Time, limit int) ([]model.WebhookDelivery, error) {
+	return append([]model.WebhookDelivery(nil), r.deliveries...), nil
+}
+
+func (r *fakeWebhookRepo) UpdateDelivery(delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updated = append(r.updated, *delivery)
+	return nil
+}
+
+func TestDeliverDueSignsTimestampAndBody(t *testing.T) {
+	payload := []byte(`{"type":"loan.created"}`)
+	requests := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	subscription := &model.WebhookSubscription{ID: uuid.New(), URL: server.URL, Secret: "s3cret", Active: true}
+	repo := &fakeWebhookRepo{
+		subscriptions: map[uuid.UUID]*model.WebhookSubscription{subscription.ID: subscription},
+		deliveries:    []model.WebhookDelivery{{ID: uuid.New(), SubscriptionID: subscription.ID, EventID: uuid.New(), EventType: "loan.created", Payload: payload}},
+	}
+
+	succeeded, failed, err := NewWebhookService(repo).DeliverDue(context.Background())
+	if err != nil || succeeded != 1 || failed != 0 {
+		t.Fatalf("DeliverDue() = %d, %d, %v, want one success", succeeded, failed, err)
+	}
+
+	request, body := <-requests, <-bodies
+	timestamp, err := strconv.ParseInt(request.Header.Get("X-Webhook-Timestamp"), 10, 64)
+	if err != nil {
+		t.Fatalf("X-Webhook-Timestamp = %q, want unix seconds", request.Header.Get("X-Webhook-Timestamp"))
+	}
+
+	if age := time.Since(time.Unix(timestamp, 0)); age < -time.Minute || age > time.Minute {
+		t.Errorf("timestamp is %s away from now", age)
+	}
+
+	if want := "sha256=" + signWebhook("s3cret", timestamp, body); request.Header.Get("X-Webhook-Signature") != want {
+		t.Errorf("X-Webhook-Signature = %q, want %q", request.Header.Get("X-Webhook-Signature"), want)
+	}
+
+	if signWebhook("s3cret", timestamp+1, body) == signWebhook("s3cret", timestamp, body) {
+		t.Error("signature does not depend on the timestamp")
+	}
+
+	if len(repo.updated) != 1 || repo.updated[0].Status != model.WebhookDeliverySucceeded {
+		t.Fatalf("recorded deliveries = %+v, want one succeeded", repo.updated)
+	}
+}
+
+func TestDeliverDueSkipsInactiveSubscription(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("webhook of an inactive subscription was sent")
+	}))
+	defer server.Close()
+
+	subscription := &model.WebhookSubscription{ID: uuid.New(), URL: server.URL, Secret: "s3cret", Active: false}
+	repo := &fakeWebhookRepo{
+		subscriptions: map[uuid.UUID]*model.WebhookSubscription{subscription.ID: subscription},
+		deliveries:    []model.WebhookDelivery{{ID: uuid.New(), SubscriptionID: subscription.ID, Payload: []byte(`{}`)}},
+	}
+
+	succeeded, failed, err := NewWebhookService(repo).DeliverDue(context.Background())
+	if err != nil || succeeded != 0 || failed != 0 {
+		t.Fatalf("DeliverDue() = %d, %d, %v, want nothing delivered", succeeded, failed, err)
+	}
+
+	if len(repo.updated) != 0 {
+		t.Fatalf("recorded deliveries = %+v, want the delivery left pending", repo.updated)
+	}
+}
+
+func TestDeliverDueBacksOffAfterFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	subscription := &model.WebhookSubscription{ID: uuid.New(), URL: server.URL, Secret: "s3cret", Active: true}
+	repo := &fakeWebhookRepo{
+		subscriptions: map[uuid.UUID]*model.WebhookSubscription{subscription.ID: subscription},
+		deliveries:    []model.WebhookDelivery{{ID: uuid.New(), SubscriptionID: subscription.ID, Payload: []byte(`{}`), Attempts: 1, Status: model.WebhookDeliveryPending}},
+	}
+
+	if _, _, err := NewWebhookService(repo).DeliverDue(context.Background()); err != nil {
+		t.Fatalf("DeliverDue() error = %v", err)
+	}
+
+	delivery := repo.updated[0]
+	if delivery.Status != model.WebhookDeliveryPending || delivery.Attempts != 2 || *delivery.LastStatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("delivery = %+v, want it pending after a second attempt", delivery)
+	}
+
+	if wait := time.Until(delivery.NextAttemptAt); wait < 50*time.Second || wait > time.Minute {
+		t.Errorf("next attempt in %s, want about one minute", wait)
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/notifications"
+	"lib_backend/internal/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WithdrawalService interface {
+	WithdrawBooks(bookIDs []uuid.UUID, reason string) (*model.WithdrawalResult, error)
+	ReinstateBooks(bookIDs []uuid.UUID) (*model.WithdrawalResult, error)
+}
+
+type withdrawalServiceImpl struct {
+	bookRepo     repository.BookRepository
+	loanRepo     repository.LoanRepository
+	holdRepo     repository.HoldRepository
+	transferRepo repository.TransferRepository
+	router       *itemRouter
+	transactor   repository.Transactor
+	outboxRepo   repository.OutboxRepository
+}
+
+func NewWithdrawalService(bookRepo repository.BookRepository, loanRepo repository.LoanRepository, holdRepo repository.HoldRepository, transferRepo repository.TransferRepository, branchRepo repository.BranchRepository, notifier *notifications.Notifier, transactor repository.Transactor, outboxRepo repository.OutboxRepository) WithdrawalService {
+	return &withdrawalServiceImpl{
+		bookRepo:     bookRepo,
+		loanRepo:     loanRepo,
+		holdRepo:     holdRepo,
+		transferRepo: transferRepo,
+		transactor:   transactor,
+		outboxRepo:   outboxRepo,
+		router:       &itemRouter{bookRepo: bookRepo, holdRepo: holdRepo, transferRepo: transferRepo, branchRepo: branchRepo, notifier: notifier, transactor: transactor, outboxRepo: outboxRepo},
+	}
+}
+
+// WithdrawBooks removes the books from the collection. Each book is handled
+// independently, so one still in circulation does not block the rest.
+func (s *withdrawalServiceImpl) WithdrawBooks(bookIDs []uuid.UUID, reason string) (*model.WithdrawalResult, error) {
+	reason = strings.TrimSpace(reason)
+
+	return s.applyToEach(bookIDs, func(tx *sql.Tx, book *model.Book) (*routed, error) {
+		if book.WithdrawnAt != nil {
+			return nil, fmt.Errorf("book with ID %s is already withdrawn", book.ID.String())
+		}
+
+		if err := s.checkOutOfCirculation(tx, book.ID); err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		book.WithdrawnAt = &now
+		book.WithdrawalReason = reason
+		book.Available = false
+
+		if err := s.save(tx, book); err != nil {
+			return nil, err
+		}
+		return nil, recordAvailability(s.outboxRepo, tx, book)
+	})
+}
+
+// ReinstateBooks puts withdrawn books back into circulation. A reinstated
+// copy is routed like a returned one: it serves the next hold on its title
+// or goes back to its home branch.
+func (s *withdrawalServiceImpl) ReinstateBooks(bookIDs []uuid.UUID) (*model.WithdrawalResult, error) {
+	return s.applyToEach(bookIDs, func(tx *sql.Tx, book *model.Book) (*routed, error) {
+		if book.WithdrawnAt == nil {
+			return nil, fmt.Errorf("book with ID %s is not withdrawn", book.ID.String())
+		}
+
+		book.WithdrawnAt = nil
+		book.WithdrawalReason = ""
+
+		result, err := s.router.routeTx(tx, book, true)
+		if err != nil {
+			return nil, err
+		}
+		return result, s.save(tx, book)
+	})
+}
+
+// checkOutOfCirculation rejects books that a patron or another branch is
+// still waiting on.
+func (s *withdrawalServiceImpl) checkOutOfCirculation(tx *sql.Tx, bookID uuid.UUID) error {
+	loan, err := s.loanRepo.WithTx(tx).GetActiveLoanByBookID(bookID)
+	if err != nil {
+		return fmt.Errorf("failed to check active loan: %w", err)
+	}
+	if loan != nil {
+		return fmt.Errorf("book with ID %s is on loan", bookID.String())
+	}
+
+	holdRepo := s.holdRepo.WithTx(tx)
+	hold, err := holdRepo.GetReadyHold(bookID)
+	if err != nil {
+		return fmt.Errorf("failed to check ready hold: %w", err)
+	}
+	if hold == nil {
+		hold, err = holdRepo.GetNextWaitingHold(bookID)
+		if err != nil {
+			return fmt.Errorf("failed to check waiting holds: %w", err)
+		}
+	}
+	if hold != nil {
+		return fmt.Errorf("book with ID %s has pending holds", bookID.String())
+	}
+
+	transfer, err := s.transferRepo.WithTx(tx).GetOpenTransferByBookID(bookID)
+	if err != nil {
+		return fmt.Errorf("failed to check open transfer: %w", err)
+	}
+	if transfer != nil {
+		return fmt.Errorf("book with ID %s has an open transfer", bookID.String())
+	}
+
+	return nil
+}
+
+// applyToEach changes each book in its own transaction, with the copy locked
+// so a checkout cannot slip in between the checks and the save.
+func (s *withdrawalServiceImpl) applyToEach(bookIDs []uuid.UUID, change func(tx *sql.Tx, book *model.Book) (*routed, error)) (*model.WithdrawalResult, error) {
+	result := &model.WithdrawalResult{Items: make([]model.WithdrawalItemResult, 0, len(bookIDs))}
+
+	for _, bookID := range bookIDs {
+		item := model.WithdrawalItemResult{BookID: bookID}
+		var book *model.Book
+		var changed *routed
+
+		err := s.transactor.WithinTx(func(tx *sql.Tx) error {
+			var err error
+			book, err = s.bookRepo.WithTx(tx).LockBook(bookID)
+
+			switch {
+			case err != nil:
+				return fmt.Errorf("failed to get book: %v", err)
+			case book == nil:
+				return fmt.Errorf("book with ID %s not found", bookID.String())
+			}
+
+			changed, err = change(tx, book)
+			return err
+		})
+
+		if err != nil {
+			item.Error = err.Error()
+		} else {
+			if changed != nil {
+				s.router.notifyReady(changed.ready, book)
+			}
+			item.Book = book
+			result.Changed++
+		}
+
+		result.Items = append(result.Items, item)
+	}
+
+	return result, nil
+}
+
+// save stores the withdrawal state of a book inside tx.
+func (s *withdrawalServiceImpl) save(tx *sql.Tx, book *model.Book) error {
+	if err := s.bookRepo.WithTx(tx).SetWithdrawn(book); err != nil {
+		return err
+	}
+	return recordEvent(s.outboxRepo, tx, events.BookUpdated, book)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"lib_backend/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func newWithdrawalService(f *circulationFixture) WithdrawalService {
+	r := f.router
+	return NewWithdrawalService(r.bookRepo, &fakeLoanRepo{store: f.store}, r.holdRepo, r.transferRepo, r.branchRepo, r.notifier, f.transactor, r.outboxRepo)
+}
+
+func TestReinstateBooksRoutesCopyToWaitingHold(t *testing.T) {
+	f := newCirculationFixture()
+	user := f.store.addUser("student")
+	onLoan := f.store.addBook("9788535914849", false)
+	withdrawn := f.store.addBook("9788535914849", false)
+	withdrawnAt := time.Now()
+	withdrawn.WithdrawnAt, withdrawn.WithdrawalReason = &withdrawnAt, "damaged"
+
+	hold, err := f.holds.PlaceHold(&model.Hold{UserID: user.ID, BookID: onLoan.ID})
+	if err != nil {
+		t.Fatalf("PlaceHold() error = %v", err)
+	}
+
+	committed := f.transactor.committed
+	result, err := newWithdrawalService(f).ReinstateBooks([]uuid.UUID{withdrawn.ID})
+	if err != nil || result.Changed != 1 {
+		t.Fatalf("ReinstateBooks() = %+v, %v, want the copy reinstated", result, err)
+	}
+
+	if f.transactor.committed != committed+1 {
+		t.Fatalf("reinstatement committed %d transactions, want one", f.transactor.committed-committed)
+	}
+	if book := f.store.books[withdrawn.ID]; book.WithdrawnAt != nil || book.Available {
+		t.Fatalf("book = %+v, want it reinstated and kept for the hold", book)
+	}
+	if ready := f.store.holds[hold.ID]; ready.Status != model.HoldStatusReady || ready.BookID != withdrawn.ID {
+		t.Fatalf("hold = %+v, want it ready on the reinstated copy", ready)
+	}
+}
+
+func TestReinstateBooksSendsCopyHome(t *testing.T) {
+	f := newCirculationFixture()
+	book := f.store.addBook("9788535914849", false)
+	here, home := uuid.New(), uuid.New()
+	withdrawnAt := time.Now()
+	book.CurrentBranchID, book.HomeBranchID, book.WithdrawnAt = &here, &home, &withdrawnAt
+
+	result, err := newWithdrawalService(f).ReinstateBooks([]uuid.UUID{book.ID, book.ID})
+	if err != nil {
+		t.Fatalf("ReinstateBooks() error = %v", err)
+	}
+
+	if result.Changed != 1 || result.Items[1].Error != "book with ID "+book.ID.String()+" is not withdrawn" {
+		t.Fatalf("result = %+v, want the second reinstatement refused", result)
+	}
+	if len(f.store.transfers) != 1 || f.store.transfers[0].ToBranchID != home || f.store.transfers[0].Reason != model.TransferReasonReturn {
+		t.Fatalf("transfers = %+v, want the copy sent to its home branch", f.store.transfers)
+	}
+	if f.store.books[book.ID].Available {
+		t.Fatal("copy in transit home is available")
+	}
+}
@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"lib_backend/internal/config"
+)
+
+// fakeS3 implements the path-style S3 calls S3Store makes: bucket HEAD/PUT and
+// object PUT/HEAD/GET/DELETE. Signatures are not checked.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]bool
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{buckets: make(map[string]bool), objects: make(map[string]fakeObject)}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+
+	if key == "" {
+		switch r.Method {
+		case http.MethodHead:
+			if !f.buckets[bucket] {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case http.MethodPut:
+			f.buckets[bucket] = true
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+		return
+	}
+
+	if !f.buckets[bucket] {
+		s3Error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	name := bucket + "/" + key
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		f.objects[name] = fakeObject{data: data, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC()}
+		w.Header().Set("ETag", `"fake"`)
+	case http.MethodHead, http.MethodGet:
+		object, ok := f.objects[name]
+		if !ok {
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusNotFound)
+			} else {
+				s3Error(w, http.StatusNotFound, "NoSuchKey")
+			}
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", object.modTime.Format(http.TimeFormat))
+		w.Header().Set("ETag", `"fake"`)
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+// readS3Body returns the object data, decoding the aws-chunked framing
+// minio-go uses for streaming signatures over plain http.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func testS3RoundTrip(t *testing.T, store *S3Store) {
+	t.Helper()
+	ctx := context.Background()
+	key := "covers/test/" + strconv.FormatInt(time.Now().UnixNano(), 10) + ".jpg"
+	data := bytes.Repeat([]byte("cover"), 1000)
+
+	if err := store.Put(ctx, key, data, "image/jpeg"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	blob, err := store.Get(ctx, key)
+	if err != nil || blob == nil {
+		t.Fatalf("Get() = %v, %v, want the stored blob", blob, err)
+	}
+
+	got, err := io.ReadAll(blob.Body)
+	blob.Body.Close()
+
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("Get() body = %d bytes, %v, want the %d bytes stored", len(got), err, len(data))
+	}
+
+	if blob.ContentType != "image/jpeg" || blob.Size != int64(len(data)) {
+		t.Errorf("Get() = %s, %d bytes, want image/jpeg and %d bytes", blob.ContentType, blob.Size, len(data))
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	blob, err = store.Get(ctx, key)
+	if blob != nil || err != nil {
+		t.Fatalf("Get() after Delete() = %v, %v, want nil and no error", blob, err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() of a missing key error = %v", err)
+	}
+}
+
+func TestS3StoreAgainstFake(t *testing.T) {
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	endpoint, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+
+	store, err := NewS3Store(config.StorageConfig{S3Endpoint: endpoint.Host, S3Bucket: "covers", S3AccessKey: "key", S3SecretKey: "secret", S3Region: "us-east-1"})
+	if err != nil {
+		t.Fatalf("NewS3Store() error = %v", err)
+	}
+
+	if !fake.buckets["covers"] {
+		t.Fatal("NewS3Store() did not create the missing bucket")
+	}
+
+	testS3RoundTrip(t, store)
+}
+
+// TestS3StoreAgainstMinIO runs the same round trip against a real server,
+// e.g. the minio service of docker-compose (S3_TEST_ENDPOINT=localhost:9000).
+func TestS3StoreAgainstMinIO(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	store, err := NewS3Store(config.StorageConfig{
+		S3Endpoint:  endpoint,
+		S3Bucket:    "lib-backend-test",
+		S3AccessKey: envOr("S3_TEST_ACCESS_KEY", "minioadmin"),
+		S3SecretKey: envOr("S3_TEST_SECRET_KEY", "minioadmin"),
+		S3Region:    "us-east-1",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Store() error = %v", err)
+	}
+
+	testS3RoundTrip(t, store)
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
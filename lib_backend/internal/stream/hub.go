@@ -0,0 +1,255 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"lib_backend/internal/config"
+	"lib_backend/internal/events"
+	"lib_backend/internal/model"
+	"lib_backend/internal/outbox"
+	"lib_backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// clientBuffer is how many events a slow client may fall behind before it
+	// is disconnected; it can then resume with Last-Event-ID.
+	clientBuffer = 256
+	pageSize     = 500
+)
+
+// DefaultTypes are streamed when a client does not ask for specific types.
+var DefaultTypes = []string{events.BookAvailability, events.LoanCreated, events.LoanReturned, events.HoldReady}
+
+// Filter selects the events a client receives. A nil BookID or UserID
+// matches every event.
+type Filter struct {
+	Types  []string
+	BookID *uuid.UUID
+	UserID *uuid.UUID
+}
+
+// refs holds the IDs the filter can match on, as found in event payloads.
+type refs struct {
+	ID     uuid.UUID  `json:"id"`
+	BookID *uuid.UUID `json:"book_id"`
+	UserID *uuid.UUID `json:"user_id"`
+}
+
+func (f Filter) Matches(event events.Event) bool {
+	if !slices.Contains(f.Types, event.Type) {
+		return false
+	}
+
+	if f.BookID == nil && f.UserID == nil {
+		return true
+	}
+
+	data, ok := event.Data.(json.RawMessage)
+	if !ok {
+		return false
+	}
+
+	var r refs
+	if err := json.Unmarshal(data, &r); err != nil {
+		return false
+	}
+
+	bookID, userID := r.BookID, r.UserID
+	switch event.Type {
+	case events.BookCreated, events.BookUpdated, events.BookDeleted:
+		bookID = &r.ID
+	case events.UserCreated, events.UserUpdated, events.UserDeleted:
+		userID = &r.ID
+	}
+
+	if f.BookID != nil && (bookID == nil || *bookID != *f.BookID) {
+		return false
+	}
+
+	return f.UserID == nil || (userID != nil && *userID == *f.UserID)
+}
+
+type Client struct {
+	// Events is closed when the client falls too far behind or the hub stops.
+	Events <-chan events.Event
+
+	events chan events.Event
+	filter Filter
+	// from is the last seq committed when the client subscribed; older
+	// events are only available through Replay.
+	from int64
+}
+
+// Hub tails the outbox and fans committed events out to the connected
+// clients. Every replica runs its own hub, so clients see all events no
+// matter which replica dispatches the outbox.
+type Hub struct {
+	outboxRepo repository.OutboxRepository
+	cfg        config.StreamConfig
+
+	mu      sync.Mutex
+	clients map[*Client]struct{}
+	lastSeq int64
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewHub(outboxRepo repository.OutboxRepository, cfg config.StreamConfig) *Hub {
+	return &Hub{outboxRepo: outboxRepo, cfg: cfg, clients: make(map[*Client]struct{})}
+}
+
+func (h *Hub) Start() error {
+	lastSeq, err := h.outboxRepo.GetLastSeq()
+
+	if err != nil {
+		return err
+	}
+
+	h.lastSeq = lastSeq
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		h.loop(ctx)
+	}()
+
+	return nil
+}
+
+func (h *Hub) Stop() {
+	if h.cancel != nil {
+		h.cancel()
+	}
+	h.wg.Wait()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		h.drop(client)
+	}
+}
+
+func (h *Hub) loop(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for {
+			batch, err := h.outboxRepo.GetSince(h.lastSeq, pageSize)
+
+			if err != nil {
+				log.Printf("ERROR: event stream failed to read the outbox: %v", err)
+				break
+			}
+
+			h.broadcast(batch)
+
+			if len(batch) < pageSize {
+				break
+			}
+		}
+	}
+}
+
+func (h *Hub) broadcast(batch []model.OutboxEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, outboxEvent := range batch {
+		event := outbox.ToEvent(outboxEvent)
+
+		for client := range h.clients {
+			if !client.filter.Matches(event) {
+				continue
+			}
+
+			select {
+			case client.events <- event:
+			default:
+				log.Printf("WARNING: event stream client fell behind at seq %d, disconnecting it", event.Seq)
+				h.drop(client)
+			}
+		}
+
+		h.lastSeq = outboxEvent.Seq
+	}
+}
+
+// Subscribe registers a client for events committed from now on.
+func (h *Hub) Subscribe(filter Filter) *Client {
+	ch := make(chan events.Event, clientBuffer)
+	client := &Client{Events: ch, events: ch, filter: filter}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client.from = h.lastSeq
+	h.clients[client] = struct{}{}
+
+	return client
+}
+
+func (h *Hub) Unsubscribe(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[client]; ok {
+		h.drop(client)
+	}
+}
+
+// drop must be called with the lock held.
+func (h *Hub) drop(client *Client) {
+	delete(h.clients, client)
+	close(client.events)
+}
+
+// Replay sends the client's events committed after afterSeq and before it
+// subscribed, so a reconnecting client resumes without gaps.
+func (h *Hub) Replay(client *Client, afterSeq int64, send func(event events.Event) error) error {
+	for afterSeq < client.from {
+		batch, err := h.outboxRepo.GetSince(afterSeq, pageSize)
+
+		if err != nil {
+			return err
+		}
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, outboxEvent := range batch {
+			if outboxEvent.Seq > client.from {
+				return nil
+			}
+
+			event := outbox.ToEvent(outboxEvent)
+			if client.filter.Matches(event) {
+				if err := send(event); err != nil {
+					return err
+				}
+			}
+			afterSeq = outboxEvent.Seq
+		}
+	}
+
+	return nil
+}
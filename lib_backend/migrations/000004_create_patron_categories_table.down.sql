@@ -0,0 +1,5 @@
+ALTER TABLE loans DROP COLUMN IF EXISTS due_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS category;
+
+DROP TABLE IF EXISTS patron_categories;
@@ -0,0 +1,27 @@
+CREATE TABLE patron_categories (
+    code VARCHAR(20) PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    max_loans INTEGER NOT NULL CHECK (max_loans >= 0),
+    loan_period_days INTEGER NOT NULL CHECK (loan_period_days > 0),
+    max_renewals INTEGER NOT NULL DEFAULT 0 CHECK (max_renewals >= 0),
+    max_holds INTEGER NOT NULL DEFAULT 0 CHECK (max_holds >= 0)
+);
+
+INSERT INTO patron_categories (code, name, max_loans, loan_period_days, max_renewals, max_holds) VALUES
+    ('student', 'Aluno', 5, 14, 2, 3),
+    ('faculty', 'Docente', 20, 60, 5, 10),
+    ('staff', 'Funcionário', 10, 30, 3, 5),
+    ('visitor', 'Visitante', 2, 7, 0, 1);
+
+ALTER TABLE users
+    ADD COLUMN category VARCHAR(20) NOT NULL DEFAULT 'student' REFERENCES patron_categories(code);
+
+ALTER TABLE loans ADD COLUMN due_at TIMESTAMP WITH TIME ZONE;
+
+UPDATE loans l
+SET due_at = l.loaned_at + make_interval(days => pc.loan_period_days)
+FROM users u
+JOIN patron_categories pc ON pc.code = u.category
+WHERE u.id = l.user_id;
+
+ALTER TABLE loans ALTER COLUMN due_at SET NOT NULL;
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS fines;
+
+DROP TABLE IF EXISTS holds;
+
+ALTER TABLE loans
+    DROP COLUMN IF EXISTS renewals,
+    DROP COLUMN IF EXISTS circulation_rule_id;
+
+DROP TABLE IF EXISTS circulation_rules;
+
+ALTER TABLE books DROP COLUMN IF EXISTS item_type;
@@ -0,0 +1,44 @@
+ALTER TABLE books ADD COLUMN item_type VARCHAR(20) NOT NULL DEFAULT 'book';
+
+CREATE TABLE circulation_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    patron_category VARCHAR(20) REFERENCES patron_categories(code) ON DELETE CASCADE,
+    item_type VARCHAR(20),
+    branch_code VARCHAR(20),
+    loan_period_days INTEGER NOT NULL CHECK (loan_period_days > 0),
+    max_renewals INTEGER NOT NULL DEFAULT 0 CHECK (max_renewals >= 0),
+    fine_per_day_cents INTEGER NOT NULL DEFAULT 0 CHECK (fine_per_day_cents >= 0),
+    holds_allowed BOOLEAN NOT NULL DEFAULT TRUE,
+    non_circulating BOOLEAN NOT NULL DEFAULT FALSE,
+    UNIQUE NULLS NOT DISTINCT (patron_category, item_type, branch_code)
+);
+
+INSERT INTO circulation_rules (patron_category, item_type, branch_code, loan_period_days, max_renewals, fine_per_day_cents, holds_allowed, non_circulating) VALUES
+    (NULL, 'reference', NULL, 1, 0, 0, FALSE, TRUE);
+
+ALTER TABLE loans
+    ADD COLUMN circulation_rule_id UUID REFERENCES circulation_rules(id) ON DELETE SET NULL,
+    ADD COLUMN renewals INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'waiting',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ready_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_holds_book_status ON holds (book_id, status, created_at);
+
+CREATE TABLE fines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    loan_id UUID NOT NULL REFERENCES loans(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    days_late INTEGER NOT NULL,
+    amount_cents INTEGER NOT NULL,
+    paid BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    paid_at TIMESTAMP WITH TIME ZONE
+);
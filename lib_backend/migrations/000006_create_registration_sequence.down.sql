@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS registration_seq;
@@ -0,0 +1 @@
+CREATE SEQUENCE registration_seq START WITH 1;
@@ -0,0 +1,7 @@
+ALTER TABLE books ADD COLUMN barcode VARCHAR(32);
+
+UPDATE books SET barcode = isbn;
+
+ALTER TABLE books
+    ALTER COLUMN barcode SET NOT NULL,
+    ADD CONSTRAINT books_barcode_key UNIQUE (barcode);
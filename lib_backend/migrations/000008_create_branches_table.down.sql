@@ -0,0 +1,11 @@
+ALTER TABLE loans
+    DROP COLUMN IF EXISTS return_branch_id,
+    DROP COLUMN IF EXISTS checkout_branch_id;
+
+DROP INDEX IF EXISTS idx_books_current_branch;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS current_branch_id,
+    DROP COLUMN IF EXISTS home_branch_id;
+
+DROP TABLE IF EXISTS branches;
@@ -0,0 +1,16 @@
+CREATE TABLE branches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code VARCHAR(20) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    address VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+ALTER TABLE books
+    ADD COLUMN home_branch_id UUID REFERENCES branches(id) ON DELETE SET NULL,
+    ADD COLUMN current_branch_id UUID REFERENCES branches(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_books_current_branch ON books (current_branch_id);
+
+ALTER TABLE loans
+    ADD COLUMN checkout_branch_id UUID REFERENCES branches(id) ON DELETE SET NULL,
+    ADD COLUMN return_branch_id UUID REFERENCES branches(id) ON DELETE SET NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transfers;
+
+ALTER TABLE holds DROP COLUMN IF EXISTS pickup_branch_id;
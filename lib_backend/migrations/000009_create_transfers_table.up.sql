@@ -0,0 +1,18 @@
+ALTER TABLE holds ADD COLUMN pickup_branch_id UUID REFERENCES branches(id) ON DELETE SET NULL;
+
+CREATE TABLE transfers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    from_branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    to_branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    hold_id UUID REFERENCES holds(id) ON DELETE SET NULL,
+    reason VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested',
+    requested_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    shipped_at TIMESTAMP WITH TIME ZONE,
+    received_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_transfers_from_status ON transfers (from_branch_id, status);
+CREATE INDEX idx_transfers_to_status ON transfers (to_branch_id, status);
+CREATE UNIQUE INDEX idx_transfers_open_book ON transfers (book_id) WHERE status IN ('requested', 'in_transit');
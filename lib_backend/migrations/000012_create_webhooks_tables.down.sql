@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+
+ALTER TABLE loans DROP COLUMN IF EXISTS overdue_at;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_event;
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,7 @@
+CREATE TABLE book_metadata_cache (
+    isbn VARCHAR(13) PRIMARY KEY,
+    found BOOLEAN NOT NULL,
+    source VARCHAR(50),
+    metadata JSONB,
+    fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE books ADD COLUMN cover_updated_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,22 @@
+DROP TABLE IF EXISTS book_subjects;
+DROP TABLE IF EXISTS subjects;
+DROP FUNCTION IF EXISTS subject_heading_key(TEXT);
+
+DROP INDEX IF EXISTS idx_books_call_number;
+DROP INDEX IF EXISTS idx_books_language;
+DROP INDEX IF EXISTS idx_books_publication_year;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS call_number,
+    DROP COLUMN IF EXISTS description,
+    DROP COLUMN IF EXISTS page_count,
+    DROP COLUMN IF EXISTS language,
+    DROP COLUMN IF EXISTS edition,
+    DROP COLUMN IF EXISTS publication_year,
+    DROP COLUMN IF EXISTS publisher,
+    DROP COLUMN IF EXISTS subtitle;
+
+-- longer values are cut to fit the original columns
+ALTER TABLE books
+    ALTER COLUMN title TYPE VARCHAR(50) USING LEFT(title, 50),
+    ALTER COLUMN author TYPE VARCHAR(50) USING LEFT(author, 50);
@@ -0,0 +1,39 @@
+ALTER TABLE books
+    ALTER COLUMN title TYPE VARCHAR(500),
+    ALTER COLUMN author TYPE VARCHAR(500),
+    ADD COLUMN subtitle VARCHAR(500),
+    ADD COLUMN publisher VARCHAR(255),
+    ADD COLUMN publication_year INTEGER CHECK (publication_year > 0),
+    ADD COLUMN edition VARCHAR(100),
+    ADD COLUMN language VARCHAR(35),
+    ADD COLUMN page_count INTEGER CHECK (page_count > 0),
+    ADD COLUMN description TEXT,
+    ADD COLUMN call_number VARCHAR(100);
+
+CREATE INDEX idx_books_publication_year ON books (publication_year);
+CREATE INDEX idx_books_language ON books (language);
+CREATE INDEX idx_books_call_number ON books (call_number);
+
+-- subject_heading_key folds case, accents and punctuation, so "Ficção" and
+-- "ficcao" are the same subject
+CREATE FUNCTION subject_heading_key(heading TEXT) RETURNS TEXT AS $$
+    SELECT trim(regexp_replace(regexp_replace(lower(translate(heading,
+        'ÁÀÂÃÄÅáàâãäåÉÈÊËéèêëÍÌÎÏíìîïÓÒÔÕÖØóòôõöøÚÙÛÜúùûüÇçÑñÝýÿ',
+        'AAAAAAaaaaaaEEEEeeeeIIIIiiiiOOOOOOooooooUUUUuuuuCcNnYyy')),
+        '[^a-z0-9]+', ' ', 'g'), '\s+', ' ', 'g'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE TABLE subjects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    heading VARCHAR(255) NOT NULL,
+    heading_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE book_subjects (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    subject_id UUID NOT NULL REFERENCES subjects(id) ON DELETE CASCADE,
+    PRIMARY KEY (book_id, subject_id)
+);
+
+CREATE INDEX idx_book_subjects_subject_id ON book_subjects (subject_id);
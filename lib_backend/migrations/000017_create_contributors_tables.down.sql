@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS book_contributors;
+DROP TABLE IF EXISTS contributors;
+DROP FUNCTION IF EXISTS contributor_name_key(TEXT);
@@ -0,0 +1,92 @@
+-- contributor_name_key folds case, accents and punctuation, so "J.R.R. Tolkien"
+-- and "J. R. R. Tolkien" are the same contributor
+CREATE FUNCTION contributor_name_key(name TEXT) RETURNS TEXT AS $$
+    SELECT trim(regexp_replace(regexp_replace(lower(translate(name,
+        'ÁÀÂÃÄÅáàâãäåÉÈÊËéèêëÍÌÎÏíìîïÓÒÔÕÖØóòôõöøÚÙÛÜúùûüÇçÑñÝýÿ',
+        'AAAAAAaaaaaaEEEEeeeeIIIIiiiiOOOOOOooooooUUUUuuuuCcNnYyy')),
+        '[^a-z0-9]+', ' ', 'g'), '\s+', ' ', 'g'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE TABLE contributors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    sort_name VARCHAR(255) NOT NULL,
+    name_key VARCHAR(255) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE book_contributors (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    contributor_id UUID NOT NULL REFERENCES contributors(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('author', 'editor', 'translator', 'illustrator')),
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, contributor_id, role)
+);
+
+CREATE INDEX idx_book_contributors_contributor_id ON book_contributors (contributor_id);
+
+-- Split the existing author strings the same way the API does: names are
+-- separated by ";" or "&", and by commas unless the comma inverts a single
+-- name ("Assis, Machado de").
+DO $$
+DECLARE
+    book RECORD;
+    part TEXT;
+    comma_parts TEXT[];
+    names TEXT[];
+    author_name TEXT;
+    display_name TEXT;
+    sorted_name TEXT;
+    contributor UUID;
+    pos INTEGER;
+BEGIN
+    FOR book IN SELECT id, author FROM books WHERE trim(author) <> '' LOOP
+        names := '{}';
+
+        FOREACH part IN ARRAY regexp_split_to_array(book.author, '\s*[;&]\s*') LOOP
+            part := trim(regexp_replace(part, '\s+', ' ', 'g'));
+            CONTINUE WHEN part = '';
+            comma_parts := regexp_split_to_array(part, '\s*,\s*');
+
+            IF array_length(comma_parts, 1) = 2 AND comma_parts[1] <> '' AND comma_parts[2] <> ''
+                AND NOT (comma_parts[1] LIKE '% %' AND comma_parts[2] LIKE '% %') THEN
+                names := names || part;
+            ELSE
+                names := names || comma_parts;
+            END IF;
+        END LOOP;
+
+        pos := 0;
+
+        FOREACH author_name IN ARRAY names LOOP
+            author_name := trim(author_name);
+            CONTINUE WHEN author_name = '';
+
+            IF author_name LIKE '%,%' THEN
+                display_name := trim(split_part(author_name, ',', 2)) || ' ' || trim(split_part(author_name, ',', 1));
+                sorted_name := trim(split_part(author_name, ',', 1)) || ', ' || trim(split_part(author_name, ',', 2));
+            ELSIF author_name LIKE '% %' THEN
+                display_name := author_name;
+                sorted_name := substring(author_name FROM '(\S+)$') || ', ' || substring(author_name FROM '^(.*)\s\S+$');
+            ELSE
+                display_name := author_name;
+                sorted_name := author_name;
+            END IF;
+
+            CONTINUE WHEN contributor_name_key(display_name) = '';
+
+            INSERT INTO contributors (name, sort_name, name_key)
+            VALUES (display_name, sorted_name, contributor_name_key(display_name))
+            ON CONFLICT (name_key) DO NOTHING;
+
+            SELECT id INTO contributor FROM contributors WHERE name_key = contributor_name_key(display_name);
+
+            INSERT INTO book_contributors (book_id, contributor_id, role, position)
+            VALUES (book.id, contributor, 'author', pos)
+            ON CONFLICT DO NOTHING;
+
+            pos := pos + 1;
+        END LOOP;
+    END LOOP;
+END
+$$;
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_books_tags;
+
+ALTER TABLE books DROP COLUMN IF EXISTS tags;
+
+DROP INDEX IF EXISTS idx_subjects_parent_id;
+
+ALTER TABLE subjects DROP COLUMN IF EXISTS parent_id;
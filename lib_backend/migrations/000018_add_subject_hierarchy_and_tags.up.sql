@@ -0,0 +1,7 @@
+ALTER TABLE subjects ADD COLUMN parent_id UUID REFERENCES subjects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_subjects_parent_id ON subjects (parent_id);
+
+ALTER TABLE books ADD COLUMN tags TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_books_tags ON books USING GIN (tags);
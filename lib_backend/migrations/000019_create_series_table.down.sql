@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_books_series_id;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS volume_number,
+    DROP COLUMN IF EXISTS series_id;
+
+DROP TABLE IF EXISTS series;
@@ -0,0 +1,14 @@
+CREATE TABLE series (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    title VARCHAR(500) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_series_title ON series (lower(title));
+
+ALTER TABLE books
+    ADD COLUMN series_id UUID REFERENCES series(id) ON DELETE SET NULL,
+    ADD COLUMN volume_number INTEGER CHECK (volume_number > 0);
+
+CREATE INDEX idx_books_series_id ON books (series_id, volume_number);
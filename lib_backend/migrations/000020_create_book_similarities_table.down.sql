@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_loans_user_book;
+DROP TABLE IF EXISTS book_similarities;
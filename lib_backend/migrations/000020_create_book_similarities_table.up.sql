@@ -0,0 +1,13 @@
+-- rebuilt by the recommendations job from the loan history; support is the
+-- number of different patrons who borrowed both books
+CREATE TABLE book_similarities (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    related_book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    support INTEGER NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (book_id, related_book_id)
+);
+
+CREATE INDEX idx_book_similarities_score ON book_similarities (book_id, score DESC);
+CREATE INDEX idx_loans_user_book ON loans (user_id, book_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_loans_book_loaned_at;
+DROP INDEX IF EXISTS idx_books_withdrawn_at;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS withdrawal_reason,
+    DROP COLUMN IF EXISTS withdrawn_at,
+    DROP COLUMN IF EXISTS added_at;
@@ -0,0 +1,10 @@
+-- added_at is unknown for the books that existed before it, so they keep NULL
+ALTER TABLE books
+    ADD COLUMN added_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN withdrawn_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN withdrawal_reason TEXT;
+
+ALTER TABLE books ALTER COLUMN added_at SET DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_books_withdrawn_at ON books (withdrawn_at);
+CREATE INDEX idx_loans_book_loaned_at ON loans (book_id, loaned_at DESC);
@@ -0,0 +1,13 @@
+DROP INDEX IF EXISTS idx_loans_returned_at;
+
+-- anonymized loans cannot be given back their patron
+DELETE FROM loans WHERE user_id IS NULL;
+
+ALTER TABLE loans
+    DROP CONSTRAINT IF EXISTS loans_patron_check,
+    DROP COLUMN IF EXISTS anonymized_at,
+    DROP COLUMN IF EXISTS patron_category,
+    DROP COLUMN IF EXISTS anonymous_ref,
+    ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE users DROP COLUMN IF EXISTS keep_loan_history;
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN keep_loan_history BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- an anonymized loan has no user_id; anonymous_ref groups the loans of one
+-- patron within a single anonymization run, and patron_category keeps the
+-- category the reports group by
+ALTER TABLE loans
+    ALTER COLUMN user_id DROP NOT NULL,
+    ADD COLUMN anonymous_ref UUID,
+    ADD COLUMN patron_category VARCHAR(20),
+    ADD COLUMN anonymized_at TIMESTAMP WITH TIME ZONE,
+    ADD CONSTRAINT loans_patron_check CHECK (user_id IS NOT NULL OR anonymous_ref IS NOT NULL);
+
+CREATE INDEX idx_loans_returned_at ON loans (returned_at) WHERE user_id IS NOT NULL AND returned;
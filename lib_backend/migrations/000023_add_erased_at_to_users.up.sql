@@ -0,0 +1,3 @@
+-- an erased user keeps its row, stripped of personal data, so the fines and
+-- statistics that still reference it stay consistent
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP WITH TIME ZONE;
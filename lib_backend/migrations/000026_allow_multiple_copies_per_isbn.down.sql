@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_books_isbn;
+
+-- fails while more than one copy of a title is catalogued
+ALTER TABLE books ADD CONSTRAINT books_isbn_key UNIQUE (isbn);
@@ -0,0 +1,4 @@
+-- Each books row is one physical copy, so several copies of a title share its ISBN.
+ALTER TABLE books DROP CONSTRAINT books_isbn_key;
+
+CREATE INDEX idx_books_isbn ON books (isbn);
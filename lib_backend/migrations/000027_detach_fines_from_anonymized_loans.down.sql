@@ -0,0 +1,2 @@
+-- fails while fines of anonymized loans are detached
+ALTER TABLE fines ALTER COLUMN loan_id SET NOT NULL;
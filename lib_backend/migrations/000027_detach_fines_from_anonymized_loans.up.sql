@@ -0,0 +1,2 @@
+-- Fines outlive the loan link once the loan is anonymized.
+ALTER TABLE fines ALTER COLUMN loan_id DROP NOT NULL;
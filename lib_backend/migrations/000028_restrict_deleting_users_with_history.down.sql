@@ -0,0 +1,7 @@
+ALTER TABLE fines
+    DROP CONSTRAINT fines_user_id_fkey,
+    ADD CONSTRAINT fines_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE loans
+    DROP CONSTRAINT loans_user_id_fkey,
+    ADD CONSTRAINT loans_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,9 @@
+-- Deleting a user must not take its loan history and fines along; patrons
+-- with history are erased instead.
+ALTER TABLE loans
+    DROP CONSTRAINT loans_user_id_fkey,
+    ADD CONSTRAINT loans_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
+
+ALTER TABLE fines
+    DROP CONSTRAINT fines_user_id_fkey,
+    ADD CONSTRAINT fines_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE RESTRICT;
@@ -0,0 +1,8 @@
+-- the same key may be stored for several clients; the stored responses are
+-- only a retry cache, so they are dropped
+DELETE FROM idempotency_keys;
+
+ALTER TABLE idempotency_keys
+    DROP CONSTRAINT idempotency_keys_pkey,
+    DROP COLUMN scope,
+    ADD PRIMARY KEY (key);
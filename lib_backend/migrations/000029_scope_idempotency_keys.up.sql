@@ -0,0 +1,6 @@
+-- keys are chosen by clients, so each client gets its own key space; scope
+-- is the caller identity of the rate limiter
+ALTER TABLE idempotency_keys
+    ADD COLUMN scope VARCHAR(255) NOT NULL DEFAULT '',
+    DROP CONSTRAINT idempotency_keys_pkey,
+    ADD PRIMARY KEY (scope, key);
@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS item_barcode_seq;
@@ -0,0 +1,3 @@
+-- generated item barcodes; copies of a title share the ISBN, so it cannot
+-- serve as the barcode
+CREATE SEQUENCE item_barcode_seq START WITH 1;
@@ -0,0 +1,13 @@
+CREATE OR REPLACE FUNCTION contributor_name_key(name TEXT) RETURNS TEXT AS $$
+    SELECT trim(regexp_replace(regexp_replace(lower(translate(name,
+        'ÁÀÂÃÄÅáàâãäåÉÈÊËéèêëÍÌÎÏíìîïÓÒÔÕÖØóòôõöøÚÙÛÜúùûüÇçÑñÝýÿ',
+        'AAAAAAaaaaaaEEEEeeeeIIIIiiiiOOOOOOooooooUUUUuuuuCcNnYyy')),
+        '[^a-z0-9]+', ' ', 'g'), '\s+', ' ', 'g'))
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- names the old key cannot tell apart keep their current key
+UPDATE contributors c SET name_key = contributor_name_key(c.name)
+WHERE NOT EXISTS (
+    SELECT 1 FROM contributors other
+    WHERE other.id <> c.id AND contributor_name_key(other.name) = contributor_name_key(c.name)
+);
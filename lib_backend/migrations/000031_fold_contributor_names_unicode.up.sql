@@ -0,0 +1,41 @@
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- contributor_name_key folds case, accents and punctuation of any script, so
+-- "J.R.R. Tolkien" and "J. R. R. Tolkien" are the same contributor while
+-- "Лев Толстой" and "村上春樹" keep their letters. unaccent is only STABLE
+-- because its dictionary could change; the dictionary is named explicitly so
+-- the key does not depend on search_path. A name made only of punctuation
+-- falls back to its lowercase form rather than the empty key.
+CREATE OR REPLACE FUNCTION contributor_name_key(name TEXT) RETURNS TEXT AS $$
+    SELECT COALESCE(NULLIF(
+        trim(regexp_replace(lower(unaccent('unaccent'::regdictionary, name)), '[[:punct:][:space:]]+', ' ', 'g')),
+        ''), lower(trim(name)))
+$$ LANGUAGE SQL IMMUTABLE;
+
+-- The old key dropped letters outside Latin-1, so every Cyrillic or CJK name
+-- collapsed onto the empty key and "Stanisław" lost its "ł". Recomputing the
+-- keys can make contributors that were kept apart only by that equal; their
+-- credits move to the oldest of them.
+DO $$
+DECLARE
+    duplicate RECORD;
+BEGIN
+    FOR duplicate IN
+        SELECT c.id, first_value(c.id) OVER (PARTITION BY contributor_name_key(c.name) ORDER BY c.created_at, c.id) AS keep_id
+        FROM contributors c
+    LOOP
+        CONTINUE WHEN duplicate.id = duplicate.keep_id;
+
+        INSERT INTO book_contributors (book_id, contributor_id, role, position)
+        SELECT book_id, duplicate.keep_id, role, position FROM book_contributors WHERE contributor_id = duplicate.id
+        ON CONFLICT DO NOTHING;
+
+        DELETE FROM contributors WHERE id = duplicate.id;
+    END LOOP;
+END
+$$;
+
+-- the unique key is checked row by row, so two keys trading places would
+-- collide halfway through a single update
+UPDATE contributors SET name_key = id::text;
+UPDATE contributors SET name_key = contributor_name_key(name);
@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS title_similarities;
+
+CREATE TABLE book_similarities (
+    book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    related_book_id UUID NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    support INTEGER NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (book_id, related_book_id)
+);
+
+CREATE INDEX idx_book_similarities_score ON book_similarities (book_id, score DESC);
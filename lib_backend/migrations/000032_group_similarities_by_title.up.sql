@@ -0,0 +1,15 @@
+-- Copies of a title share its ISBN, so similarities are computed between
+-- titles: the borrowers of every copy count together. The pairs are derived
+-- data; the next run of the recommendations job fills the new table.
+DROP TABLE IF EXISTS book_similarities;
+
+CREATE TABLE title_similarities (
+    isbn VARCHAR(13) NOT NULL,
+    related_isbn VARCHAR(13) NOT NULL,
+    support INTEGER NOT NULL,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (isbn, related_isbn)
+);
+
+CREATE INDEX idx_title_similarities_score ON title_similarities (isbn, score DESC);
@@ -69,6 +69,26 @@ function App() {
     }
   }, [currentView]);
 
+  useEffect(() => {
+    if (currentView !== 'books') {
+      return;
+    }
+
+    // keeps availability current without re-fetching the list; EventSource
+    // reconnects on its own and resumes from the last event it saw
+    const events = new EventSource(`${API_BASE_URL}/events/stream?types=book.availability`);
+    events.addEventListener('book.availability', (message) => {
+      const { data } = JSON.parse(message.data);
+      setBooks((current) => current.map((book) =>
+        book.id === data.book_id
+          ? { ...book, available: data.available, current_branch_id: data.current_branch_id }
+          : book
+      ));
+    });
+
+    return () => events.close();
+  }, [currentView]);
+
   const handleSaveBook = async (bookData) => {
     try {
       if (bookData.id) {
@@ -2,10 +2,15 @@ import React from 'react';
 import './BookCard.css';
 import { FontAwesomeIcon } from '@fortawesome/react-fontawesome';
 import { faEdit, faTrashAlt } from '@fortawesome/free-solid-svg-icons';
+import { API_BASE_URL } from '../../constants';
+
+const coverSrc = (book) =>
+    book.cover ? API_BASE_URL.replace(/\/api$/, '') + book.cover.medium : '/img.svg';
 
 const BookCard = ({ book, onEdit, onDelete }) => {
     return (
         <div className="book-card">
+            <img className="book-cover" src={coverSrc(book)} alt={`Capa de ${book.title || 'livro'}`} />
             <div className="card-field">
                 <label htmlFor={`title-${book.id}`}>Título:</label>
                 <input type="text" id={`title-${book.id}`} value={book.title || ''} readOnly />
@@ -18,6 +23,18 @@ const BookCard = ({ book, onEdit, onDelete }) => {
                 <label htmlFor={`isbn-${book.id}`}>ISBN:</label>
                 <input type="text" id={`isbn-${book.id}`} value={book.isbn || ''} readOnly />
             </div>
+            {book.series && (
+                <p className="book-series">
+                    {book.series.title}
+                    {book.series.volume_number && `, vol. ${book.series.volume_number}`}
+                    {book.series.next && (
+                        <span>
+                            {' '}· Próximo: vol. {book.series.next.volume_number}
+                            {book.series.next.available ? ' (disponível)' : ' (indisponível)'}
+                        </span>
+                    )}
+                </p>
+            )}
 
             <div className="card-actions">
                 <button onClick={onEdit} className="edit-button">